@@ -0,0 +1,190 @@
+package xpdf
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mazzegi/xpdf/barcode"
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+	"github.com/pkg/errors"
+)
+
+// default sizes of barcodes without width or height in mm
+const (
+	barcodeModule1D = 0.33
+	barcodeModule2D = 0.5
+	barcodeHeight1D = 15
+)
+
+// barcodeSymbol is an encoded barcode with its quiet zone and human-readable text
+type barcodeSymbol struct {
+	matrix *barcode.Matrix
+	// quiet zone in modules
+	left, right, top, bottom int
+	text                     string
+	twoDimensional           bool
+}
+
+func encodeBarcode(bc *xdoc.Barcode) (barcodeSymbol, error) {
+	data := strings.TrimSpace(bc.Data)
+	var sym barcodeSymbol
+	var err error
+	var quiet int
+	switch bc.Type {
+	case xdoc.BarcodeQR:
+		sym.matrix, err = barcode.QR(data, barcode.Level(bc.Level))
+		sym.twoDimensional = true
+		quiet = barcode.QRQuietZone
+	case xdoc.BarcodeDataMatrix:
+		sym.matrix, err = barcode.DataMatrix(data)
+		sym.twoDimensional = true
+		quiet = barcode.DataMatrixQuietZone
+	case xdoc.BarcodeCode128:
+		sym.matrix, err = barcode.Code128(data)
+		sym.text = data
+		quiet = barcode.Code128QuietZone
+	case xdoc.BarcodeEAN13:
+		var digits string
+		digits, err = barcode.EAN13Digits(data)
+		if err == nil {
+			sym.matrix, err = barcode.EAN13(digits)
+			sym.text = digits[:1] + " " + digits[1:7] + " " + digits[7:]
+		}
+		sym.left, sym.right = barcode.EAN13QuietZoneLeft, barcode.EAN13QuietZoneRight
+	default:
+		return sym, errors.Errorf("unsupported barcode type %q", bc.Type)
+	}
+	if err != nil {
+		return sym, errors.Wrapf(err, "encode %s", bc.Type)
+	}
+	if bc.QuietZone >= 0 {
+		quiet = bc.QuietZone
+		sym.left, sym.right = quiet, quiet
+	} else if sym.left == 0 {
+		sym.left, sym.right = quiet, quiet
+	}
+	if sym.twoDimensional {
+		sym.top, sym.bottom = quiet, quiet
+	}
+	if !bc.ShowText || sym.twoDimensional {
+		sym.text = ""
+	}
+	return sym, nil
+}
+
+// barcodeLayout is the size of a barcode on the page
+type barcodeLayout struct {
+	sym        barcodeSymbol
+	module     float64
+	barHeight  float64
+	textHeight float64
+	width      float64
+	height     float64
+	rotatedW   float64
+	rotatedH   float64
+	sty        style.Styles
+}
+
+// layoutBarcode sizes the symbol by the width and height styles. Two-dimensional symbols keep square modules,
+// one-dimensional ones fill the height with bars and the human-readable text.
+func (p *Processor) layoutBarcode(bc *xdoc.Barcode, sty style.Styles, maxWidth float64) (barcodeLayout, error) {
+	sym, err := encodeBarcode(bc)
+	if err != nil {
+		return barcodeLayout{}, err
+	}
+	l := barcodeLayout{sym: sym, sty: sty}
+	cols := float64(sym.matrix.Cols + sym.left + sym.right)
+	if sym.text != "" {
+		p.engine.ChangeFont(sty.Font)
+		l.textHeight = p.engine.FontHeight()
+		p.resetStyles()
+	}
+	if sym.twoDimensional {
+		rows := float64(sym.matrix.Rows + sym.top + sym.bottom)
+		switch {
+		case sty.Width > 0 && sty.Height > 0:
+			l.module = math.Min(sty.Width/cols, sty.Height/rows)
+		case sty.Width > 0:
+			l.module = sty.Width / cols
+		case sty.Height > 0:
+			l.module = sty.Height / rows
+		default:
+			l.module = barcodeModule2D
+		}
+		if l.module*cols > maxWidth {
+			l.module = maxWidth / cols
+		}
+		l.width, l.height = l.module*cols, l.module*rows
+	} else {
+		l.module = barcodeModule1D
+		if sty.Width > 0 {
+			l.module = sty.Width / cols
+		}
+		if l.module*cols > maxWidth {
+			l.module = maxWidth / cols
+		}
+		l.width = l.module * cols
+		l.height = barcodeHeight1D + l.textHeight
+		if sty.Height > 0 {
+			l.height = math.Max(sty.Height, l.textHeight)
+		}
+		l.barHeight = l.height - l.textHeight
+	}
+	l.rotatedW, l.rotatedH = rotatedSize(sty.Rotate, l.width, l.height)
+	return l, nil
+}
+
+func (p *Processor) renderBarcode(bc *xdoc.Barcode, pa PrintableArea) {
+	sty := bc.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+	l, err := p.layoutBarcode(bc, sty, pa.Width()-sty.OffsetX)
+	if err != nil {
+		Logf("ERROR: barcode: %v", err)
+		return
+	}
+	xStart, yStart := p.engine.GetXY()
+	if !p.preventPageBreak && !p.fitsOnPage(l.rotatedH+sty.OffsetY) && !p.atPageTop() {
+		xStart = p.newPageAt(xStart)
+		_, yStart = p.engine.GetXY()
+	}
+	x := xStart + sty.OffsetX
+	y := yStart + sty.OffsetY
+	if sty.Rotate == 0 {
+		p.drawBarcode(l, x, y)
+	} else {
+		cx, cy := x+l.rotatedW/2, y+l.rotatedH/2
+		p.transformed(rotation(sty.Rotate, cx, cy), func() {
+			p.drawBarcode(l, cx-l.width/2, cy-l.height/2)
+		})
+	}
+	p.engine.SetX(xStart)
+	p.engine.SetY(y + l.rotatedH)
+}
+
+// drawBarcode draws the background including the quiet zone, the dark modules as rectangles and the text centered below the bars
+func (p *Processor) drawBarcode(l barcodeLayout, x, y float64) {
+	defer p.resetStyles()
+	m := l.sym.matrix
+	p.engine.SetFillColor(l.sty.Background.Values())
+	p.engine.FillRect(x, y, l.width, l.height)
+
+	p.engine.SetFillColor(l.sty.Foreground.Values())
+	x0 := x + float64(l.sym.left)*l.module
+	y0 := y + float64(l.sym.top)*l.module
+	rowHeight := l.module
+	if !l.sym.twoDimensional {
+		rowHeight = l.barHeight
+	}
+	for row := 0; row < m.Rows; row++ {
+		for _, run := range m.Runs(row) {
+			p.engine.FillRect(x0+float64(run.Col)*l.module, y0+float64(row)*rowHeight, float64(run.Length)*l.module, rowHeight)
+		}
+	}
+
+	if l.sym.text != "" {
+		p.engine.ChangeFont(l.sty.Font)
+		p.engine.SetTextColor(l.sty.Text.Values())
+		tw := p.engine.TextWidth(l.sym.text)
+		p.engine.TextAt(x+(l.width-tw)/2, y+l.barHeight+baselineRatio*l.textHeight, l.sym.text)
+	}
+}
@@ -0,0 +1,89 @@
+// Package barcode encodes data as one-dimensional (Code 128, EAN-13) and two-dimensional (QR code, Data Matrix) symbols.
+// Symbols are returned as a matrix of modules, which can be drawn with any vector or raster backend.
+package barcode
+
+// Matrix is a grid of modules, where true stands for a dark module. One-dimensional symbols have a single row.
+type Matrix struct {
+	Cols int
+	Rows int
+	bits []bool
+}
+
+func newMatrix(cols, rows int) *Matrix {
+	return &Matrix{
+		Cols: cols,
+		Rows: rows,
+		bits: make([]bool, cols*rows),
+	}
+}
+
+// At reports if the module at col, row is dark. Modules outside of the matrix are light.
+func (m *Matrix) At(col, row int) bool {
+	if col < 0 || col >= m.Cols || row < 0 || row >= m.Rows {
+		return false
+	}
+	return m.bits[row*m.Cols+col]
+}
+
+func (m *Matrix) set(col, row int, dark bool) {
+	m.bits[row*m.Cols+col] = dark
+}
+
+// Run is a horizontal sequence of dark modules in a row
+type Run struct {
+	Col    int
+	Length int
+}
+
+// Runs returns the runs of dark modules of row from left to right. Drawing runs instead of single modules avoids hairline gaps between adjacent modules.
+func (m *Matrix) Runs(row int) []Run {
+	var runs []Run
+	for col := 0; col < m.Cols; {
+		if !m.At(col, row) {
+			col++
+			continue
+		}
+		start := col
+		for col < m.Cols && m.At(col, row) {
+			col++
+		}
+		runs = append(runs, Run{Col: start, Length: col - start})
+	}
+	return runs
+}
+
+// String renders the matrix with '#' for dark and '.' for light modules, one line per row
+func (m *Matrix) String() string {
+	buf := make([]byte, 0, (m.Cols+1)*m.Rows)
+	for row := 0; row < m.Rows; row++ {
+		for col := 0; col < m.Cols; col++ {
+			if m.At(col, row) {
+				buf = append(buf, '#')
+			} else {
+				buf = append(buf, '.')
+			}
+		}
+		buf = append(buf, '\n')
+	}
+	return string(buf)
+}
+
+// appendWidths appends alternating bars and spaces with the given widths in modules, starting with a bar
+func appendWidths(bits []bool, widths string) []bool {
+	dark := true
+	for _, w := range widths {
+		for i := 0; i < int(w-'0'); i++ {
+			bits = append(bits, dark)
+		}
+		dark = !dark
+	}
+	return bits
+}
+
+func rowMatrix(bits []bool) *Matrix {
+	return &Matrix{
+		Cols: len(bits),
+		Rows: 1,
+		bits: bits,
+	}
+}
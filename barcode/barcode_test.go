@@ -0,0 +1,304 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCode128Values(t *testing.T) {
+	tests := []struct {
+		in  string
+		out []int
+	}{
+		{in: "Wikipedia", out: []int{104, 55, 73, 75, 73, 80, 69, 68, 73, 65}},
+		{in: "123456", out: []int{105, 12, 34, 56}},
+		{in: "AB123456", out: []int{104, 33, 34, 99, 12, 34, 56}},
+		{in: "12345", out: []int{105, 12, 34, 100, 21}},
+		{in: "A1234567B", out: []int{104, 33, 17, 99, 23, 45, 67, 100, 34}},
+		{in: "a\tb", out: []int{104, 65, 101, 73, 100, 66}},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			have := code128Values(test.in)
+			if fmt.Sprint(have) != fmt.Sprint(test.out) {
+				t.Fatalf("have %v, want %v", have, test.out)
+			}
+		})
+	}
+}
+
+func TestCode128(t *testing.T) {
+	for i, p := range code128Patterns {
+		sum := 0
+		for _, w := range p {
+			sum += int(w - '0')
+		}
+		if i < code128Stop && sum != 11 || i == code128Stop && sum != 13 {
+			t.Fatalf("pattern %d (%s) has %d modules", i, p, sum)
+		}
+	}
+	m, err := Code128("Wikipedia")
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	//start, 9 characters and checksum with 11 modules each and the stop pattern
+	if m.Cols != 11*11+13 || m.Rows != 1 {
+		t.Fatalf("have %dx%d modules", m.Cols, m.Rows)
+	}
+	//checksum of "Wikipedia" is 88
+	checksum := rowMatrix(appendWidths(nil, code128Patterns[88])).String()
+	if have := m.String()[110:121]; have != strings.TrimSpace(checksum) {
+		t.Fatalf("have checksum %s, want %s", have, checksum)
+	}
+	if _, err := Code128("Grüße"); err == nil {
+		t.Fatalf("non-ascii data should fail")
+	}
+}
+
+func TestEAN13(t *testing.T) {
+	tests := []struct {
+		in     string
+		digits string
+		fail   bool
+	}{
+		{in: "400638133393", digits: "4006381333931"},
+		{in: "4006381333931", digits: "4006381333931"},
+		{in: "4006381333932", fail: true},
+		{in: "40063813339", fail: true},
+		{in: "40063813339x", fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			m, err := EAN13(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("encode %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encode %q failed: %v", test.in, err)
+			}
+			digits, _ := EAN13Digits(test.in)
+			if digits != test.digits {
+				t.Fatalf("have digits %s, want %s", digits, test.digits)
+			}
+			//guards, first left digit 0 with odd parity and last right digit 1
+			s := strings.TrimSpace(m.String())
+			if len(s) != 95 || !strings.HasPrefix(s, "#.#...##.#") || !strings.HasSuffix(s, "##..##.#.#") || s[45:50] != ".#.#." {
+				t.Fatalf("unexpected modules %s", s)
+			}
+		})
+	}
+}
+
+func TestQRCodewords(t *testing.T) {
+	//"HELLO WORLD" at 1-M, with the error correction codewords of the well known example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := append(append([]byte{}, data...), 196, 35, 39, 119, 235, 215, 231, 226, 93, 23)
+	if have := qrInterleave(data, 1, 1); !bytes.Equal(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+
+	formats := []struct {
+		level Level
+		mask  int
+		bits  string
+	}{
+		{level: LevelL, mask: 0, bits: "111011111000100"},
+		{level: LevelM, mask: 0, bits: "101010000010010"},
+		{level: LevelQ, mask: 0, bits: "011010101011111"},
+		{level: LevelH, mask: 7, bits: "000100000111011"},
+	}
+	for _, f := range formats {
+		if have := fmt.Sprintf("%015b", qrFormatBits(f.level, f.mask)); have != f.bits {
+			t.Fatalf("format %s%d: have %s, want %s", f.level, f.mask, have, f.bits)
+		}
+	}
+	if have := fmt.Sprintf("%018b", qrVersionBits(7)); have != "000111110010010100" {
+		t.Fatalf("version 7: have %s", have)
+	}
+}
+
+// readQR reads the codewords back from the symbol using the format information of the symbol
+func readQR(t *testing.T, m *Matrix, version int) (Level, []byte) {
+	q := newQRSymbol(version)
+	q.drawFunctionPatterns()
+	bits := 0
+	for i := 0; i <= 5; i++ {
+		if m.At(8, i) {
+			bits |= 1 << i
+		}
+	}
+	for i, xy := range [][2]int{{8, 7}, {8, 8}, {7, 8}} {
+		if m.At(xy[0], xy[1]) {
+			bits |= 1 << (6 + i)
+		}
+	}
+	for i := 9; i < 15; i++ {
+		if m.At(14-i, 8) {
+			bits |= 1 << i
+		}
+	}
+	var level Level
+	mask := -1
+	for _, l := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+		for k := 0; k < 8; k++ {
+			if qrFormatBits(l, k) == bits {
+				level, mask = l, k
+			}
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("invalid format bits %015b", bits)
+	}
+	var codewords []byte
+	n := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = q.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.isFunction(x, y) {
+					continue
+				}
+				if n%8 == 0 {
+					codewords = append(codewords, 0)
+				}
+				if m.At(x, y) != qrMasked(mask, x, y) {
+					codewords[n/8] |= 0x80 >> (n % 8)
+				}
+				n++
+			}
+		}
+	}
+	return level, codewords[:qrRawModules(version)/8]
+}
+
+func TestQR(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		level   Level
+		version int
+		fail    bool
+	}{
+		{name: "numeric", in: "01234567", level: LevelH, version: 1},
+		{name: "alphanumeric", in: "HELLO WORLD", level: LevelQ, version: 1},
+		{name: "byte", in: "https://example.com/?q=xpdf", level: LevelM, version: 3},
+		{name: "sepa", in: "BCD\n002\n1\nSCT\nBFSWDE33BER\nWikimedia Foerdergesellschaft\nDE33100205000001194700\nEUR123.45\n\n\nSpende fuer Wikipedia", level: LevelM, version: 7},
+		{name: "large", in: strings.Repeat("xpdf", 200), level: LevelL, version: 20},
+		{name: "too long", in: strings.Repeat("x", 3000), level: LevelH, fail: true},
+		{name: "invalid level", in: "x", level: "X", fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := QR(test.in, test.level)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("encode should fail but did not")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encode failed: %v", err)
+			}
+			if size := test.version*4 + 17; m.Cols != size || m.Rows != size {
+				t.Fatalf("have %dx%d modules, want version %d", m.Cols, m.Rows, test.version)
+			}
+			level, codewords := readQR(t, m, test.version)
+			if level != test.level {
+				t.Fatalf("have level %s, want %s", level, test.level)
+			}
+			//every block must be divisible by the generator polynomial
+			lvl, _ := level.index()
+			numBlocks := qrBlocks[lvl][test.version]
+			eccLen := qrECCPerBlock[lvl][test.version]
+			numShort := numBlocks - len(codewords)%numBlocks
+			shortData := len(codewords)/numBlocks - eccLen
+			blocks := make([][]byte, numBlocks)
+			pos := 0
+			for i := 0; i < shortData+1+eccLen; i++ {
+				for b := range blocks {
+					if i == shortData && b < numShort {
+						continue
+					}
+					blocks[b] = append(blocks[b], codewords[pos])
+					pos++
+				}
+			}
+			generator := qrField.generator(eccLen, 0)
+			for b, block := range blocks {
+				data, ecc := block[:len(block)-eccLen], block[len(block)-eccLen:]
+				if rem := qrField.remainder(data, generator); !bytes.Equal(rem, ecc) {
+					t.Fatalf("block %d is corrupted", b)
+				}
+			}
+		})
+	}
+}
+
+func TestDataMatrix(t *testing.T) {
+	//the example of the specification
+	codewords := dataMatrixECC(dataMatrixPad(dataMatrixASCII("123456"), 3), dataMatrixSizes[0])
+	if want := []byte{142, 164, 186, 114, 25, 5, 88, 102}; !bytes.Equal(codewords, want) {
+		t.Fatalf("have %v, want %v", codewords, want)
+	}
+	if have := dataMatrixPad([]byte{66}, 5); !bytes.Equal(have, []byte{66, 129, 70, 220, 115}) {
+		t.Fatalf("have pads %v", have)
+	}
+
+	tests := []struct {
+		in   string
+		size int
+		fail bool
+	}{
+		{in: "123456", size: 10},
+		{in: "Hello", size: 12},
+		{in: "xpdf", size: 12},
+		{in: "Ä", size: 12},
+		{in: strings.Repeat("data matrix ", 20), size: 64},
+		{in: strings.Repeat("x", 1600), fail: true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%.10s", test.in), func(t *testing.T) {
+			m, err := DataMatrix(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("encode should fail but did not")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encode failed: %v", err)
+			}
+			if m.Cols != test.size || m.Rows != test.size {
+				t.Fatalf("have %dx%d modules, want %d", m.Cols, m.Rows, test.size)
+			}
+			//solid finder on the left and bottom, alternating timing on the top and right
+			for i := 0; i < test.size; i++ {
+				if !m.At(0, i) || !m.At(i, test.size-1) {
+					t.Fatalf("finder pattern broken at %d", i)
+				}
+				if m.At(i, 0) != (i%2 == 0) || m.At(test.size-1, i) != (i%2 == 1) {
+					t.Fatalf("timing pattern broken at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRuns(t *testing.T) {
+	m := rowMatrix(appendWidths(nil, "21312"))
+	if have := fmt.Sprint(m.Runs(0)); have != "[{0 2} {3 3} {7 2}]" {
+		t.Fatalf("have %s", have)
+	}
+}
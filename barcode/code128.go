@@ -0,0 +1,148 @@
+package barcode
+
+import (
+	"github.com/pkg/errors"
+)
+
+// code128Patterns are the widths of bars and spaces of the symbol values 0 to 106
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+type code128Set int
+
+const (
+	code128SetA code128Set = iota
+	code128SetB
+	code128SetC
+)
+
+// Code128QuietZone is the minimum quiet zone in modules on both sides
+const Code128QuietZone = 10
+
+// Code128 encodes ASCII data as Code 128 symbol. Runs of digits are encoded in the compact code set C,
+// control characters in code set A and all other characters in code set B.
+func Code128(data string) (*Matrix, error) {
+	if len(data) == 0 {
+		return nil, errors.Errorf("code128: no data")
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] > 127 {
+			return nil, errors.Errorf("code128: invalid character %q at %d - only ascii is supported", data[i], i)
+		}
+	}
+	values := code128Values(data)
+
+	checksum := values[0]
+	for i, v := range values[1:] {
+		checksum += (i + 1) * v
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var bits []bool
+	for _, v := range values {
+		bits = appendWidths(bits, code128Patterns[v])
+	}
+	return rowMatrix(bits), nil
+}
+
+// digitRun returns the number of consecutive digits in s starting at pos
+func digitRun(s string, pos int) int {
+	n := 0
+	for pos+n < len(s) && s[pos+n] >= '0' && s[pos+n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// code128Values returns the symbol values including the start code, but without checksum and stop code
+func code128Values(data string) []int {
+	setFor := func(c byte) code128Set {
+		if c < 32 {
+			return code128SetA
+		}
+		return code128SetB
+	}
+	var values []int
+	var set code128Set
+	if run := digitRun(data, 0); run >= 4 || run == len(data) && run%2 == 0 {
+		set = code128SetC
+		values = append(values, code128StartC)
+	} else if setFor(data[0]) == code128SetA {
+		set = code128SetA
+		values = append(values, code128StartA)
+	} else {
+		set = code128SetB
+		values = append(values, code128StartB)
+	}
+
+	for pos := 0; pos < len(data); {
+		run := digitRun(data, pos)
+		if set == code128SetC {
+			if run >= 2 {
+				values = append(values, int(data[pos]-'0')*10+int(data[pos+1]-'0'))
+				pos += 2
+				continue
+			}
+			set = setFor(data[pos])
+			values = append(values, switchCode(set))
+		}
+		//switching to C pays off for at least 4 digits at the end or 6 digits in between
+		if run >= 6 || run >= 4 && pos+run == len(data) {
+			if run%2 == 1 {
+				values = append(values, code128Value(data[pos], set))
+				pos++
+			}
+			set = code128SetC
+			values = append(values, code128CodeC)
+			continue
+		}
+		c := data[pos]
+		if want := setFor(c); want != set && !(c >= 32 && c < 96) {
+			set = want
+			values = append(values, switchCode(set))
+		}
+		values = append(values, code128Value(c, set))
+		pos++
+	}
+	return values
+}
+
+func switchCode(set code128Set) int {
+	switch set {
+	case code128SetA:
+		return code128CodeA
+	case code128SetB:
+		return code128CodeB
+	default:
+		return code128CodeC
+	}
+}
+
+// code128Value returns the value of c in code set A or B. Characters from 32 to 95 have the same value in both sets.
+func code128Value(c byte, set code128Set) int {
+	if set == code128SetA && c < 32 {
+		return int(c) + 64
+	}
+	return int(c) - 32
+}
@@ -0,0 +1,260 @@
+package barcode
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DataMatrixQuietZone is the minimum quiet zone in modules on all sides
+const DataMatrixQuietZone = 1
+
+// dataMatrixSize describes a square ECC 200 symbol
+type dataMatrixSize struct {
+	size      int // modules per side
+	region    int // data modules per side of a region
+	dataWords int
+	eccWords  int
+	eccBlocks int
+}
+
+var dataMatrixSizes = []dataMatrixSize{
+	{10, 8, 3, 5, 1},
+	{12, 10, 5, 7, 1},
+	{14, 12, 8, 10, 1},
+	{16, 14, 12, 12, 1},
+	{18, 16, 18, 14, 1},
+	{20, 18, 22, 18, 1},
+	{22, 20, 30, 20, 1},
+	{24, 22, 36, 24, 1},
+	{26, 24, 44, 28, 1},
+	{32, 14, 62, 36, 1},
+	{36, 16, 86, 42, 1},
+	{40, 18, 114, 48, 1},
+	{44, 20, 144, 56, 1},
+	{48, 22, 174, 68, 1},
+	{52, 24, 204, 84, 2},
+	{64, 14, 280, 112, 2},
+	{72, 16, 368, 144, 4},
+	{80, 18, 456, 192, 4},
+	{88, 20, 576, 224, 4},
+	{96, 22, 696, 272, 4},
+	{104, 24, 816, 336, 6},
+	{120, 18, 1050, 408, 6},
+	{132, 20, 1304, 496, 8},
+	{144, 22, 1558, 620, 10},
+}
+
+var dataMatrixField = newGaloisField(0x12D)
+
+// DataMatrix encodes data as square ECC 200 Data Matrix symbol in ASCII encodation, where pairs of digits take a single codeword.
+func DataMatrix(data string) (*Matrix, error) {
+	codewords := dataMatrixASCII(data)
+	var sz dataMatrixSize
+	for _, s := range dataMatrixSizes {
+		if s.dataWords >= len(codewords) {
+			sz = s
+			break
+		}
+	}
+	if sz.size == 0 {
+		return nil, errors.Errorf("datamatrix: data of %d bytes is too long", len(data))
+	}
+	codewords = dataMatrixPad(codewords, sz.dataWords)
+	codewords = dataMatrixECC(codewords, sz)
+	return dataMatrixPlace(codewords, sz), nil
+}
+
+// dataMatrixASCII returns the codewords of data in ASCII encodation
+func dataMatrixASCII(data string) []byte {
+	var codewords []byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case digitRun(data, i) >= 2:
+			codewords = append(codewords, byte(130+int(c-'0')*10+int(data[i+1]-'0')))
+			i++
+		case c >= 128:
+			//upper shift
+			codewords = append(codewords, 235, c-128+1)
+		default:
+			codewords = append(codewords, c+1)
+		}
+	}
+	return codewords
+}
+
+// dataMatrixPad fills the codewords up to n. The first pad is 129, the following ones are randomized by their position.
+func dataMatrixPad(codewords []byte, n int) []byte {
+	if len(codewords) < n {
+		codewords = append(codewords, 129)
+	}
+	for len(codewords) < n {
+		pos := len(codewords) + 1
+		pad := 129 + (149*pos)%253 + 1
+		if pad > 254 {
+			pad -= 254
+		}
+		codewords = append(codewords, byte(pad))
+	}
+	return codewords
+}
+
+// dataMatrixECC appends the error correction codewords. Larger symbols interleave several blocks, where codeword i belongs to block i mod blocks.
+func dataMatrixECC(data []byte, sz dataMatrixSize) []byte {
+	eccPerBlock := sz.eccWords / sz.eccBlocks
+	generator := dataMatrixField.generator(eccPerBlock, 1)
+	result := make([]byte, sz.dataWords+sz.eccWords)
+	copy(result, data)
+	for b := 0; b < sz.eccBlocks; b++ {
+		var block []byte
+		for i := b; i < len(data); i += sz.eccBlocks {
+			block = append(block, data[i])
+		}
+		for i, e := range dataMatrixField.remainder(block, generator) {
+			result[sz.dataWords+b+i*sz.eccBlocks] = e
+		}
+	}
+	return result
+}
+
+// dataMatrixPlacement places the codewords in the data area of all regions without finder and timing patterns
+type dataMatrixPlacement struct {
+	rows, cols int
+	codewords  []byte
+	bits       []int8 // -1 for unset
+}
+
+func (p *dataMatrixPlacement) module(row, col, pos, bit int) {
+	if row < 0 {
+		row += p.rows
+		col += 4 - (p.rows+4)%8
+	}
+	if col < 0 {
+		col += p.cols
+		row += 4 - (p.cols+4)%8
+	}
+	v := int8(0)
+	if pos < len(p.codewords) && p.codewords[pos]&(1<<(8-bit)) != 0 {
+		v = 1
+	}
+	p.bits[row*p.cols+col] = v
+}
+
+func (p *dataMatrixPlacement) isSet(row, col int) bool {
+	return p.bits[row*p.cols+col] >= 0
+}
+
+// utah places a codeword in the standard shape with its last bit at row, col
+func (p *dataMatrixPlacement) utah(row, col, pos int) {
+	p.module(row-2, col-2, pos, 1)
+	p.module(row-2, col-1, pos, 2)
+	p.module(row-1, col-2, pos, 3)
+	p.module(row-1, col-1, pos, 4)
+	p.module(row-1, col, pos, 5)
+	p.module(row, col-2, pos, 6)
+	p.module(row, col-1, pos, 7)
+	p.module(row, col, pos, 8)
+}
+
+// corner places a codeword, which is split between the corners of the data area
+func (p *dataMatrixPlacement) corner(pos int, positions [8][2]int) {
+	for i, rc := range positions {
+		p.module(rc[0], rc[1], pos, i+1)
+	}
+}
+
+func (p *dataMatrixPlacement) place() {
+	nr, nc := p.rows, p.cols
+	pos := 0
+	row, col := 4, 0
+	for {
+		switch {
+		case row == nr && col == 0:
+			p.corner(pos, [8][2]int{{nr - 1, 0}, {nr - 1, 1}, {nr - 1, 2}, {0, nc - 2}, {0, nc - 1}, {1, nc - 1}, {2, nc - 1}, {3, nc - 1}})
+			pos++
+		case row == nr-2 && col == 0 && nc%4 != 0:
+			p.corner(pos, [8][2]int{{nr - 3, 0}, {nr - 2, 0}, {nr - 1, 0}, {0, nc - 4}, {0, nc - 3}, {0, nc - 2}, {0, nc - 1}, {1, nc - 1}})
+			pos++
+		case row == nr-2 && col == 0 && nc%8 == 4:
+			p.corner(pos, [8][2]int{{nr - 3, 0}, {nr - 2, 0}, {nr - 1, 0}, {0, nc - 2}, {0, nc - 1}, {1, nc - 1}, {2, nc - 1}, {3, nc - 1}})
+			pos++
+		case row == nr+4 && col == 2 && nc%8 == 0:
+			p.corner(pos, [8][2]int{{nr - 1, 0}, {nr - 1, nc - 1}, {0, nc - 3}, {0, nc - 2}, {0, nc - 1}, {1, nc - 3}, {1, nc - 2}, {1, nc - 1}})
+			pos++
+		}
+		//sweep upward diagonally
+		for {
+			if row < nr && col >= 0 && !p.isSet(row, col) {
+				p.utah(row, col, pos)
+				pos++
+			}
+			row -= 2
+			col += 2
+			if row < 0 || col >= nc {
+				break
+			}
+		}
+		row++
+		col += 3
+		//sweep downward diagonally
+		for {
+			if row >= 0 && col < nc && !p.isSet(row, col) {
+				p.utah(row, col, pos)
+				pos++
+			}
+			row += 2
+			col -= 2
+			if row >= nr || col < 0 {
+				break
+			}
+		}
+		row += 3
+		col++
+		if row >= nr && col >= nc {
+			break
+		}
+	}
+	//the lower right corner remains unset in some sizes and gets a fixed pattern
+	if !p.isSet(nr-1, nc-1) {
+		p.bits[(nr-1)*nc+nc-1] = 1
+		p.bits[(nr-2)*nc+nc-2] = 1
+	}
+}
+
+// dataMatrixPlace returns the symbol with the codewords placed in the data regions, which are surrounded by finder and timing patterns
+func dataMatrixPlace(codewords []byte, sz dataMatrixSize) *Matrix {
+	regions := sz.size / (sz.region + 2)
+	p := &dataMatrixPlacement{
+		rows:      regions * sz.region,
+		cols:      regions * sz.region,
+		codewords: codewords,
+		bits:      make([]int8, regions*sz.region*regions*sz.region),
+	}
+	for i := range p.bits {
+		p.bits[i] = -1
+	}
+	p.place()
+
+	m := newMatrix(sz.size, sz.size)
+	block := sz.region + 2
+	for ry := 0; ry < regions; ry++ {
+		for rx := 0; rx < regions; rx++ {
+			x0, y0 := rx*block, ry*block
+			for i := 0; i < block; i++ {
+				//solid L on the left and bottom
+				m.set(x0, y0+i, true)
+				m.set(x0+i, y0+block-1, true)
+				//alternating timing on the top and right
+				m.set(x0+i, y0, i%2 == 0)
+				m.set(x0+block-1, y0+i, i%2 == 1 || i == block-1)
+			}
+		}
+	}
+	for row := 0; row < p.rows; row++ {
+		for col := 0; col < p.cols; col++ {
+			x := col + 1 + col/sz.region*2
+			y := row + 1 + row/sz.region*2
+			m.set(x, y, p.bits[row*p.cols+col] == 1)
+		}
+	}
+	return m
+}
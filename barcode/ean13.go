@@ -0,0 +1,81 @@
+package barcode
+
+import (
+	"github.com/pkg/errors"
+)
+
+// eanL are the left hand odd parity patterns of the digits. Right hand patterns are their complement, even parity patterns the reversed complement.
+var eanL = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity encodes the first digit as parity of the left hand digits, where 'G' is even parity
+var eanParity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EAN-13 quiet zones in modules
+const (
+	EAN13QuietZoneLeft  = 11
+	EAN13QuietZoneRight = 7
+)
+
+// EAN13Digits returns the 13 digits of data, which has 12 digits without or 13 digits with check digit.
+// A given check digit must be correct.
+func EAN13Digits(data string) (string, error) {
+	if len(data) != 12 && len(data) != 13 {
+		return "", errors.Errorf("ean13: invalid length %d - must be 12 or 13 digits", len(data))
+	}
+	if digitRun(data, 0) != len(data) {
+		return "", errors.Errorf("ean13: %q contains non-digits", data)
+	}
+	check := eanCheckDigit(data[:12])
+	if len(data) == 13 && data[12] != check {
+		return "", errors.Errorf("ean13: invalid check digit %c - must be %c", data[12], check)
+	}
+	return data[:12] + string(check), nil
+}
+
+// eanCheckDigit returns the check digit of 12 digits, which are weighted alternately with 1 and 3
+func eanCheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// EAN13 encodes 12 or 13 digits as EAN-13 symbol of 95 modules
+func EAN13(data string) (*Matrix, error) {
+	digits, err := EAN13Digits(data)
+	if err != nil {
+		return nil, err
+	}
+	bits := make([]bool, 0, 95)
+	appendPattern := func(pattern string, invert, reverse bool) {
+		for i := range pattern {
+			c := pattern[i]
+			if reverse {
+				c = pattern[len(pattern)-1-i]
+			}
+			bits = append(bits, (c == '1') != invert)
+		}
+	}
+	appendPattern("101", false, false)
+	parity := eanParity[digits[0]-'0']
+	for i := 1; i <= 6; i++ {
+		appendPattern(eanL[digits[i]-'0'], parity[i-1] == 'G', parity[i-1] == 'G')
+	}
+	appendPattern("01010", false, false)
+	for i := 7; i <= 12; i++ {
+		appendPattern(eanL[digits[i]-'0'], true, false)
+	}
+	appendPattern("101", false, false)
+	return rowMatrix(bits), nil
+}
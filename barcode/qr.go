@@ -0,0 +1,546 @@
+package barcode
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the error correction level of QR codes
+type Level string
+
+const (
+	LevelL Level = "L" // recovers 7% of the data
+	LevelM Level = "M" // recovers 15% of the data
+	LevelQ Level = "Q" // recovers 25% of the data
+	LevelH Level = "H" // recovers 30% of the data
+)
+
+// QRQuietZone is the minimum quiet zone in modules on all sides
+const QRQuietZone = 4
+
+// index into the tables by level
+func (l Level) index() (int, error) {
+	switch l {
+	case LevelL:
+		return 0, nil
+	case LevelM:
+		return 1, nil
+	case LevelQ:
+		return 2, nil
+	case LevelH:
+		return 3, nil
+	default:
+		return 0, errors.Errorf("qr: invalid error correction level %q - must be one of L, M, Q, H", string(l))
+	}
+}
+
+// formatBits are the bits of the level in the format information
+func (l Level) formatBits() int {
+	switch l {
+	case LevelL:
+		return 1
+	case LevelQ:
+		return 3
+	case LevelH:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// qrECCPerBlock is the number of error correction codewords per block by level and version
+var qrECCPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// qrBlocks is the number of error correction blocks by level and version
+var qrBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+var qrField = newGaloisField(0x11D)
+
+const qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+type qrMode int
+
+const (
+	qrModeNumeric qrMode = iota
+	qrModeAlphanumeric
+	qrModeByte
+)
+
+// indicator returns the mode indicator and the bit lengths of the character count for versions 1-9, 10-26 and 27-40
+func (m qrMode) indicator() (int, [3]int) {
+	switch m {
+	case qrModeNumeric:
+		return 0x1, [3]int{10, 12, 14}
+	case qrModeAlphanumeric:
+		return 0x2, [3]int{9, 11, 13}
+	default:
+		return 0x4, [3]int{8, 16, 16}
+	}
+}
+
+func qrModeOf(data string) qrMode {
+	if digitRun(data, 0) == len(data) {
+		return qrModeNumeric
+	}
+	for i := 0; i < len(data); i++ {
+		if strings.IndexByte(qrAlphanumeric, data[i]) < 0 {
+			return qrModeByte
+		}
+	}
+	return qrModeAlphanumeric
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+// qrSegmentBits returns the data bits without mode indicator and count
+func qrSegmentBits(data string, mode qrMode) bitBuffer {
+	var bits bitBuffer
+	switch mode {
+	case qrModeNumeric:
+		for i := 0; i < len(data); i += 3 {
+			n := len(data) - i
+			if n > 3 {
+				n = 3
+			}
+			v := 0
+			for _, c := range data[i : i+n] {
+				v = v*10 + int(c-'0')
+			}
+			bits.append(v, n*3+1)
+		}
+	case qrModeAlphanumeric:
+		for i := 0; i < len(data); i += 2 {
+			v := strings.IndexByte(qrAlphanumeric, data[i])
+			if i+1 < len(data) {
+				bits.append(v*45+strings.IndexByte(qrAlphanumeric, data[i+1]), 11)
+			} else {
+				bits.append(v, 6)
+			}
+		}
+	default:
+		for i := 0; i < len(data); i++ {
+			bits.append(int(data[i]), 8)
+		}
+	}
+	return bits
+}
+
+// qrRawModules returns the number of modules of a version, which are available for data and error correction
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func qrDataCodewords(version, level int) int {
+	return qrRawModules(version)/8 - qrECCPerBlock[level][version]*qrBlocks[level][version]
+}
+
+// QR encodes data as QR code model 2 with the smallest version, which holds the data at the given error correction level.
+// The whole data is encoded in numeric, alphanumeric or byte mode, whichever fits. Bytes are taken as they are, which is UTF-8 for go strings.
+func QR(data string, level Level) (*Matrix, error) {
+	lvl, err := level.index()
+	if err != nil {
+		return nil, err
+	}
+	mode := qrModeOf(data)
+	indicator, countBits := mode.indicator()
+	payload := qrSegmentBits(data, mode)
+
+	var bits bitBuffer
+	version := 1
+	for ; version <= 40; version++ {
+		cb := countBits[0]
+		switch {
+		case version >= 27:
+			cb = countBits[2]
+		case version >= 10:
+			cb = countBits[1]
+		}
+		if len(data) >= 1<<cb {
+			continue
+		}
+		if 4+cb+len(payload) <= qrDataCodewords(version, lvl)*8 {
+			bits.append(indicator, 4)
+			bits.append(len(data), cb)
+			bits = append(bits, payload...)
+			break
+		}
+	}
+	if version > 40 {
+		return nil, errors.Errorf("qr: data of %d bytes is too long for level %s", len(data), level)
+	}
+
+	//terminator, byte alignment and padding
+	capacity := qrDataCodewords(version, lvl) * 8
+	for i := 0; i < 4 && len(bits) < capacity; i++ {
+		bits.append(0, 1)
+	}
+	for len(bits)%8 != 0 {
+		bits.append(0, 1)
+	}
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i, b := range bits {
+		if b {
+			codewords[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	q := newQRSymbol(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(qrInterleave(codewords, version, lvl))
+	q.applyBestMask(level)
+	return q.m, nil
+}
+
+// qrInterleave splits the data into blocks, appends the error correction codewords to each block and interleaves the blocks
+func qrInterleave(data []byte, version, level int) []byte {
+	numBlocks := qrBlocks[level][version]
+	eccLen := qrECCPerBlock[level][version]
+	rawCodewords := qrRawModules(version) / 8
+	numShort := numBlocks - rawCodewords%numBlocks
+	shortLen := rawCodewords / numBlocks
+
+	generator := qrField.generator(eccLen, 0)
+	var blocks [][]byte
+	pos := 0
+	for i := 0; i < numBlocks; i++ {
+		dataLen := shortLen - eccLen
+		if i >= numShort {
+			dataLen++
+		}
+		block := append([]byte{}, data[pos:pos+dataLen]...)
+		pos += dataLen
+		ecc := qrField.remainder(block, generator)
+		if i < numShort {
+			//short blocks get a placeholder, so that all blocks have the same length
+			block = append(block, 0)
+		}
+		blocks = append(blocks, append(block, ecc...))
+	}
+
+	var result []byte
+	for i := 0; i < len(blocks[0]); i++ {
+		for j, block := range blocks {
+			//skip the placeholder of short blocks
+			if i == shortLen-eccLen && j < numShort {
+				continue
+			}
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+type qrSymbol struct {
+	version  int
+	size     int
+	m        *Matrix
+	function []bool
+}
+
+func newQRSymbol(version int) *qrSymbol {
+	size := version*4 + 17
+	return &qrSymbol{
+		version:  version,
+		size:     size,
+		m:        newMatrix(size, size),
+		function: make([]bool, size*size),
+	}
+}
+
+func (q *qrSymbol) setFunction(x, y int, dark bool) {
+	q.m.set(x, y, dark)
+	q.function[y*q.size+x] = true
+}
+
+func (q *qrSymbol) isFunction(x, y int) bool {
+	return q.function[y*q.size+x]
+}
+
+// alignmentPositions returns the center coordinates of the alignment patterns
+func (q *qrSymbol) alignmentPositions() []int {
+	if q.version == 1 {
+		return nil
+	}
+	num := q.version/7 + 2
+	step := (q.version*4 + num*2 + 1) / (num*2 - 2) * 2
+	if q.version == 32 {
+		step = 26
+	}
+	positions := make([]int, num)
+	positions[0] = 6
+	for i, pos := num-1, q.size-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+func (q *qrSymbol) drawFunctionPatterns() {
+	//timing patterns
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+	//finder patterns with separators
+	for _, c := range [][2]int{{3, 3}, {q.size - 4, 3}, {3, q.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x < 0 || x >= q.size || y < 0 || y >= q.size {
+					continue
+				}
+				dist := max(abs(dx), abs(dy))
+				q.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+	//alignment patterns, except where they would overlap the finder patterns
+	positions := q.alignmentPositions()
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	//reserve the format information, which is drawn with the mask
+	q.drawFormatBits(0)
+	q.drawVersion()
+}
+
+// formatBits returns the 15 bits of level and mask with BCH error correction
+func qrFormatBits(level Level, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (q *qrSymbol) drawFormatBits(bits int) {
+	bit := func(i int) bool {
+		return bits>>i&1 == 1
+	}
+	//first copy around the top left finder
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+	//second copy split between the top right and bottom left finders
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// qrVersionBits returns the 18 bits of the version with BCH error correction
+func qrVersionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+func (q *qrSymbol) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+	bits := qrVersionBits(q.version)
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a := q.size - 11 + i%3
+		b := i / 3
+		q.setFunction(a, b, dark)
+		q.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag scan of two module wide columns from the bottom right
+func (q *qrSymbol) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			//skip the vertical timing pattern
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if upward {
+				y = q.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.isFunction(x, y) || i >= len(codewords)*8 {
+					continue
+				}
+				q.m.set(x, y, codewords[i/8]>>(7-i%8)&1 == 1)
+				i++
+			}
+		}
+	}
+}
+
+func qrMasked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (q *qrSymbol) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if !q.isFunction(x, y) && qrMasked(mask, x, y) {
+				q.m.set(x, y, !q.m.At(x, y))
+			}
+		}
+	}
+}
+
+// applyBestMask applies the mask with the lowest penalty. Masks are xor-ed, so applying a mask twice removes it.
+func (q *qrSymbol) applyBestMask(level Level) {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(qrFormatBits(level, mask))
+		if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		q.applyMask(mask)
+	}
+	q.applyMask(best)
+	q.drawFormatBits(qrFormatBits(level, best))
+}
+
+// penalty rates the symbol by the rules of the specification: runs of same color, 2x2 blocks, finder-like patterns and the dark/light balance
+func (q *qrSymbol) penalty() int {
+	penalty := 0
+	finderLike := []bool{true, false, true, true, true, false, true}
+	lines := func(at func(i, j int) bool) {
+		for i := 0; i < q.size; i++ {
+			run := 1
+			for j := 1; j <= q.size; j++ {
+				if j < q.size && at(i, j) == at(i, j-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += 3 + run - 5
+				}
+				run = 1
+			}
+			//1:1:3:1:1 with 4 light modules on one side
+			for j := 0; j+7 <= q.size; j++ {
+				match := true
+				for k, dark := range finderLike {
+					if at(i, j+k) != dark {
+						match = false
+						break
+					}
+				}
+				if !match {
+					continue
+				}
+				lightBefore, lightAfter := true, true
+				for k := 1; k <= 4; k++ {
+					lightBefore = lightBefore && (j-k < 0 || !at(i, j-k))
+					lightAfter = lightAfter && (j+6+k >= q.size || !at(i, j+6+k))
+				}
+				if lightBefore || lightAfter {
+					penalty += 40
+				}
+			}
+		}
+	}
+	lines(func(i, j int) bool { return q.m.At(j, i) })
+	lines(func(i, j int) bool { return q.m.At(i, j) })
+
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			c := q.m.At(x, y)
+			if c {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size && c == q.m.At(x+1, y) && c == q.m.At(x, y+1) && c == q.m.At(x+1, y+1) {
+				penalty += 3
+			}
+		}
+	}
+	total := q.size * q.size
+	//each 5% deviation from 50% dark modules costs 10
+	penalty += abs(dark*20-total*10) / total * 10
+	return penalty
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,64 @@
+package barcode
+
+// galoisField is GF(256) with the given reducing polynomial. QR codes use 0x11D and Data Matrix 0x12D.
+type galoisField struct {
+	exp [512]byte
+	log [256]byte
+}
+
+func newGaloisField(poly int) *galoisField {
+	gf := &galoisField{}
+	x := 1
+	for i := 0; i < 255; i++ {
+		gf.exp[i] = byte(x)
+		gf.log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= poly
+		}
+	}
+	//duplicate, so that exp[log a + log b] needs no modulo
+	for i := 255; i < 512; i++ {
+		gf.exp[i] = gf.exp[i-255]
+	}
+	return gf
+}
+
+func (gf *galoisField) mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf.exp[int(gf.log[a])+int(gf.log[b])]
+}
+
+// generator returns the coefficients (without the leading 1) of the generator polynomial (x - a^first)...(x - a^(first+degree-1))
+func (gf *galoisField) generator(degree, first int) []byte {
+	g := make([]byte, degree)
+	g[degree-1] = 1
+	root := gf.exp[first]
+	for i := 0; i < degree; i++ {
+		//multiply by (x - root)
+		for j := 0; j < degree; j++ {
+			g[j] = gf.mul(g[j], root)
+			if j+1 < degree {
+				g[j] ^= g[j+1]
+			}
+		}
+		root = gf.mul(root, 2)
+	}
+	return g
+}
+
+// remainder returns the error correction codewords of data, which is the remainder of the division by the generator
+func (gf *galoisField) remainder(data []byte, generator []byte) []byte {
+	rem := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[len(rem)-1] = 0
+		for i, g := range generator {
+			rem[i] ^= gf.mul(g, factor)
+		}
+	}
+	return rem
+}
@@ -0,0 +1,125 @@
+package xpdf
+
+import (
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/text"
+)
+
+// rtl reports if text with styles sty is written from right to left
+func rtl(sty style.Styles) bool {
+	return sty.Direction == style.DirectionRTL
+}
+
+// hAlign returns the alignment of the lines of text with styles sty, which is mirrored for right-to-left text
+func hAlign(sty style.Styles) style.HAlign {
+	if rtl(sty) {
+		return sty.HAlign.Mirrored()
+	}
+	return sty.HAlign
+}
+
+// bidi reports if the items of the line have to be reordered for display. Lines with tabs keep their order,
+// as the tab stops are positioned from the left.
+func (l textLine) bidi(rtl bool) bool {
+	if l.hasTabs() {
+		return false
+	}
+	if rtl {
+		return true
+	}
+	for _, item := range l.items {
+		if text.HasRTL(item.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// bidiToken is a part of an item of a line, whose runes have the same embedding level.
+// Spaces separating items and inline objects like images are tokens of their own.
+type bidiToken struct {
+	item   *textItem
+	runes  []rune
+	level  int
+	space  bool
+	object bool
+	// last is true for the token ending the item
+	last bool
+}
+
+// visualLine returns line with its items in the visual order of the Unicode Bidirectional Algorithm.
+// The parts of items with different levels become items of their own, right-to-left ones with reversed text.
+func visualLine(line textLine, rtl bool) textLine {
+	if !line.bidi(rtl) {
+		return line
+	}
+	//tokens of single runes first
+	var tokens []*bidiToken
+	var rs []rune
+	add := func(tok *bidiToken, r rune) {
+		tokens = append(tokens, tok)
+		rs = append(rs, r)
+	}
+	for i, item := range line.items {
+		itemRunes := []rune(item.text)
+		if i > 0 && !item.glued && len(itemRunes) > 0 && itemRunes[0] == ' ' {
+			add(&bidiToken{item: item, runes: []rune{' '}, space: true}, ' ')
+			itemRunes = itemRunes[1:]
+		}
+		if item.image != nil {
+			add(&bidiToken{item: item, object: true}, text.ObjectReplacement)
+		}
+		for _, r := range itemRunes {
+			add(&bidiToken{item: item, runes: []rune{r}}, r)
+		}
+		if n := len(tokens); n > 0 && tokens[n-1].item == item {
+			tokens[n-1].last = true
+		}
+	}
+	levels := text.BidiLevels(rs, rtl)
+	//merge runes of the same item and level
+	var merged []*bidiToken
+	var mergedLevels []int
+	for i, tok := range tokens {
+		tok.level = levels[i]
+		if n := len(merged); n > 0 {
+			prev := merged[n-1]
+			if prev.item == tok.item && prev.level == tok.level && !prev.space && !tok.space && !prev.object && !tok.object {
+				prev.runes = append(prev.runes, tok.runes...)
+				prev.last = tok.last
+				continue
+			}
+		}
+		merged = append(merged, tok)
+		mergedLevels = append(mergedLevels, tok.level)
+	}
+
+	visual := line
+	visual.items = nil
+	pending := false
+	for _, idx := range text.VisualOrder(mergedLevels) {
+		tok := merged[idx]
+		if tok.space {
+			pending = true
+			continue
+		}
+		item := *tok.item
+		item.text = string(tok.runes)
+		if tok.level%2 == 1 {
+			item.text = text.Reversed(item.text)
+		}
+		if !tok.object {
+			item.image = nil
+		}
+		if !tok.last {
+			item.footnote = nil
+		}
+		item.glued = !pending && len(visual.items) > 0
+		if pending && len(visual.items) > 0 {
+			item.text = " " + item.text
+		}
+		pending = false
+		visual.items = append(visual.items, &item)
+	}
+	return visual
+}
@@ -0,0 +1,36 @@
+package xpdf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVisualLine(t *testing.T) {
+	tests := []struct {
+		items []string
+		rtl   bool
+		exp   string
+	}{
+		{items: []string{"hello", " world"}, exp: `["hello" " world"]`},
+		{items: []string{"hello", " שלום", " עולם", " world"}, exp: `["hello" " םלוע" " םולש" " world"]`},
+		{items: []string{"שלום", " עולם"}, exp: `["םלוע" " םולש"]`},
+		{items: []string{"שלום", " עולם"}, rtl: true, exp: `["םלוע" " םולש"]`},
+		{items: []string{"hello", " world"}, rtl: true, exp: `["hello" " world"]`},
+		{items: []string{"שלום", " (abc", " 12)"}, rtl: true, exp: `["(" "abc" " 12" ")" " םולש"]`},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var line textLine
+			for _, s := range test.items {
+				line.items = append(line.items, &textItem{text: s})
+			}
+			var have []string
+			for _, item := range visualLine(line, test.rtl).items {
+				have = append(have, item.text)
+			}
+			if s := fmt.Sprintf("%q", have); s != test.exp {
+				t.Fatalf("have %s, want %s", s, test.exp)
+			}
+		})
+	}
+}
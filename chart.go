@@ -0,0 +1,397 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+// chartPalette colors series, which have no color of their own, and the slices of pie charts
+var chartPalette = []style.RGB{
+	{R: 0x1f, G: 0x77, B: 0xb4},
+	{R: 0xff, G: 0x7f, B: 0x0e},
+	{R: 0x2c, G: 0xa0, B: 0x2c},
+	{R: 0xd6, G: 0x27, B: 0x28},
+	{R: 0x94, G: 0x67, B: 0xbd},
+	{R: 0x8c, G: 0x56, B: 0x4b},
+	{R: 0xe3, G: 0x77, B: 0xc2},
+	{R: 0x7f, G: 0x7f, B: 0x7f},
+}
+
+const (
+	// height of charts without height style relative to their width
+	chartAspect     = 0.6
+	chartTickLength = 1.5
+	chartBarGroup   = 0.8
+)
+
+type chartSeries struct {
+	name   string
+	values []float64
+	sty    style.Styles
+	color  style.RGB
+}
+
+type legendEntry struct {
+	text  string
+	color style.RGB
+}
+
+// chartArea is the area of a chart, where x0,y0 is the top left and x1,y1 the bottom right corner
+type chartArea struct {
+	x0, y0, x1, y1 float64
+}
+
+func (a chartArea) width() float64  { return a.x1 - a.x0 }
+func (a chartArea) height() float64 { return a.y1 - a.y0 }
+
+// niceScale returns the bounds and the tick step of an axis, which covers min, max and zero with round numbers
+func niceScale(min, max float64, maxTicks int) (lo, hi, step float64) {
+	min, max = math.Min(min, 0), math.Max(max, 0)
+	if max == min {
+		max = min + 1
+	}
+	if maxTicks < 2 {
+		maxTicks = 2
+	}
+	raw := (max - min) / float64(maxTicks)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step = 10 * mag
+	for _, f := range []float64{1, 2, 2.5, 5} {
+		if raw <= f*mag {
+			step = f * mag
+			break
+		}
+	}
+	return math.Floor(min/step) * step, math.Ceil(max/step) * step, step
+}
+
+// formatTick formats v with as many decimals as the step needs
+func formatTick(v, step float64) string {
+	decimals := 0
+	for d := step; decimals < 6 && math.Abs(d-math.Round(d)) > 1e-9; d *= 10 {
+		decimals++
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	//values rounded to zero must not keep their sign
+	if strings.Trim(s, "-0.") == "" {
+		s = strings.TrimPrefix(s, "-")
+	}
+	return s
+}
+
+func (p *Processor) chartSeries(ch *xdoc.Chart, sty style.Styles) []chartSeries {
+	var series []chartSeries
+	for i, s := range ch.Series {
+		ssty := s.MutatedStyles(p.doc.StyleClasses(), sty)
+		color := ssty.Foreground
+		if color == sty.Foreground {
+			color = chartPalette[i%len(chartPalette)]
+		}
+		series = append(series, chartSeries{
+			name:   s.Name,
+			values: s.Values,
+			sty:    ssty,
+			color:  color,
+		})
+	}
+	return series
+}
+
+// chartHeight returns the height of the chart box
+func chartHeight(sty style.Styles, width float64) float64 {
+	if sty.Height > 0 {
+		return sty.Height
+	}
+	return width * chartAspect
+}
+
+func chartWidth(sty style.Styles, pa PrintableArea) float64 {
+	if sty.Width > 0 {
+		return math.Min(sty.Width, pa.Width())
+	}
+	return pa.Width()
+}
+
+func (p *Processor) renderChart(ch *xdoc.Chart, pa PrintableArea) {
+	defer p.resetStyles()
+	sty := ch.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+	width := chartWidth(sty, pa)
+	height := chartHeight(sty, width)
+
+	xStart, yStart := p.engine.GetXY()
+	if !p.preventPageBreak && !p.fitsOnPage(height+sty.OffsetY) && !p.atPageTop() {
+		xStart = p.newPageAt(xStart)
+		_, yStart = p.engine.GetXY()
+	}
+	x0 := xStart + sty.OffsetX
+	y0 := yStart + sty.OffsetY
+	p.drawBox(x0, y0, x0+width, y0+height, sty)
+
+	area := chartArea{
+		x0: x0 + sty.Padding.Left,
+		y0: y0 + sty.Padding.Top,
+		x1: x0 + width - sty.Padding.Right,
+		y1: y0 + height - sty.Padding.Bottom,
+	}
+	p.engine.ChangeFont(sty.Font)
+	p.engine.SetTextColor(sty.Text.Values())
+	series := p.chartSeries(ch, sty)
+
+	var legend []legendEntry
+	switch ch.Type {
+	case xdoc.ChartPie:
+		legend = pieLegend(ch.Labels, series[0])
+	default:
+		for _, s := range series {
+			if s.name != "" {
+				legend = append(legend, legendEntry{text: s.name, color: s.color})
+			}
+		}
+	}
+	area.y1 -= p.drawLegend(legend, area)
+
+	switch ch.Type {
+	case xdoc.ChartPie:
+		p.drawPieChart(series[0], area, sty)
+	default:
+		p.drawAxisChart(ch, series, area, sty)
+	}
+
+	p.engine.SetX(xStart)
+	p.engine.SetY(y0 + height)
+}
+
+// drawLegend draws the entries centered in rows at the bottom of the area and returns the height used
+func (p *Processor) drawLegend(entries []legendEntry, area chartArea) float64 {
+	if len(entries) == 0 {
+		return 0
+	}
+	fh := p.engine.FontHeight()
+	swatch := fh * 0.6
+	gap := fh / 2
+	entryWidth := func(e legendEntry) float64 {
+		return swatch + gap + p.engine.TextWidth(e.text)
+	}
+	//break the entries into rows
+	var rows [][]legendEntry
+	var row []legendEntry
+	rowWidth := 0.0
+	for _, e := range entries {
+		w := entryWidth(e)
+		if len(row) > 0 && rowWidth+2*gap+w > area.width() {
+			rows = append(rows, row)
+			row, rowWidth = nil, 0
+		}
+		if len(row) > 0 {
+			rowWidth += 2 * gap
+		}
+		row = append(row, e)
+		rowWidth += w
+	}
+	rows = append(rows, row)
+
+	height := float64(len(rows))*fh + gap
+	y := area.y1 - float64(len(rows))*fh
+	for _, row := range rows {
+		w := 0.0
+		for i, e := range row {
+			if i > 0 {
+				w += 2 * gap
+			}
+			w += entryWidth(e)
+		}
+		x := area.x0 + (area.width()-w)/2
+		for _, e := range row {
+			p.engine.SetFillColor(e.color.Values())
+			p.engine.FillRect(x, y+(fh-swatch)/2, swatch, swatch)
+			p.engine.TextAt(x+swatch+gap, y+baselineRatio*fh, e.text)
+			x += entryWidth(e) + 2*gap
+		}
+		y += fh
+	}
+	return height
+}
+
+// drawAxisChart draws bar and line charts with a value axis on the left and the categories below
+func (p *Processor) drawAxisChart(ch *xdoc.Chart, series []chartSeries, area chartArea, sty style.Styles) {
+	fh := p.engine.FontHeight()
+	gap := fh / 3
+	categories := len(ch.Labels)
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		if len(s.values) > categories {
+			categories = len(s.values)
+		}
+		for _, v := range s.values {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+	}
+	if categories == 0 {
+		return
+	}
+
+	//the plot area leaves room for the tick labels
+	plot := area
+	plot.y0 += fh / 2
+	plot.y1 -= chartTickLength + gap + fh
+	maxTicks := int(plot.height() / (fh * 2))
+	lo, hi, step := niceScale(min, max, maxTicks)
+	var ticks []float64
+	labelWidth := 0.0
+	for i := 0; lo+float64(i)*step <= hi+step/2; i++ {
+		v := lo + float64(i)*step
+		ticks = append(ticks, v)
+		labelWidth = math.Max(labelWidth, p.engine.TextWidth(formatTick(v, step)))
+	}
+	plot.x0 += labelWidth + gap + chartTickLength
+	yOf := func(v float64) float64 {
+		return plot.y1 - (v-lo)/(hi-lo)*plot.height()
+	}
+	slot := plot.width() / float64(categories)
+
+	//series below the axes
+	switch ch.Type {
+	case xdoc.ChartLine:
+		for _, s := range series {
+			if len(s.values) == 0 {
+				continue
+			}
+			p.engine.SetLineWidth(s.sty.LineWidth)
+			p.engine.SetDrawColor(s.color.Values())
+			p.engine.SetFillColor(s.color.Values())
+			for i, v := range s.values {
+				x := plot.x0 + (float64(i)+0.5)*slot
+				if i == 0 {
+					p.engine.MoveTo(x, yOf(v))
+				} else {
+					p.engine.LineTo(x, yOf(v))
+				}
+			}
+			p.engine.DrawPath()
+			marker := math.Max(3*s.sty.LineWidth, fh/4)
+			for i, v := range s.values {
+				x := plot.x0 + (float64(i)+0.5)*slot
+				p.engine.FillRect(x-marker/2, yOf(v)-marker/2, marker, marker)
+			}
+		}
+	default:
+		barWidth := slot * chartBarGroup / float64(len(series))
+		for si, s := range series {
+			p.engine.SetFillColor(s.color.Values())
+			for i, v := range s.values {
+				x := plot.x0 + float64(i)*slot + slot*(1-chartBarGroup)/2 + float64(si)*barWidth
+				top, bottom := yOf(math.Max(v, 0)), yOf(math.Min(v, 0))
+				p.engine.FillRect(x, top, barWidth, bottom-top)
+			}
+		}
+	}
+
+	//axes and ticks
+	p.engine.SetLineWidth(sty.LineWidth)
+	p.engine.SetDrawColor(sty.Foreground.Values())
+	p.engine.MoveTo(plot.x0, plot.y0)
+	p.engine.LineTo(plot.x0, plot.y1)
+	p.engine.MoveTo(plot.x0, yOf(0))
+	p.engine.LineTo(plot.x1, yOf(0))
+	for _, v := range ticks {
+		p.engine.MoveTo(plot.x0-chartTickLength, yOf(v))
+		p.engine.LineTo(plot.x0, yOf(v))
+	}
+	for i := 0; i <= categories; i++ {
+		x := plot.x0 + float64(i)*slot
+		p.engine.MoveTo(x, plot.y1)
+		p.engine.LineTo(x, plot.y1+chartTickLength)
+	}
+	p.engine.DrawPath()
+
+	for _, v := range ticks {
+		s := formatTick(v, step)
+		p.engine.TextAt(plot.x0-chartTickLength-gap-p.engine.TextWidth(s), yOf(v)+(baselineRatio-0.5)*fh, s)
+	}
+	//show every nth category label, if the labels don't fit into their slots
+	every := 1
+	for _, l := range ch.Labels {
+		if n := int(math.Ceil(p.engine.TextWidth(l) / (slot * 0.9))); n > every {
+			every = n
+		}
+	}
+	for i, l := range ch.Labels {
+		if i%every != 0 {
+			continue
+		}
+		x := plot.x0 + (float64(i)+0.5)*slot - p.engine.TextWidth(l)/2
+		p.engine.TextAt(x, plot.y1+chartTickLength+gap+baselineRatio*fh, l)
+	}
+}
+
+func pieLegend(labels []string, s chartSeries) []legendEntry {
+	total := 0.0
+	for _, v := range s.values {
+		total += math.Max(v, 0)
+	}
+	var entries []legendEntry
+	for i, v := range s.values {
+		text := fmt.Sprintf("#%d", i+1)
+		if i < len(labels) {
+			text = labels[i]
+		}
+		if total > 0 {
+			text += fmt.Sprintf(" (%.0f%%)", math.Max(v, 0)/total*100)
+		}
+		entries = append(entries, legendEntry{text: text, color: chartPalette[i%len(chartPalette)]})
+	}
+	return entries
+}
+
+// drawPieChart draws the slices clockwise starting at the top. Negative values are ignored.
+func (p *Processor) drawPieChart(s chartSeries, area chartArea, sty style.Styles) {
+	total := 0.0
+	for _, v := range s.values {
+		total += math.Max(v, 0)
+	}
+	if total == 0 {
+		return
+	}
+	cx, cy := (area.x0+area.x1)/2, (area.y0+area.y1)/2
+	r := math.Min(area.width(), area.height()) / 2
+	angle := -math.Pi / 2
+	for i, v := range s.values {
+		if v <= 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		p.engine.SetFillColor(chartPalette[i%len(chartPalette)].Values())
+		p.slicePath(cx, cy, r, angle, angle+sweep)
+		p.engine.FillPath(false)
+		//separate the slices with lines of the background color
+		p.engine.SetLineWidth(sty.LineWidth)
+		p.engine.SetDrawColor(sty.Background.Values())
+		p.slicePath(cx, cy, r, angle, angle+sweep)
+		p.engine.DrawPath()
+		angle += sweep
+	}
+}
+
+// slicePath adds the path of a pie slice from angle a0 to a1, where the arc is approximated by cubic curves of at most 90 degrees
+func (p *Processor) slicePath(cx, cy, r, a0, a1 float64) {
+	p.engine.MoveTo(cx, cy)
+	p.engine.LineTo(cx+r*math.Cos(a0), cy+r*math.Sin(a0))
+	segments := int(math.Ceil((a1 - a0) / (math.Pi / 2)))
+	da := (a1 - a0) / float64(segments)
+	k := 4.0 / 3.0 * math.Tan(da/4) * r
+	for i := 0; i < segments; i++ {
+		s := a0 + float64(i)*da
+		e := s + da
+		p.engine.CurveTo(
+			cx+r*math.Cos(s)-k*math.Sin(s), cy+r*math.Sin(s)+k*math.Cos(s),
+			cx+r*math.Cos(e)+k*math.Sin(e), cy+r*math.Sin(e)-k*math.Cos(e),
+			cx+r*math.Cos(e), cy+r*math.Sin(e),
+		)
+	}
+	p.engine.ClosePath()
+}
@@ -0,0 +1,46 @@
+package xpdf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNiceScale(t *testing.T) {
+	tests := []struct {
+		min, max     float64
+		ticks        int
+		lo, hi, step float64
+	}{
+		{min: 10, max: 33, ticks: 5, lo: 0, hi: 40, step: 10},
+		{min: -5, max: 33, ticks: 5, lo: -10, hi: 40, step: 10},
+		{min: 0.1, max: 0.5, ticks: 5, lo: 0, hi: 0.5, step: 0.1},
+		{min: -120, max: -30, ticks: 4, lo: -150, hi: 0, step: 50},
+		{min: 7, max: 7, ticks: 5, lo: 0, hi: 8, step: 2},
+		{min: 0, max: 0, ticks: 5, lo: 0, hi: 1, step: 0.2},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			lo, hi, step := niceScale(test.min, test.max, test.ticks)
+			if fmt.Sprintf("%g %g %g", lo, hi, step) != fmt.Sprintf("%g %g %g", test.lo, test.hi, test.step) {
+				t.Fatalf("have %g..%g step %g, want %g..%g step %g", lo, hi, step, test.lo, test.hi, test.step)
+			}
+		})
+	}
+}
+
+func TestFormatTick(t *testing.T) {
+	tests := []struct {
+		v, step float64
+		out     string
+	}{
+		{v: 20, step: 10, out: "20"},
+		{v: 0.30000000000000004, step: 0.1, out: "0.3"},
+		{v: 2.5, step: 2.5, out: "2.5"},
+		{v: -0.0000001, step: 0.25, out: "0.00"},
+	}
+	for _, test := range tests {
+		if have := formatTick(test.v, test.step); have != test.out {
+			t.Fatalf("format %g (step %g): have %q, want %q", test.v, test.step, have, test.out)
+		}
+	}
+}
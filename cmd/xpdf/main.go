@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,9 +14,43 @@ import (
 	"github.com/mazzegi/xpdf/xdoc"
 )
 
+// langFiles are hyphenation pattern files by language given as lang=file
+type langFiles map[string]string
+
+func (lf langFiles) String() string {
+	return fmt.Sprintf("%v", map[string]string(lf))
+}
+
+func (lf langFiles) Set(s string) error {
+	lang, file, ok := strings.Cut(s, "=")
+	if !ok || lang == "" || file == "" {
+		return fmt.Errorf("invalid value %q - must be lang=file", s)
+	}
+	lf[lang] = file
+	return nil
+}
+
+// loadFonts returns the registry of the font definition file or of the fonts found in the directory path
+func loadFonts(path string) (*font.Registry, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return font.LoadRegistryFromDirs(path)
+	}
+	return font.LoadRegistryFromFile(path)
+}
+
 func main() {
+	patterns := langFiles{}
+	flag.Var(patterns, "hyphenation", "TeX hyphenation patterns of a language like de=hyph-de-1996.pat.txt (repeatable)")
+	fontsPath := flag.String("fonts", "fonts/fontdef.toml", "font definition file or directory, which is scanned for font files")
+	flag.Parse()
+	args := flag.Args()
+
 	var in string
-	if len(os.Args) < 2 {
+	if len(args) < 1 {
 		// fmt.Println("usage: xpdf <in> <optional:out>")
 		// os.Exit(1)
 		//in = "../../examples/measure.xml"
@@ -27,11 +62,11 @@ func main() {
 		in = "../../examples/grid.xml"
 		//in = "../../examples/table1.xml"
 	} else {
-		in = os.Args[1]
+		in = args[0]
 	}
 	var out string
-	if len(os.Args) >= 3 {
-		out = os.Args[2]
+	if len(args) >= 2 {
+		out = args[1]
 	} else {
 		base := filepath.Base(in)
 		ext := filepath.Ext(in)
@@ -50,7 +85,7 @@ func main() {
 	}
 	defer outF.Close()
 
-	fonts, err := font.LoadRegistryFromFile("fonts/fontdef.toml")
+	fonts, err := loadFonts(*fontsPath)
 	if err != nil {
 		fmt.Printf("ERROR loading font-def: %v\n", err)
 		os.Exit(2)
@@ -64,8 +99,20 @@ func main() {
 
 	wd, _ := os.Getwd()
 
-	hyp := hyphenation.NewEnUs()
+	//text without a language is hyphenated in the one of the document, if it declares one
+	hyp, ok := hyphenation.ForLang(doc.Lang)
+	if !ok {
+		hyp = hyphenation.NewEnUs()
+	}
 	p := xpdf.NewProcessor(engine, hyp, doc, wd)
+	for lang, file := range patterns {
+		lh, err := hyphenation.LoadTeXFiles(file, "")
+		if err != nil {
+			fmt.Printf("ERROR loading hyphenation patterns: %v\n", err)
+			os.Exit(2)
+		}
+		p.SetHyphenator(lang, lh)
+	}
 	err = p.Process(outF)
 	if err != nil {
 		fmt.Println("ERROR processing input:", err)
@@ -0,0 +1,176 @@
+package xpdf
+
+import (
+	"math"
+
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+// columnFlow lets the document flow through columns. Content continues at the top of the next column,
+// when reaching the bottom of a column, and on the next page, when reaching the bottom of the last column.
+type columnFlow struct {
+	count int
+	gap   float64
+	areas []PrintableArea
+	idx   int
+	// lowest position reached in the columns of the current page
+	bottom float64
+	// pages of the flow - the last one gets balanced columns
+	page      int
+	lastPage  int
+	lastPageH float64
+}
+
+func (cf *columnFlow) area() PrintableArea {
+	return cf.areas[cf.idx]
+}
+
+// layout computes the column areas of the printable area pa starting at y0.
+// If height is greater than zero, all but the last column are limited to that height.
+func (cf *columnFlow) layout(pa PrintableArea, y0 float64, height float64) {
+	width := (pa.Width() - float64(cf.count-1)*cf.gap) / float64(cf.count)
+	cf.areas = make([]PrintableArea, cf.count)
+	for i := range cf.areas {
+		x0 := pa.x0 + float64(i)*(width+cf.gap)
+		y1 := pa.y1
+		if height > 0 && i < cf.count-1 && y0+height < pa.y1 {
+			y1 = y0 + height
+		}
+		cf.areas[i] = PrintableArea{
+			x0: x0,
+			y0: y0,
+			x1: x0 + width,
+			y1: y1,
+		}
+	}
+	cf.idx = 0
+	cf.bottom = y0
+}
+
+// balanceTolerance keeps lines starting at the balanced height of a column out of it despite rounding errors
+const balanceTolerance = 0.01
+
+// balance sets the last page of the flow and the height of its columns, so that the content of contentHeight is
+// distributed equally over them. The columns of the first page have the height first, the ones of the following pages
+// the height page. The slack is added to the balanced height, as lines cannot be split.
+func (cf *columnFlow) balance(contentHeight, first, page, slack float64) {
+	firstCapacity := float64(cf.count) * first
+	if contentHeight <= firstCapacity {
+		cf.lastPage = 0
+		cf.lastPageH = contentHeight/float64(cf.count) + slack
+		return
+	}
+	pageCapacity := float64(cf.count) * page
+	rest := contentHeight - firstCapacity
+	pages := math.Ceil(rest / pageCapacity)
+	cf.lastPage = int(pages)
+	cf.lastPageH = (rest-(pages-1)*pageCapacity)/float64(cf.count) + slack
+}
+
+// reached records y as a position in the current column
+func (cf *columnFlow) reached(y float64) {
+	if y > cf.bottom {
+		cf.bottom = y
+	}
+}
+
+// columnsContentHeight returns the accumulated height of the instructions. If any of it cannot be measured in advance, ok is false.
+func (p *Processor) columnsContentHeight(iss []xdoc.Instruction) (height float64, ok bool) {
+	defer p.resetStyles()
+	for _, i := range iss {
+		switch i.(type) {
+		case *xdoc.Font:
+			p.changeFont(i.MutatedStyles(p.doc.StyleClasses(), p.currStyles).Font)
+			continue
+		case *xdoc.TextBlock, *xdoc.SetX:
+			continue
+		}
+		h, ok := p.blockHeight(i)
+		if !ok {
+			return 0, false
+		}
+		if text, isText := i.(*xdoc.Text); isText && len(text.ISS) > 0 {
+			//the next block starts after the line spacing below the last line
+			sty := text.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+			p.engine.ChangeFont(sty.Font)
+			h += p.engine.FontHeight() * (sty.LineSpacing - 1)
+			p.resetStyles()
+		}
+		height += h
+	}
+	return height, true
+}
+
+// renderColumns lets the instructions of cols flow through its columns. Of the styles of cols only offset-y and the
+// pagination rules apply, the instructions don't inherit them.
+func (p *Processor) renderColumns(cols *xdoc.Columns) {
+	if p.columns != nil {
+		//nested columns just continue in the current column
+		p.processInstructions(cols.Instructions)
+		return
+	}
+	sty := cols.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+	pa := p.page().printableArea
+	_, y := p.engine.GetXY()
+	y += sty.OffsetY
+
+	cf := &columnFlow{
+		count: cols.Count,
+		gap:   cols.Gap,
+	}
+	if cf.count < 1 {
+		cf.count = 1
+	}
+	cf.lastPage = -1
+	cf.layout(pa, y, 0)
+	font := p.currStyles.Font
+	p.columns = cf
+	contentHeight, ok := p.columnsContentHeight(cols.ISS)
+	p.changeFont(font)
+	if ok {
+		//a column takes the lines starting above the balanced height, which reach below it by the font height
+		slack := p.engine.FontHeight() - balanceTolerance
+		cf.balance(contentHeight, pa.y1-y, pa.Height(), slack)
+	}
+	if cf.lastPage == 0 {
+		cf.layout(pa, y, cf.lastPageH)
+	}
+
+	p.engine.SetX(cf.area().x0)
+	p.engine.SetY(y)
+	p.processInstructions(cols.Instructions)
+	_, yEnd := p.engine.GetXY()
+	cf.reached(yEnd)
+	p.columns = nil
+
+	p.engine.SetX(pa.x0)
+	p.engine.SetY(cf.bottom)
+}
+
+// nextColumn moves the flow to the top of the next column. It returns false, if the flow is already in the last column of the page.
+func (p *Processor) nextColumn() bool {
+	cf := p.columns
+	_, y := p.engine.GetXY()
+	cf.reached(y)
+	if cf.idx >= len(cf.areas)-1 {
+		return false
+	}
+	cf.idx++
+	p.engine.SetX(cf.area().x0)
+	p.engine.SetY(cf.area().y0)
+	return true
+}
+
+// newColumnsPage lays out the columns of a new page
+func (p *Processor) newColumnsPage() {
+	cf := p.columns
+	cf.page++
+	pa := p.pageArea()
+	var height float64
+	if cf.page == cf.lastPage {
+		height = cf.lastPageH
+	}
+	cf.layout(pa, pa.y0, height)
+	p.engine.SetX(cf.area().x0)
+	p.engine.SetY(cf.area().y0)
+}
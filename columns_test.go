@@ -0,0 +1,131 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestColumnLayout(t *testing.T) {
+	pa := PrintableArea{x0: 20, y0: 20, x1: 190, y1: 277}
+	tests := []struct {
+		count  int
+		gap    float64
+		y0     float64
+		height float64
+		exp    []PrintableArea
+	}{
+		{count: 1, gap: 10, y0: 20, exp: []PrintableArea{{x0: 20, y0: 20, x1: 190, y1: 277}}},
+		{count: 2, gap: 10, y0: 50, exp: []PrintableArea{
+			{x0: 20, y0: 50, x1: 100, y1: 277},
+			{x0: 110, y0: 50, x1: 190, y1: 277},
+		}},
+		{count: 3, gap: 10, y0: 20, exp: []PrintableArea{
+			{x0: 20, y0: 20, x1: 70, y1: 277},
+			{x0: 80, y0: 20, x1: 130, y1: 277},
+			{x0: 140, y0: 20, x1: 190, y1: 277},
+		}},
+		//all but the last column are limited to the height
+		{count: 3, gap: 10, y0: 20, height: 100, exp: []PrintableArea{
+			{x0: 20, y0: 20, x1: 70, y1: 120},
+			{x0: 80, y0: 20, x1: 130, y1: 120},
+			{x0: 140, y0: 20, x1: 190, y1: 277},
+		}},
+		//a height beyond the page is ignored
+		{count: 2, gap: 10, y0: 200, height: 100, exp: []PrintableArea{
+			{x0: 20, y0: 200, x1: 100, y1: 277},
+			{x0: 110, y0: 200, x1: 190, y1: 277},
+		}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			cf := &columnFlow{count: test.count, gap: test.gap, idx: 1}
+			cf.layout(pa, test.y0, test.height)
+			if len(cf.areas) != len(test.exp) {
+				t.Fatalf("have %d columns, want %d", len(cf.areas), len(test.exp))
+			}
+			for ic, area := range cf.areas {
+				if area != test.exp[ic] {
+					t.Fatalf("column %d: have %s, want %s", ic, area, test.exp[ic])
+				}
+			}
+			if cf.idx != 0 || cf.bottom != test.y0 {
+				t.Fatalf("have column %d at bottom %.1f, want column 0 at %.1f", cf.idx, cf.bottom, test.y0)
+			}
+		})
+	}
+}
+
+func TestColumnBalance(t *testing.T) {
+	tests := []struct {
+		count         int
+		contentHeight float64
+		first, page   float64
+		lastPage      int
+		lastPageH     float64
+	}{
+		{count: 2, contentHeight: 100, first: 200, page: 250, lastPage: 0, lastPageH: 55},
+		{count: 3, contentHeight: 300, first: 100, page: 250, lastPage: 0, lastPageH: 105},
+		//the rest of 100 after the first page
+		{count: 2, contentHeight: 300, first: 100, page: 250, lastPage: 1, lastPageH: 55},
+		//the rest of 100 after the first and second page
+		{count: 2, contentHeight: 800, first: 100, page: 250, lastPage: 2, lastPageH: 55},
+		{count: 1, contentHeight: 120, first: 100, page: 250, lastPage: 1, lastPageH: 25},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			cf := &columnFlow{count: test.count}
+			cf.balance(test.contentHeight, test.first, test.page, 5)
+			if cf.lastPage != test.lastPage || math.Abs(cf.lastPageH-test.lastPageH) > 1e-9 {
+				t.Fatalf("have page %d with height %.1f, want page %d with height %.1f", cf.lastPage, cf.lastPageH, test.lastPage, test.lastPageH)
+			}
+		})
+	}
+}
+
+func TestBalancedColumns(t *testing.T) {
+	tests := []struct {
+		count int
+		lines int
+	}{
+		{count: 2, lines: 10},
+		{count: 2, lines: 9},
+		{count: 3, lines: 12},
+		{count: 3, lines: 200},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			body := strings.Repeat("<text>line</text>", test.lines)
+			e := processTestDoc(t, "", fmt.Sprintf(`<columns count="%d" gap="10">%s</columns>`, test.count, body))
+			lastPage := e.find(t, "line").page
+			for _, rt := range e.texts {
+				if rt.text == "line" && rt.page > lastPage {
+					lastPage = rt.page
+				}
+			}
+			//the lines of the last page are distributed equally
+			perColumn := map[int]int{}
+			for _, rt := range e.texts {
+				if rt.text == "line" && rt.page == lastPage {
+					perColumn[int(rt.x)]++
+				}
+			}
+			if len(perColumn) != test.count {
+				t.Fatalf("have %d columns on the last page, want %d", len(perColumn), test.count)
+			}
+			min, max := test.lines, 0
+			for _, n := range perColumn {
+				if n < min {
+					min = n
+				}
+				if n > max {
+					max = n
+				}
+			}
+			if max-min > 1 {
+				t.Fatalf("have %d to %d lines in the columns, want balanced ones", min, max)
+			}
+		})
+	}
+}
@@ -10,6 +10,9 @@ func DefaultStyle() style.Styles {
 			Style:      style.FontStyleNormal,
 			Weight:     style.FontWeightNormal,
 			Decoration: style.FontDecorationNormal,
+			Variant:    style.FontVariantNormal,
+			Kerning:    style.FontKerningAuto,
+			Ligatures:  style.FontLigaturesNormal,
 		},
 		Box: style.Box{
 			Border:  style.Border{Left: 0, Top: 0, Right: 0, Bottom: 0},
@@ -27,8 +30,9 @@ func DefaultStyle() style.Styles {
 			ColumnWidth: -1,
 		},
 		Align: style.Align{
-			HAlign: style.HAlignLeft,
-			VAlign: style.VAlignTop,
+			HAlign:        style.HAlignLeft,
+			VAlign:        style.VAlignTop,
+			VerticalAlign: style.VerticalAlign{Mode: style.VerticalAlignBaseline},
 		},
 		Color: style.Color{
 			Foreground: style.Black,
@@ -38,5 +42,25 @@ func DefaultStyle() style.Styles {
 		Draw: style.Draw{
 			LineWidth: 1,
 		},
+		Pagination: style.Pagination{
+			Widows:          2,
+			Orphans:         2,
+			PageBreakBefore: style.PageBreakAuto,
+			PageBreakAfter:  style.PageBreakAuto,
+		},
+		Floating: style.Floating{
+			Float: style.FloatNone,
+			Clear: style.ClearNone,
+		},
+		Image: style.Image{
+			ObjectFit: style.ObjectFitFill,
+		},
+		Typography: style.Typography{
+			TextTransform: style.TextTransformNone,
+			Overflow:      style.OverflowWrap,
+			WhiteSpace:    style.WhiteSpaceNormal,
+			Hyphens:       style.HyphensAuto,
+			Direction:     style.DirectionLTR,
+		},
 	}
 }
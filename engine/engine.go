@@ -22,12 +22,21 @@ type Engine interface {
 	PrintableArea() (x0, y0, x1, y1 float64)
 	PageWidth() float64
 	PageHeight() float64
-	PutImage(src string, x, y, width, height float64)
+	PutImage(name string, data []byte, format string, x, y, width, height float64)
 	SetTextColor(r, g, b int)
 	FontHeight() float64
 	MonoFont() string
 	TextWidth(s string) float64
+	// Kerning returns the adjustment of the width of a followed by b in the current font
+	Kerning(a, b rune) float64
+	// Ligatures returns s with the standard ligatures of the current font
+	Ligatures(s string) string
+	// HasGlyph reports if the font fnt of a single family has a glyph for r, which the engine can write
+	HasGlyph(fnt style.Font, r rune) bool
 	WriteText(s string)
+	// SetCharSpacing sets the extra space after each character of the following text
+	SetCharSpacing(spacing float64)
+	TextAt(x, y float64, s string)
 	Margins() (left, top, right, bottom float64)
 
 	//drawing stuff
@@ -37,5 +46,13 @@ type Engine interface {
 	FillRect(x, y, width, height float64)
 	MoveTo(x, y float64)
 	LineTo(x, y float64)
+	CurveTo(cx0, cy0, cx1, cy1, x, y float64)
+	ClosePath()
 	DrawPath()
+	FillPath(evenOdd bool)
+	ClipRect(x, y, width, height float64)
+	ClipEnd()
+	TransformBegin()
+	Transform(a, b, c, d, e, f float64)
+	TransformEnd()
 }
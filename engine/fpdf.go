@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"bytes"
+	"fmt"
 	"io"
-	"io/ioutil"
+	"strings"
+	"unicode"
 
 	"github.com/jung-kurt/gofpdf/v2"
 	"github.com/mazzegi/xpdf/font"
@@ -44,8 +47,7 @@ func fpdfFontStyle(fnt style.Font) string {
 	case style.FontStyleItalic:
 		s += "I"
 	}
-	switch fnt.Weight {
-	case style.FontWeightBold:
+	if fnt.Weight.Value() >= 600 {
 		s += "B"
 	}
 	switch fnt.Decoration {
@@ -59,10 +61,68 @@ type FPDF struct {
 	pdf              *gofpdf.Fpdf
 	monoFont         string
 	translateUnicode func(s string) string
+	charSpacing      float64
+	fonts            *font.Registry
+	// families are the registered font families in lower case
+	families map[string]bool
+	// added are the registered fonts, which have been added to gofpdf on first use
+	added map[font.Descriptor]addedFont
+	// face is the parsed font file of the current font
+	face *font.Face
+}
+
+// addedFont is a registered font added to gofpdf with its family name there and its parsed font file
+type addedFont struct {
+	key  string
+	face *font.Face
+}
+
+// coreFonts are the standard fonts of pdf, which are always available
+var coreFonts = map[string]bool{
+	"arial":        true,
+	"courier":      true,
+	"helvetica":    true,
+	"times":        true,
+	"symbol":       true,
+	"zapfdingbats": true,
+}
+
+// fontKey returns the family name of the registered font fd in gofpdf, which becomes part of the font name in the pdf
+func fontKey(fd font.Descriptor) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, fd.Name)
+	key := fmt.Sprintf("%s-%d", name, fd.Weight)
+	if fd.Style.Italic() {
+		key += "-italic"
+	}
+	return key
+}
+
+// basicPlane returns s with the characters beyond the basic multilingual plane like emoji replaced by U+FFFD, as gofpdf
+// only reads the glyphs of the basic plane from font files.
+func basicPlane(s string) string {
+	for _, r := range s {
+		if r > 0xffff {
+			return strings.Map(func(r rune) rune {
+				if r <= 0xffff {
+					return r
+				}
+				return '\ufffd'
+			}, s)
+		}
+	}
+	return s
 }
 
 func NewFPDF(fonts *font.Registry, doc *xdoc.Document) (*FPDF, error) {
 	e := &FPDF{
+		fonts:    fonts,
+		families: map[string]bool{},
+		added:    map[font.Descriptor]addedFont{},
 		pdf: gofpdf.New(
 			fpdfOrientation(doc.Page.Orientation),
 			"mm",
@@ -80,7 +140,7 @@ func NewFPDF(fonts *font.Registry, doc *xdoc.Document) (*FPDF, error) {
 
 	//TODO: make code-page for unicode translator an option (per font?)
 	//e.translateUnicode = e.pdf.UnicodeTranslatorFromDescriptor("")
-	e.translateUnicode = func(s string) string { return s }
+	e.translateUnicode = basicPlane
 	return e, nil
 }
 
@@ -91,29 +151,46 @@ func (e *FPDF) initFonts(fonts *font.Registry) error {
 		e.monoFont = "Courier"
 	}
 	return fonts.Each(func(fd font.Descriptor) error {
-		bs, err := ioutil.ReadFile(fd.FilePath)
-		if err != nil {
-			return errors.Wrapf(err, "read file %q", fd.FilePath)
-		}
-		var sty string
-		switch fd.Style {
-		case font.Bold:
-			sty = "B"
-		case font.Italic:
-			sty = "I"
-		case font.BoldItalic:
-			sty = "BI"
-		default:
-			sty = ""
-		}
-		e.pdf.AddUTF8FontFromBytes(fd.Name, sty, bs)
-		if e.pdf.Error() != nil {
-			return e.pdf.Error()
-		}
+		e.families[strings.ToLower(fd.Name)] = true
 		return nil
 	})
 }
 
+// match returns the registered font matching fnt of a single family best
+func (e *FPDF) match(fnt style.Font) (font.Descriptor, bool) {
+	return e.fonts.Match(fnt.Family, fnt.Weight.Value(), fnt.Style == style.FontStyleItalic)
+}
+
+// addFont adds the registered font fd to gofpdf, if it hasn't been used before. Fonts are added on demand,
+// so unused fonts of large registries aren't parsed and embedded.
+func (e *FPDF) addFont(fd font.Descriptor) (addedFont, error) {
+	if af, ok := e.added[fd]; ok {
+		return af, nil
+	}
+	bs, err := e.fonts.Load(fd)
+	if err != nil {
+		return addedFont{}, errors.Wrapf(err, "load font %q", fd.FilePath)
+	}
+	face, err := e.fonts.Face(fd)
+	if err != nil {
+		return addedFont{}, err
+	}
+	af := addedFont{key: fontKey(fd), face: face}
+	//fonts, whose names only differ in other characters than letters and digits, get distinct keys
+	for _, other := range e.added {
+		if other.key == af.key {
+			af.key += fmt.Sprintf("-%d", len(e.added))
+			break
+		}
+	}
+	e.pdf.AddUTF8FontFromBytes(af.key, "", bs)
+	if e.pdf.Error() != nil {
+		return addedFont{}, e.pdf.Error()
+	}
+	e.added[fd] = af
+	return af, nil
+}
+
 func (e *FPDF) Error() error {
 	return e.pdf.Error()
 }
@@ -160,8 +237,62 @@ func (e *FPDF) LineFeed(lines float64) {
 	e.pdf.Ln(heightMM * lines)
 }
 
+// ChangeFont sets the current font. Of a list of families, the first one defined is used. Of the registered fonts
+// of a family, the one matching weight and style best is used.
 func (e *FPDF) ChangeFont(fnt style.Font) {
-	e.pdf.SetFont(string(fnt.Family), fpdfFontStyle(fnt), float64(fnt.PointSize))
+	fnt.Family = e.family(fnt)
+	e.face = nil
+	if fd, ok := e.match(fnt); ok {
+		af, err := e.addFont(fd)
+		if err != nil {
+			e.pdf.SetError(err)
+			return
+		}
+		sty := ""
+		if fnt.Decoration == style.FontDecorationUnderline {
+			sty = "U"
+		}
+		e.pdf.SetFont(af.key, sty, float64(fnt.PointSize))
+		e.face = af.face
+		return
+	}
+	e.pdf.SetFont(fnt.Family, fpdfFontStyle(fnt), float64(fnt.PointSize))
+}
+
+// defined reports if the font family can be used
+func (e *FPDF) defined(family string) bool {
+	family = strings.ToLower(family)
+	return e.families[family] || coreFonts[family]
+}
+
+// family returns the first defined family of the list of fnt. If there is none, it is the first one.
+func (e *FPDF) family(fnt style.Font) string {
+	families := fnt.Families()
+	for _, family := range families {
+		if e.defined(family) {
+			return family
+		}
+	}
+	if len(families) > 0 {
+		return families[0]
+	}
+	return fnt.Family
+}
+
+// HasGlyph reports if the font fnt of a single family has a glyph for r. Core fonts are taken to cover Latin-1.
+// Characters beyond the basic multilingual plane like emoji can't be written, so no font has a glyph for them.
+func (e *FPDF) HasGlyph(fnt style.Font, r rune) bool {
+	if r > 0xffff {
+		return false
+	}
+	if fd, ok := e.match(fnt); ok {
+		face, err := e.fonts.Face(fd)
+		return err == nil && face.HasGlyph(r)
+	}
+	if coreFonts[strings.ToLower(fnt.Family)] {
+		return r < 0x100
+	}
+	return false
 }
 
 func (e *FPDF) PrintableArea() (x0, y0, x1, y1 float64) {
@@ -180,8 +311,24 @@ func (e *FPDF) PageWidth() float64 {
 	return pw
 }
 
-func (e *FPDF) PutImage(src string, x, y, width, height float64) {
-	e.pdf.ImageOptions(src, x, y, width, height, false, gofpdf.ImageOptions{}, 0, "")
+// PutImage puts the image data into the box. Images are registered by name, so their data is only normalized and
+// registered with gofpdf, when the name is placed first, and embedded once.
+func (e *FPDF) PutImage(name string, data []byte, format string, x, y, width, height float64) {
+	opts := gofpdf.ImageOptions{
+		ImageType: format,
+	}
+	if e.pdf.GetImageInfo(name) == nil {
+		if format == "png" && pngNeedsNormalize(data) {
+			normalized, err := normalizePNG(data)
+			if err != nil {
+				e.pdf.SetError(errors.Wrapf(err, "normalize png %q", name))
+				return
+			}
+			data = normalized
+		}
+		e.pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(data))
+	}
+	e.pdf.ImageOptions(name, x, y, width, height, false, opts, 0, "")
 }
 
 func (e *FPDF) SetTextColor(r, g, b int) {
@@ -205,9 +352,48 @@ func (e *FPDF) TextWidth(s string) float64 {
 	return e.pdf.GetStringWidth(e.translateUnicode(s))
 }
 
+// Kerning returns the kerning between a and b in the current font, which is added to the width of a.
+// Core fonts have no kerning.
+func (e *FPDF) Kerning(a, b rune) float64 {
+	if e.face == nil {
+		return 0
+	}
+	_, sizeMM := e.pdf.GetFontSize()
+	return e.face.Kerning(a, b) * sizeMM
+}
+
+// Ligatures returns s with the standard ligatures of the current font substituted
+func (e *FPDF) Ligatures(s string) string {
+	if e.face == nil {
+		return s
+	}
+	return e.face.Ligatures(s)
+}
+
+// WriteText writes s at the current position, which is moved behind it. Lines are laid out by the caller,
+// so s isn't broken at the right margin.
 func (e *FPDF) WriteText(s string) {
+	if s == "" {
+		return
+	}
 	_, heightMM := e.pdf.GetFontSize()
-	e.pdf.Write(heightMM, e.translateUnicode(s))
+	s = e.translateUnicode(s)
+	e.pdf.CellFormat(e.pdf.GetStringWidth(s), heightMM, s, "", 0, "", false, 0, "")
+}
+
+// SetCharSpacing sets the character spacing (Tc) of the content stream. The engine doesn't take it into account
+// for widths and positions, so the caller has to place text explicitly.
+func (e *FPDF) SetCharSpacing(spacing float64) {
+	if spacing == e.charSpacing {
+		return
+	}
+	e.charSpacing = spacing
+	e.pdf.RawWriteStr(fmt.Sprintf("%.5f Tc", spacing*e.pdf.GetConversionRatio()))
+}
+
+// TextAt writes s with its baseline starting at x,y
+func (e *FPDF) TextAt(x, y float64, s string) {
+	e.pdf.Text(x, y, e.translateUnicode(s))
 }
 
 //drawing
@@ -235,6 +421,56 @@ func (e *FPDF) LineTo(x, y float64) {
 	e.pdf.LineTo(x, y)
 }
 
+func (e *FPDF) CurveTo(cx0, cy0, cx1, cy1, x, y float64) {
+	e.pdf.CurveBezierCubicTo(cx0, cy0, cx1, cy1, x, y)
+}
+
+func (e *FPDF) ClosePath() {
+	e.pdf.ClosePath()
+}
+
 func (e *FPDF) DrawPath() {
 	e.pdf.DrawPath("D")
 }
+
+func (e *FPDF) FillPath(evenOdd bool) {
+	if evenOdd {
+		e.pdf.DrawPath("F*")
+		return
+	}
+	e.pdf.DrawPath("F")
+}
+
+// ClipRect restricts drawing to the rectangle until ClipEnd is called
+func (e *FPDF) ClipRect(x, y, width, height float64) {
+	e.pdf.ClipRect(x, y, width, height, false)
+}
+
+func (e *FPDF) ClipEnd() {
+	e.pdf.ClipEnd()
+}
+
+// TransformBegin starts a transformation context, which is closed by TransformEnd
+func (e *FPDF) TransformBegin() {
+	e.pdf.TransformBegin()
+}
+
+// Transform applies the matrix, which maps the point (x,y) to (a*x + c*y + e, b*x + d*y + f).
+// Coordinates are the ones of the page, with the origin at the top left and y growing downwards.
+func (e *FPDF) Transform(a, b, c, d, tx, ty float64) {
+	//convert to pdf space, where the origin is at the bottom left and the unit is pt
+	k := e.pdf.GetConversionRatio()
+	h := e.PageHeight()
+	e.pdf.Transform(gofpdf.TransformMatrix{
+		A: a,
+		B: -b,
+		C: -c,
+		D: d,
+		E: k * (c*h + tx),
+		F: k * (h - d*h - ty),
+	})
+}
+
+func (e *FPDF) TransformEnd() {
+	e.pdf.TransformEnd()
+}
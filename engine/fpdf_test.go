@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mazzegi/xpdf/font"
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+func TestBasicPlane(t *testing.T) {
+	e, err := NewFPDF(font.NewRegistry(), &xdoc.Document{})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "abc", exp: "abc"},
+		{in: "a₹b", exp: "a₹b"},
+		{in: "a\U0001f600b\U0001f600", exp: "a\ufffdb\ufffd"},
+		{in: "\U00020000", exp: "\ufffd"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			if have := basicPlane(test.in); have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+	if e.HasGlyph(style.Font{Family: "helvetica"}, '\U0001f600') {
+		t.Fatalf("have glyph beyond the basic plane")
+	}
+}
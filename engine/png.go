@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/pkg/errors"
+)
+
+// pngNeedsNormalize reports if the png can't be embedded as it is. The pdf backend supports neither 16-bit depth nor interlacing
+// and only knows fully transparent palette entries, so semi-transparent palettes would lose their alpha.
+func pngNeedsNormalize(data []byte) bool {
+	const signatureLen = 8
+	if len(data) < signatureLen+8+13 || string(data[signatureLen+4:signatureLen+8]) != "IHDR" {
+		return false
+	}
+	ihdr := data[signatureLen+8:]
+	bitDepth, colorType, interlace := ihdr[8], ihdr[9], ihdr[12]
+	if bitDepth > 8 || interlace != 0 {
+		return true
+	}
+	if colorType != 3 {
+		return false
+	}
+	pos := signatureLen
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		switch string(data[pos+4 : pos+8]) {
+		case "tRNS":
+			return true
+		case "IDAT", "IEND":
+			return false
+		}
+		pos += 8 + length + 4
+	}
+	return false
+}
+
+// normalizePNG re-encodes the png as 8-bit, non-interlaced RGB(A), which keeps a full alpha channel
+func normalizePNG(data []byte) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode png")
+	}
+	nrgba := image.NewNRGBA(img.Bounds())
+	draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	buf := &bytes.Buffer{}
+	err = png.Encode(buf, nrgba)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode png")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/mazzegi/xpdf/font"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, img)
+	if err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNormalizePNG(t *testing.T) {
+	rgba := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	rgba.Set(0, 0, color.NRGBA{R: 255, A: 128})
+
+	deep := image.NewNRGBA64(image.Rect(0, 0, 2, 2))
+	deep.Set(1, 1, color.NRGBA64{G: 0xFFFF, A: 0x8000})
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{
+		color.NRGBA{A: 255},
+		color.NRGBA{B: 255, A: 100},
+	})
+	paletted.SetColorIndex(1, 0, 1)
+
+	opaquePaletted := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{
+		color.NRGBA{A: 255},
+		color.NRGBA{B: 255, A: 255},
+	})
+
+	tests := []struct {
+		name      string
+		img       image.Image
+		normalize bool
+	}{
+		{name: "rgba", img: rgba, normalize: false},
+		{name: "16-bit", img: deep, normalize: true},
+		{name: "palette with alpha", img: paletted, normalize: true},
+		{name: "opaque palette", img: opaquePaletted, normalize: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := encodePNG(t, test.img)
+			if have := pngNeedsNormalize(data); have != test.normalize {
+				t.Fatalf("needs normalize: have %t, want %t", have, test.normalize)
+			}
+			if !test.normalize {
+				return
+			}
+			normalized, err := normalizePNG(data)
+			if err != nil {
+				t.Fatalf("normalize failed: %v", err)
+			}
+			if pngNeedsNormalize(normalized) {
+				t.Fatalf("normalized png still needs normalizing")
+			}
+			img, err := png.Decode(bytes.NewReader(normalized))
+			if err != nil {
+				t.Fatalf("decode normalized png: %v", err)
+			}
+			b := test.img.Bounds()
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					want := color.NRGBAModel.Convert(test.img.At(x, y)).(color.NRGBA)
+					have := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+					if have != want {
+						t.Fatalf("pixel %d,%d: have %v, want %v", x, y, have, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPutImageOnce(t *testing.T) {
+	e, err := NewFPDF(font.NewRegistry(), &xdoc.Document{})
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	e.AddPage()
+	data := encodePNG(t, image.NewNRGBA64(image.Rect(0, 0, 2, 2)))
+	e.PutImage("deep", data, "png", 10, 10, 20, 20)
+	//placed again, the data isn't normalized anymore, which would fail for the truncated one
+	e.PutImage("deep", data[:33], "png", 10, 40, 20, 20)
+	if err := e.Error(); err != nil {
+		t.Fatalf("put image: %v", err)
+	}
+}
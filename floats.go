@@ -0,0 +1,160 @@
+package xpdf
+
+import (
+	"math"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+// floatArea is the area occupied by a floating image or box, including its margins.
+type floatArea struct {
+	PrintableArea
+	side style.Float
+}
+
+// addFloat registers a floating area. Text lines of the flow are shortened around it.
+func (p *Processor) addFloat(pa PrintableArea, side style.Float) {
+	p.floats = append(p.floats, floatArea{
+		PrintableArea: pa,
+		side:          side,
+	})
+}
+
+// floatInsets returns the space occupied by floats on the left and right side of the horizontal band [x0,x1] between y0 and y1.
+func (p *Processor) floatInsets(x0, x1, y0, y1 float64) (left, right float64) {
+	for _, f := range p.floats {
+		if f.y1 <= y0 || f.y0 >= y1 {
+			continue
+		}
+		switch f.side {
+		case style.FloatLeft:
+			if inset := f.x1 - x0; inset > left {
+				left = inset
+			}
+		case style.FloatRight:
+			if inset := x1 - f.x0; inset > right {
+				right = inset
+			}
+		}
+	}
+	return
+}
+
+// floatsBottom returns the first bottom of the floats in the band between y0 and y1. It is false, if there are none.
+func (p *Processor) floatsBottom(y0, y1 float64) (float64, bool) {
+	bottom, found := 0.0, false
+	for _, f := range p.floats {
+		if f.y1 <= y0 || f.y0 >= y1 {
+			continue
+		}
+		if !found || f.y1 < bottom {
+			bottom, found = f.y1, true
+		}
+	}
+	return bottom, found
+}
+
+// floatPosition returns the x position of a floating element with the given width, placed at y into pa.
+func (p *Processor) floatPosition(pa PrintableArea, side style.Float, y, width, height float64) float64 {
+	left, right := p.floatInsets(pa.x0, pa.x1, y, y+height)
+	if side == style.FloatRight {
+		return pa.x1 - right - width
+	}
+	return pa.x0 + left
+}
+
+// floatSpan returns a lineSpan for text starting at (x,y) with the given width, where lines are shortened around floats.
+func (p *Processor) floatSpan(x, y, width, lineHeight float64) lineSpan {
+	if len(p.floats) == 0 {
+		return fixedSpan(width)
+	}
+	return &flowSpan{
+		p:          p,
+		x:          x,
+		top:        y,
+		width:      width,
+		lineHeight: lineHeight,
+		fontHeight: p.engine.FontHeight(),
+		lines:      map[int]floatInset{},
+	}
+}
+
+// floatInset is the indent and the available width of a line beside floats and its clearance below them
+type floatInset struct {
+	indent, width, clearance float64
+}
+
+// flowSpan is the lineSpan of text flowing around floats. Lines are positioned from the top of the text on the current page.
+// After a page break, the following lines are shortened around the floats of the new page, while the lines of the previous
+// pages keep their spans.
+type flowSpan struct {
+	p          *Processor
+	x          float64
+	width      float64
+	lineHeight float64
+	fontHeight float64
+	// first is the index of the first line on the current page, which starts at top
+	first int
+	top   float64
+	lines map[int]floatInset
+}
+
+// line returns the span of the line following prev. A line, which has no space beside the floats, is moved below them.
+func (s *flowSpan) line(prev []textLine) (float64, float64, float64) {
+	idx := len(prev)
+	if in, ok := s.lines[idx]; ok {
+		return in.indent, in.width, in.clearance
+	}
+	top := s.top
+	for i := s.first; i < idx; i++ {
+		top += prev[i].spaceAbove() + s.lineHeight + prev[i].below
+	}
+	var in floatInset
+	for {
+		left, right := s.p.floatInsets(s.x, s.x+s.width, top+in.clearance, top+in.clearance+s.fontHeight)
+		in.indent, in.width = math.Max(left, 0), s.width-math.Max(left, 0)-math.Max(right, 0)
+		if in.width > 0 {
+			break
+		}
+		bottom, ok := s.p.floatsBottom(top+in.clearance, top+in.clearance+s.fontHeight)
+		if !ok {
+			break
+		}
+		in.clearance = bottom - top
+	}
+	s.lines[idx] = in
+	return in.indent, in.width, in.clearance
+}
+
+func (s *flowSpan) pageBreak(idx int, x, y float64) bool {
+	s.first, s.x, s.top = idx, x, y
+	for i := range s.lines {
+		if i >= idx {
+			delete(s.lines, i)
+		}
+	}
+	return true
+}
+
+// clearFloats moves the current position below the floats of the given side and removes them.
+func (p *Processor) clearFloats(clear style.Clear) {
+	if len(p.floats) == 0 {
+		return
+	}
+	_, y := p.engine.GetXY()
+	remaining := []floatArea{}
+	for _, f := range p.floats {
+		cleared := clear == style.ClearBoth ||
+			(clear == style.ClearLeft && f.side == style.FloatLeft) ||
+			(clear == style.ClearRight && f.side == style.FloatRight)
+		if !cleared {
+			remaining = append(remaining, f)
+			continue
+		}
+		if f.y1 > y {
+			y = f.y1
+		}
+	}
+	p.floats = remaining
+	p.engine.SetY(y)
+}
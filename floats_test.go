@@ -0,0 +1,229 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+// testFloats are a float on the left from 20 to 60 and one on the right from 150 to 190, both from y 100 to 140
+var testFloats = []floatArea{
+	{PrintableArea: PrintableArea{x0: 20, y0: 100, x1: 60, y1: 140}, side: style.FloatLeft},
+	{PrintableArea: PrintableArea{x0: 150, y0: 100, x1: 190, y1: 140}, side: style.FloatRight},
+}
+
+func TestFloatInsets(t *testing.T) {
+	tests := []struct {
+		floats      []floatArea
+		y0, y1      float64
+		x0, x1      float64
+		left, right float64
+	}{
+		{floats: nil, x0: 20, x1: 190, y0: 100, y1: 110},
+		{floats: testFloats, x0: 20, x1: 190, y0: 100, y1: 110, left: 40, right: 40},
+		{floats: testFloats, x0: 30, x1: 170, y0: 95, y1: 101, left: 30, right: 20},
+		//bands touching a float aren't affected
+		{floats: testFloats, x0: 20, x1: 190, y0: 140, y1: 150},
+		{floats: testFloats, x0: 20, x1: 190, y0: 90, y1: 100},
+		{floats: testFloats[:1], x0: 20, x1: 190, y0: 120, y1: 130, left: 40},
+		{floats: testFloats[1:], x0: 20, x1: 190, y0: 120, y1: 130, right: 40},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			p := &Processor{floats: test.floats}
+			left, right := p.floatInsets(test.x0, test.x1, test.y0, test.y1)
+			if left != test.left || right != test.right {
+				t.Fatalf("have %.1f, %.1f, want %.1f, %.1f", left, right, test.left, test.right)
+			}
+		})
+	}
+}
+
+func TestFloatPosition(t *testing.T) {
+	pa := PrintableArea{x0: 20, y0: 20, x1: 190, y1: 277}
+	tests := []struct {
+		floats        []floatArea
+		side          style.Float
+		y             float64
+		width, height float64
+		exp           float64
+	}{
+		{floats: nil, side: style.FloatLeft, y: 100, width: 30, height: 10, exp: 20},
+		{floats: nil, side: style.FloatRight, y: 100, width: 30, height: 10, exp: 160},
+		//beside the floats
+		{floats: testFloats, side: style.FloatLeft, y: 100, width: 30, height: 10, exp: 60},
+		{floats: testFloats, side: style.FloatRight, y: 100, width: 30, height: 10, exp: 120},
+		//overlapping the floats only at their bottom
+		{floats: testFloats, side: style.FloatLeft, y: 135, width: 30, height: 10, exp: 60},
+		{floats: testFloats, side: style.FloatRight, y: 140, width: 30, height: 10, exp: 160},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			p := &Processor{floats: test.floats}
+			have := p.floatPosition(pa, test.side, test.y, test.width, test.height)
+			if have != test.exp {
+				t.Fatalf("have %.1f, want %.1f", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestClearFloats(t *testing.T) {
+	lower := floatArea{PrintableArea: PrintableArea{x0: 150, y0: 100, x1: 190, y1: 160}, side: style.FloatRight}
+	tests := []struct {
+		floats    []floatArea
+		clear     style.Clear
+		y         float64
+		expY      float64
+		remaining int
+	}{
+		{floats: testFloats, clear: style.ClearNone, y: 110, expY: 110, remaining: 2},
+		{floats: testFloats, clear: style.ClearBoth, y: 110, expY: 140, remaining: 0},
+		{floats: testFloats, clear: style.ClearLeft, y: 110, expY: 140, remaining: 1},
+		{floats: []floatArea{testFloats[0], lower}, clear: style.ClearRight, y: 110, expY: 160, remaining: 1},
+		{floats: []floatArea{testFloats[0], lower}, clear: style.ClearBoth, y: 110, expY: 160, remaining: 0},
+		//the position isn't moved up to a float ending above it
+		{floats: testFloats, clear: style.ClearBoth, y: 150, expY: 150, remaining: 0},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			p := testProcessor(t)
+			p.floats = test.floats
+			p.engine.SetY(test.y)
+			p.clearFloats(test.clear)
+			_, y := p.engine.GetXY()
+			if math.Abs(y-test.expY) > 1e-9 || len(p.floats) != test.remaining {
+				t.Fatalf("have y %.1f and %d floats, want y %.1f and %d floats", y, len(p.floats), test.expY, test.remaining)
+			}
+		})
+	}
+}
+
+func TestFlowSpanPageBreak(t *testing.T) {
+	p := testProcessor(t)
+	p.floats = testFloats[:1]
+	span := p.floatSpan(20, 100, 170, 6)
+	if indent, width, _ := span.line(nil); indent != 40 || width != 130 {
+		t.Fatalf("have line 0 at %.1f with %.1f, want at 40 with 130", indent, width)
+	}
+	//the new page has no floats
+	p.floats = nil
+	if !span.pageBreak(1, 20, 20) {
+		t.Fatalf("expected lines to change after page break")
+	}
+	if indent, width, _ := span.line(nil); indent != 40 || width != 130 {
+		t.Fatalf("have line 0 at %.1f with %.1f after page break, want at 40 with 130", indent, width)
+	}
+	if indent, width, _ := span.line(make([]textLine, 1)); indent != 0 || width != 170 {
+		t.Fatalf("have line 1 at %.1f with %.1f, want at 0 with 170", indent, width)
+	}
+	if fixedSpan(170).pageBreak(1, 20, 20) {
+		t.Fatalf("expected fixed lines not to change after page break")
+	}
+}
+
+func TestFlowSpanLines(t *testing.T) {
+	wide := []floatArea{{PrintableArea: PrintableArea{x0: 20, y0: 100, x1: 190, y1: 130}, side: style.FloatLeft}}
+	tests := []struct {
+		floats []floatArea
+		prev   []textLine
+		// indent, width and clearance of the next line
+		indent, width, clearance float64
+	}{
+		{floats: testFloats[:1], prev: nil, indent: 0, width: 170},
+		//lines of 6 from 88, the second one ends above the float at 100, the third one starts at it
+		{floats: testFloats[:1], prev: make([]textLine, 1), indent: 0, width: 170},
+		{floats: testFloats[:1], prev: make([]textLine, 2), indent: 40, width: 130},
+		//an image exceeding the first line moves the second one down to the float
+		{floats: testFloats[:1], prev: []textLine{{above: 4}}, indent: 40, width: 130},
+		{floats: testFloats[:1], prev: []textLine{{below: 4}}, indent: 40, width: 130},
+		//a float as wide as the text leaves no space, so the line is moved below it
+		{floats: wide, prev: make([]textLine, 2), indent: 0, width: 170, clearance: 30},
+		{floats: wide, prev: []textLine{{}, {above: 3}}, indent: 0, width: 170, clearance: 27},
+		{floats: append(wide, testFloats[1]), prev: make([]textLine, 2), indent: 0, width: 130, clearance: 30},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			p := testProcessor(t)
+			p.floats = test.floats
+			span := p.floatSpan(20, 88, 170, 6)
+			indent, width, clearance := span.line(test.prev)
+			if indent != test.indent || width != test.width || math.Abs(clearance-test.clearance) > 1e-9 {
+				t.Fatalf("have line at %.1f with %.1f and clearance %.1f, want at %.1f with %.1f and clearance %.1f",
+					indent, width, clearance, test.indent, test.width, test.clearance)
+			}
+		})
+	}
+}
+
+func TestFloatAcrossPageBreak(t *testing.T) {
+	//a float on the right, whose margin reaches beyond the bottom of the first page, beside a paragraph continuing on the next page
+	body := `<sety y="230"/><box style="float: right; width: 60; height: 40; margin: 0,0,0,40"></box>` +
+		`<text style="orphans: 1; widows: 1">` + repeated("word", 120) + `</text>`
+	e := processTestDoc(t, "", body)
+	//rightmost word positions by page and line
+	type pageLine struct {
+		page int
+		y    float64
+	}
+	maxX := map[pageLine]float64{}
+	firstY := map[int]float64{}
+	for _, rt := range e.texts {
+		if rt.text != "word" && rt.text != " word" {
+			continue
+		}
+		pl := pageLine{page: rt.page, y: rt.y}
+		if rt.x > maxX[pl] {
+			maxX[pl] = rt.x
+		}
+		if y, ok := firstY[rt.page]; !ok || rt.y < y {
+			firstY[rt.page] = rt.y
+		}
+	}
+	//the float starts at 190-60
+	for pl, x := range maxX {
+		if pl.page == 1 && x > 130 {
+			t.Fatalf("have words up to %.1f on page 1, want them left of the float at 130", x)
+		}
+	}
+	if x := maxX[pageLine{page: 2, y: firstY[2]}]; x < 150 {
+		t.Fatalf("have words up to %.1f in the first line of page 2, want them to use the full width", x)
+	}
+}
+
+func TestFloatingImagePageBreak(t *testing.T) {
+	tests := []struct {
+		y    float64
+		page int
+	}{
+		{y: 150, page: 1},
+		//the float reaching beyond the bottom margin at 277 starts a new page
+		{y: 231.7, page: 2},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			body := fmt.Sprintf(`<sety y="%g"/><image style="float: right; width: 50; height: 80">%s</image><text>beside</text>`, test.y, testImageURI(t))
+			e := processTestDoc(t, "", body)
+			if len(e.images) != 1 {
+				t.Fatalf("have %d images, want 1", len(e.images))
+			}
+			have := e.images[0]
+			if have.page != test.page || have.y+have.height > 277 {
+				t.Fatalf("have image on page %d from %.1f to %.1f, want it on page %d above 277", have.page, have.y, have.y+have.height, test.page)
+			}
+			if beside := e.find(t, "beside"); beside.page != test.page || beside.x+20 > have.x {
+				t.Fatalf("have text on page %d at %.1f, want it left of the image on page %d", beside.page, beside.x, test.page)
+			}
+		})
+	}
+}
+
+func TestTextBelowWideFloat(t *testing.T) {
+	//the float fills the printable width from 20 to 190, so the text continues below it
+	e := processTestDoc(t, "", `<sety y="100"/><box style="float: left; width: 170; height: 30"></box><text>below the float</text>`)
+	if have := e.find(t, "below"); have.y < 130 || have.x != 20 {
+		t.Fatalf("have text at %.1f,%.1f, want it at 20 below 130", have.x, have.y)
+	}
+}
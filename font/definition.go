@@ -1,14 +1,18 @@
 package font
 
 import (
+	"path"
 	"path/filepath"
 
 	"github.com/BurntSushi/toml"
+	"github.com/mazzegi/xpdf/resource"
 	"github.com/pkg/errors"
 )
 
 type StyleDefinition struct {
-	Style    Style
+	Style Style
+	// Weight is optional for weights other than the ones of regular and bold
+	Weight   int
 	FilePath string
 }
 
@@ -42,6 +46,34 @@ func LoadRegistryFromFile(file string) (*Registry, error) {
 		return filepath.Join(absDir, file)
 	}
 
+	return newRegistry(defs, resolve), nil
+}
+
+// LoadRegistry loads the definition file name with the resolver r, which is also used to load the font files.
+// Font files are resolved relative to the definition file.
+func LoadRegistry(name string, r resource.Resolver) (*Registry, error) {
+	bs, err := r.Resolve(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolve %q", name)
+	}
+	var defs Definitions
+	_, err = toml.Decode(string(bs), &defs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "toml-decode %q", name)
+	}
+	dir := path.Dir(filepath.ToSlash(name))
+	resolve := func(file string) string {
+		if filepath.IsAbs(file) || path.IsAbs(file) {
+			return file
+		}
+		return path.Join(dir, filepath.ToSlash(file))
+	}
+	reg := newRegistry(defs, resolve)
+	reg.SetResolver(r)
+	return reg, nil
+}
+
+func newRegistry(defs Definitions, resolve func(file string) string) *Registry {
 	reg := NewRegistry()
 	reg.monoFont = defs.MonoFont
 	for _, fnt := range defs.Fonts {
@@ -49,9 +81,10 @@ func LoadRegistryFromFile(file string) (*Registry, error) {
 			reg.Register(Descriptor{
 				Name:     fnt.Name,
 				Style:    sty.Style,
+				Weight:   sty.Weight,
 				FilePath: resolve(sty.FilePath),
 			})
 		}
 	}
-	return reg, nil
+	return reg
 }
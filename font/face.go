@@ -0,0 +1,274 @@
+package font
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// lookup types of GPOS and GSUB tables
+const (
+	gposPairAdjustment = 2
+	gposExtension      = 9
+	gsubLigature       = 4
+	gsubExtension      = 7
+)
+
+type glyphPair struct {
+	left, right uint16
+}
+
+// Face holds the data of a font file, which the layout of text needs beyond the widths of characters:
+// kerning pairs of the GPOS or kern table and the standard ligatures of the GSUB table.
+type Face struct {
+	unitsPerEm int
+	glyphs     map[rune]uint16
+	// kernLookups are the pair adjustment subtables of the GPOS kern feature
+	kernLookups [][]table
+	// kernPairs are the pairs of the kern table, which is only used without GPOS kerning
+	kernPairs map[glyphPair]int16
+	// ligatureLookups are the ligature substitution subtables of the GSUB liga feature
+	ligatureLookups [][]table
+	// ligatureRunes are the characters of ligature glyphs, which are mapped by the cmap
+	ligatureRunes map[uint16]rune
+}
+
+// ParseFace parses the TrueType or OpenType font data
+func ParseFace(data []byte) (*Face, error) {
+	return parseFace(data, 0)
+}
+
+// parseFace parses the font starting at offset in data
+func parseFace(data []byte, offset int) (*Face, error) {
+	tables, err := tableDirectory(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	f := &Face{
+		unitsPerEm: int(tables["head"].u16(18)),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, errors.New("missing units per em")
+	}
+	f.glyphs, err = parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, errors.Wrap(err, "parse cmap")
+	}
+	if gpos, ok := tables["GPOS"]; ok {
+		f.kernLookups = featureLookups(gpos, "kern", gposPairAdjustment, gposExtension)
+	}
+	if len(f.kernLookups) == 0 {
+		f.kernPairs = parseKern(tables["kern"])
+	}
+	if gsub, ok := tables["GSUB"]; ok {
+		f.ligatureLookups = featureLookups(gsub, "liga", gsubLigature, gsubExtension)
+		f.ligatureRunes = f.ligatureCharacters()
+	}
+	return f, nil
+}
+
+// HasGlyph reports if the face has a glyph for r
+func (f *Face) HasGlyph(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// parseKern returns the horizontal kerning pairs of the format 0 subtables of the kern table t
+func parseKern(t table) map[glyphPair]int16 {
+	pairs := map[glyphPair]int16{}
+	if t.u16(0) != 0 {
+		//the apple version isn't supported
+		return pairs
+	}
+	off := 4
+	for i := 0; i < int(t.u16(2)); i++ {
+		sub := t.sub(off)
+		length, coverage := int(sub.u16(2)), sub.u16(4)
+		//horizontal format 0 kerning values, which are neither minimum nor cross-stream ones
+		if coverage&0x7 == 0x1 && coverage>>8 == 0 {
+			for j := 0; j < int(sub.u16(6)); j++ {
+				rec := 14 + 6*j
+				pairs[glyphPair{sub.u16(rec), sub.u16(rec + 2)}] = sub.i16(rec + 4)
+			}
+		}
+		if length == 0 {
+			break
+		}
+		off += length
+	}
+	return pairs
+}
+
+// Kerning returns the kerning between a and b relative to the font size, which is added to the advance of a.
+// It is negative for pairs like "AV", which are moved closer together.
+func (f *Face) Kerning(a, b rune) float64 {
+	ga, okA := f.glyphs[a]
+	gb, okB := f.glyphs[b]
+	if !okA || !okB {
+		return 0
+	}
+	if len(f.kernLookups) == 0 {
+		return float64(f.kernPairs[glyphPair{ga, gb}]) / float64(f.unitsPerEm)
+	}
+	var kern int
+	for _, lookup := range f.kernLookups {
+		for _, sub := range lookup {
+			if v, ok := pairAdjustment(sub, ga, gb); ok {
+				kern += int(v)
+				break
+			}
+		}
+	}
+	return float64(kern) / float64(f.unitsPerEm)
+}
+
+// valueRecordSize returns the size of a value record with the format vf
+func valueRecordSize(vf uint16) int {
+	return 2 * bits.OnesCount16(vf&0xff)
+}
+
+// xAdvance returns the horizontal advance of the value record at off with the format vf
+func xAdvance(t table, off int, vf uint16) int16 {
+	if vf&0x4 == 0 {
+		return 0
+	}
+	return t.i16(off + 2*bits.OnesCount16(vf&0x3))
+}
+
+// pairAdjustment returns the advance adjustment of the first glyph of the pair a, b by the pair adjustment
+// subtable t. It reports false, if the subtable doesn't apply to the pair.
+func pairAdjustment(t table, a, b uint16) (int16, bool) {
+	idx, ok := coverageIndex(t.sub(int(t.u16(2))), a)
+	if !ok {
+		return 0, false
+	}
+	vf1, vf2 := t.u16(4), t.u16(6)
+	recSize := 2 + valueRecordSize(vf1) + valueRecordSize(vf2)
+	switch t.u16(0) {
+	case 1:
+		if idx >= int(t.u16(8)) {
+			return 0, false
+		}
+		set := t.sub(int(t.u16(10 + 2*idx)))
+		n := int(set.u16(0))
+		i := sort.Search(n, func(i int) bool { return set.u16(2+i*recSize) >= b })
+		if i < n && set.u16(2+i*recSize) == b {
+			return xAdvance(set, 2+i*recSize+2, vf1), true
+		}
+	case 2:
+		class1 := glyphClass(t.sub(int(t.u16(8))), a)
+		class2 := glyphClass(t.sub(int(t.u16(10))), b)
+		class1Count, class2Count := int(t.u16(12)), int(t.u16(14))
+		if class1 >= class1Count || class2 >= class2Count {
+			return 0, false
+		}
+		recSize -= 2
+		return xAdvance(t, 16+(class1*class2Count+class2)*recSize, vf1), true
+	}
+	return 0, false
+}
+
+// ligatureCharacters returns the characters of the ligature glyphs of the liga lookups
+func (f *Face) ligatureCharacters() map[uint16]rune {
+	ligGlyphs := map[uint16]bool{}
+	for _, lookup := range f.ligatureLookups {
+		for _, sub := range lookup {
+			for i := 0; i < int(sub.u16(4)); i++ {
+				set := sub.sub(int(sub.u16(6 + 2*i)))
+				for j := 0; j < int(set.u16(0)); j++ {
+					lig := set.sub(int(set.u16(2 + 2*j)))
+					ligGlyphs[lig.u16(0)] = true
+				}
+			}
+		}
+	}
+	runes := map[uint16]rune{}
+	for r, g := range f.glyphs {
+		if !ligGlyphs[g] {
+			continue
+		}
+		if prev, ok := runes[g]; !ok || r < prev {
+			runes[g] = r
+		}
+	}
+	return runes
+}
+
+// ligature returns the ligature glyph of the ligature substitution subtable t at the start of gs
+// and the number of glyphs it replaces
+func ligature(t table, gs []uint16) (uint16, int, bool) {
+	idx, ok := coverageIndex(t.sub(int(t.u16(2))), gs[0])
+	if !ok || idx >= int(t.u16(4)) {
+		return 0, 0, false
+	}
+	set := t.sub(int(t.u16(6 + 2*idx)))
+	for i := 0; i < int(set.u16(0)); i++ {
+		lig := set.sub(int(set.u16(2 + 2*i)))
+		n := int(lig.u16(2))
+		if n == 0 || n > len(gs) {
+			continue
+		}
+		match := true
+		for k := 1; k < n; k++ {
+			if lig.u16(4+2*(k-1)) != gs[k] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return lig.u16(0), n, true
+		}
+	}
+	return 0, 0, false
+}
+
+// Ligatures returns s with the standard ligatures of the face substituted. As text is written as characters,
+// only ligatures with a code point of their own like U+FB01 for fi are used.
+func (f *Face) Ligatures(s string) string {
+	if len(f.ligatureRunes) == 0 {
+		return s
+	}
+	rs := []rune(s)
+	gs := make([]uint16, len(rs))
+	for i, r := range rs {
+		gs[i] = f.glyphs[r]
+	}
+	changed := false
+	for _, lookup := range f.ligatureLookups {
+		var outRunes []rune
+		var outGlyphs []uint16
+		for i := 0; i < len(gs); {
+			if gs[i] != 0 {
+				if g, n, ok := f.substitute(lookup, gs[i:]); ok {
+					outRunes = append(outRunes, f.ligatureRunes[g])
+					outGlyphs = append(outGlyphs, g)
+					i += n
+					changed = true
+					continue
+				}
+			}
+			outRunes = append(outRunes, rs[i])
+			outGlyphs = append(outGlyphs, gs[i])
+			i++
+		}
+		rs, gs = outRunes, outGlyphs
+	}
+	if !changed {
+		return s
+	}
+	return string(rs)
+}
+
+// substitute returns the first ligature of the subtables of lookup at the start of gs, which has a character
+func (f *Face) substitute(lookup []table, gs []uint16) (uint16, int, bool) {
+	for _, sub := range lookup {
+		if g, n, ok := ligature(sub, gs); ok {
+			if _, has := f.ligatureRunes[g]; has {
+				return g, n, true
+			}
+			return 0, 0, false
+		}
+	}
+	return 0, 0, false
+}
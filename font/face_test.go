@@ -0,0 +1,219 @@
+package font
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+// be encodes values as big-endian 16-bit integers, tags as 4 bytes and byte slices as they are
+func be(vs ...any) []byte {
+	var bs []byte
+	for _, v := range vs {
+		switch v := v.(type) {
+		case int:
+			bs = binary.BigEndian.AppendUint16(bs, uint16(v))
+		case uint32:
+			bs = binary.BigEndian.AppendUint32(bs, v)
+		case string:
+			bs = append(bs, v...)
+		case []byte:
+			bs = append(bs, v...)
+		}
+	}
+	return bs
+}
+
+// sfntData returns a font file with the given tables
+func sfntData(tables map[string][]byte) []byte {
+	return sfntDataAt(tables, 0)
+}
+
+// sfntDataAt returns a font with the given tables, which starts at base in a collection
+func sfntDataAt(tables map[string][]byte, base int) []byte {
+	var tags []string
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	data := be(uint32(0x00010000), len(tags), 0, 0, 0)
+	off := base + 12 + 16*len(tags)
+	for _, tag := range tags {
+		data = append(data, be(tag, uint32(0), uint32(off), uint32(len(tables[tag])))...)
+		off += len(tables[tag])
+	}
+	for _, tag := range tags {
+		data = append(data, tables[tag]...)
+	}
+	return data
+}
+
+// testCmap returns a cmap table with a format 4 subtable mapping each character to its glyph
+func testCmap(glyphs map[rune]int) []byte {
+	var rs []int
+	for r := range glyphs {
+		rs = append(rs, int(r))
+	}
+	sort.Ints(rs)
+	rs = append(rs, 0xffff)
+	var ends, starts, deltas, offsets []any
+	for _, r := range rs {
+		ends = append(ends, r)
+		starts = append(starts, r)
+		deltas = append(deltas, (glyphs[rune(r)]-r+0x10000)%0x10000)
+		offsets = append(offsets, 0)
+	}
+	if rs[len(rs)-1] == 0xffff {
+		deltas[len(deltas)-1] = 1
+	}
+	sub := be(4, 0, 0, 2*len(rs), 0, 0, 0)
+	sub = append(sub, be(ends...)...)
+	sub = append(sub, be(0)...)
+	sub = append(sub, be(starts...)...)
+	sub = append(sub, be(deltas...)...)
+	sub = append(sub, be(offsets...)...)
+	return be(0, 1, 3, 1, uint32(12), sub)
+}
+
+// testLayoutTable returns a GPOS or GSUB table with a feature tag using all lookups
+func testLayoutTable(tag string, lookups ...[]byte) []byte {
+	var indices []any
+	for i := range lookups {
+		indices = append(indices, i)
+	}
+	feature := be(0, len(lookups), be(indices...))
+	features := be(1, tag, 8, feature)
+	lookupList := be(len(lookups))
+	off := 2 + 2*len(lookups)
+	for _, l := range lookups {
+		lookupList = append(lookupList, be(off)...)
+		off += len(l)
+	}
+	for _, l := range lookups {
+		lookupList = append(lookupList, l...)
+	}
+	scripts := be(0)
+	return be(uint32(0x00010000), 10, 10+len(scripts), 10+len(scripts)+len(features), scripts, features, lookupList)
+}
+
+// testLookup returns a lookup of type typ with one subtable
+func testLookup(typ int, sub []byte) []byte {
+	return be(typ, 0, 1, 8, sub)
+}
+
+const (
+	glyphA = iota + 1
+	glyphV
+	glyphT
+	glyphO
+	glyphF
+	glyphI
+	glyphFI
+	glyphFFI
+)
+
+// testFontData returns a font with glyphs for A, V, T, o, f, i and fi, kerning pairs and ligatures of f
+func testFontData(withGPOS bool) []byte {
+	glyphs := map[rune]int{'A': glyphA, 'V': glyphV, 'T': glyphT, 'o': glyphO, 'f': glyphF, 'i': glyphI, 'ﬁ': glyphFI}
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	kern := be(0, 1, 0, 14+6, 0x0001, 1, 0, 0, 0, glyphA, glyphV, -100)
+	tables := map[string][]byte{
+		"head": head,
+		"cmap": testCmap(glyphs),
+		"kern": kern,
+	}
+	if withGPOS {
+		//pairs of glyphs: T o
+		pairSet := be(1, glyphO, -80)
+		pairs := be(1, 12, 0x4, 0, 1, 18, be(1, 1, glyphT), pairSet)
+		//pairs of classes: A V
+		classes := be(2, 24, 0x4, 0, 30, 40, 2, 2, 0, 0, 0, -120)
+		classes = append(classes, be(1, 1, glyphA, 2, 1, glyphA, glyphA, 1, 1, glyphV, 1, 1)...)
+		tables["GPOS"] = testLayoutTable("kern", testLookup(2, pairs), testLookup(2, classes))
+	}
+	//ligatures of f: ffi without a character and fi
+	ffi := be(glyphFFI, 3, glyphF, glyphI)
+	fi := be(glyphFI, 2, glyphI)
+	set := be(2, 6, 6+len(ffi), ffi, fi)
+	ligatures := be(1, 8, 1, 14, be(1, 1, glyphF), set)
+	tables["GSUB"] = testLayoutTable("liga", testLookup(4, ligatures))
+
+	return sfntData(tables)
+}
+
+func testFace(t *testing.T, withGPOS bool) *Face {
+	face, err := ParseFace(testFontData(withGPOS))
+	if err != nil {
+		t.Fatalf("parse face: %v", err)
+	}
+	return face
+}
+
+func TestKerning(t *testing.T) {
+	tests := []struct {
+		gpos bool
+		a, b rune
+		exp  float64
+	}{
+		{gpos: true, a: 'T', b: 'o', exp: -0.08},
+		{gpos: true, a: 'A', b: 'V', exp: -0.12},
+		{gpos: true, a: 'V', b: 'A', exp: 0},
+		{gpos: true, a: 'o', b: 'T', exp: 0},
+		{gpos: true, a: 'T', b: 'x', exp: 0},
+		{gpos: false, a: 'A', b: 'V', exp: -0.1},
+		{gpos: false, a: 'T', b: 'o', exp: 0},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := testFace(t, test.gpos).Kerning(test.a, test.b)
+			if math.Abs(have-test.exp) > 1e-9 {
+				t.Fatalf("have %f, want %f", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestLigatures(t *testing.T) {
+	face := testFace(t, false)
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "", exp: ""},
+		{in: "fish", exp: "ﬁsh"},
+		{in: "ff", exp: "ff"},
+		//the ffi ligature has no character, so fi is used
+		{in: "offi", exp: "ofﬁ"},
+		{in: "f i", exp: "f i"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := face.Ligatures(test.in)
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestHasGlyph(t *testing.T) {
+	face := testFace(t, false)
+	if !face.HasGlyph('A') || !face.HasGlyph('ﬁ') {
+		t.Fatalf("expected glyphs of A and fi")
+	}
+	if face.HasGlyph('x') {
+		t.Fatalf("expected no glyph of x")
+	}
+}
+
+func TestParseFaceErrors(t *testing.T) {
+	if _, err := ParseFace([]byte("no font")); err == nil {
+		t.Fatalf("expected error for invalid data")
+	}
+	if _, err := ParseFace(sfntData(map[string][]byte{"head": make([]byte, 54)})); err == nil {
+		t.Fatalf("expected error without units per em")
+	}
+}
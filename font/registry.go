@@ -1,5 +1,13 @@
 package font
 
+import (
+	"os"
+	"strings"
+
+	"github.com/mazzegi/xpdf/resource"
+	"github.com/pkg/errors"
+)
+
 type Style string
 
 const (
@@ -9,25 +17,186 @@ const (
 	BoldItalic Style = "bold+italic"
 )
 
+// Italic reports if the style is italic or bold italic
+func (s Style) Italic() bool {
+	return s == Italic || s == BoldItalic
+}
+
+// Bold reports if the style is bold or bold italic
+func (s Style) Bold() bool {
+	return s == Bold || s == BoldItalic
+}
+
+// styleOf returns the style of a font with the weight from 100 to 900, which is bold from 600
+func styleOf(weight int, italic bool) Style {
+	switch bold := weight >= 600; {
+	case bold && italic:
+		return BoldItalic
+	case bold:
+		return Bold
+	case italic:
+		return Italic
+	default:
+		return Regular
+	}
+}
+
 type Descriptor struct {
-	Name     string
-	Style    Style
+	Name  string
+	Style Style
+	// Weight is the weight from 100 to 900. If it is 0, the registry sets it to the one of the style.
+	Weight   int
 	FilePath string
+	// Index is the index of the font in a collection file
+	Index int
+}
+
+// weight returns the weight of fonts of the style, which is 700 for bold ones and 400 for others
+func (s Style) weight() int {
+	if s.Bold() {
+		return 700
+	}
+	return 400
 }
 
 type Registry struct {
 	monoFont string
 	fonts    []Descriptor
+	resolver resource.Resolver
+	// faces are the parsed font files, which are loaded on demand, with the errors of the ones failing to load
+	faces map[Descriptor]loadedFace
+}
+
+type loadedFace struct {
+	face *Face
+	err  error
 }
 
 func NewRegistry() *Registry {
-	return &Registry{}
+	return &Registry{
+		faces: map[Descriptor]loadedFace{},
+	}
 }
 
 func (d *Registry) Register(fd Descriptor) {
+	if fd.Weight == 0 {
+		fd.Weight = fd.Style.weight()
+	}
 	d.fonts = append(d.fonts, fd)
 }
 
+// SetResolver sets the resolver, which loads the font files. Without a resolver, FilePath refers to the file system.
+func (d *Registry) SetResolver(r resource.Resolver) {
+	d.resolver = r
+}
+
+// Load returns the content of the font file of fd. Fonts of collections are returned as font files of their own.
+func (d *Registry) Load(fd Descriptor) ([]byte, error) {
+	var bs []byte
+	var err error
+	if d.resolver != nil {
+		bs, err = d.resolver.Resolve(fd.FilePath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		bs, err = os.ReadFile(fd.FilePath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read file %q", fd.FilePath)
+		}
+	}
+	bs, err = collectionFont(bs, fd.Index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "font %d of collection %q", fd.Index, fd.FilePath)
+	}
+	return bs, nil
+}
+
+// Face returns the parsed font file of fd, which is only loaded once. Fonts failing to load keep their error.
+func (d *Registry) Face(fd Descriptor) (*Face, error) {
+	lf, ok := d.faces[fd]
+	if !ok {
+		lf.face, lf.err = d.loadFace(fd)
+		d.faces[fd] = lf
+	}
+	return lf.face, lf.err
+}
+
+// loadFace loads and parses the font file of fd
+func (d *Registry) loadFace(fd Descriptor) (*Face, error) {
+	bs, err := d.Load(fd)
+	if err != nil {
+		return nil, err
+	}
+	face, err := ParseFace(bs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse font %q", fd.FilePath)
+	}
+	return face, nil
+}
+
+// weightRank returns the rank of the weight w for the desired one by the CSS font matching, which is lower for better matches.
+// For desired weights from 400 to 500, heavier weights up to 500 are tried first, then lighter ones and then heavier ones.
+// Lighter desired weights prefer lighter weights and heavier ones heavier weights.
+func weightRank(desired, w int) int {
+	const first, second, third = 1000, 2000, 3000
+	dist := w - desired
+	if dist < 0 {
+		dist = -dist
+	}
+	normal := desired >= 400 && desired <= 500
+	switch {
+	case w == desired:
+		return 0
+	case normal && w > desired && w <= 500:
+		return first + dist
+	case normal && w < desired:
+		return second + dist
+	case normal:
+		return third + dist
+	case desired < 400 && w < desired, desired > 500 && w > desired:
+		return first + dist
+	default:
+		return second + dist
+	}
+}
+
+// Match returns the font of family, which matches weight and italic best. Like in CSS, fonts of the requested style are
+// preferred and the weight is matched among them. Family names are case insensitive like in gofpdf.
+func (d *Registry) Match(family string, weight int, italic bool) (Descriptor, bool) {
+	var best Descriptor
+	found := false
+	better := func(fd Descriptor) bool {
+		if fd.Style.Italic() != best.Style.Italic() {
+			return fd.Style.Italic() == italic
+		}
+		return weightRank(weight, fd.Weight) < weightRank(weight, best.Weight)
+	}
+	for _, fd := range d.fonts {
+		if !strings.EqualFold(fd.Name, family) {
+			continue
+		}
+		if !found || better(fd) {
+			best, found = fd, true
+		}
+	}
+	return best, found
+}
+
+// HasGlyph reports if the font name with style sty has a glyph for r. It is false for fonts, which aren't registered
+// or can't be loaded.
+func (d *Registry) HasGlyph(name string, sty Style, r rune) bool {
+	fd, ok := d.Match(name, sty.weight(), sty.Italic())
+	if !ok {
+		return false
+	}
+	face, err := d.Face(fd)
+	if err != nil {
+		return false
+	}
+	return face.HasGlyph(r)
+}
+
 func (d *Registry) MonoFont() string {
 	return d.monoFont
 }
@@ -0,0 +1,72 @@
+package font
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/resource"
+	"github.com/pkg/errors"
+)
+
+func TestRegistryHasGlyph(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.ttf")
+	if err := os.WriteFile(file, testFontData(false), 0644); err != nil {
+		t.Fatalf("write font: %v", err)
+	}
+	reg := NewRegistry()
+	reg.Register(Descriptor{Name: "test", Style: Regular, FilePath: file})
+	reg.Register(Descriptor{Name: "missing", Style: Regular, FilePath: filepath.Join(t.TempDir(), "missing.ttf")})
+	tests := []struct {
+		name  string
+		style Style
+		r     rune
+		exp   bool
+	}{
+		{name: "test", style: Regular, r: 'A', exp: true},
+		{name: "Test", style: Regular, r: 'f', exp: true},
+		{name: "test", style: Regular, r: 'x', exp: false},
+		//the regular font is the nearest match
+		{name: "test", style: Bold, r: 'A', exp: true},
+		{name: "other", style: Regular, r: 'A', exp: false},
+		{name: "missing", style: Regular, r: 'A', exp: false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := reg.HasGlyph(test.name, test.style, test.r)
+			if have != test.exp {
+				t.Fatalf("have %t, want %t", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestRegistryFaceError(t *testing.T) {
+	loads := map[string]int{}
+	reg := NewRegistry()
+	reg.SetResolver(resource.ResolverFunc(func(name string) ([]byte, error) {
+		loads[name]++
+		if name == "broken.ttf" {
+			return []byte("no font"), nil
+		}
+		return nil, errors.Errorf("no file %q", name)
+	}))
+	reg.Register(Descriptor{Name: "broken", Style: Regular, FilePath: "broken.ttf"})
+	reg.Register(Descriptor{Name: "missing", Style: Regular, FilePath: "missing.ttf"})
+	for _, r := range "abc" {
+		if reg.HasGlyph("broken", Regular, r) || reg.HasGlyph("missing", Regular, r) {
+			t.Fatalf("have glyph for %q", r)
+		}
+	}
+	for _, file := range []string{"broken.ttf", "missing.ttf"} {
+		if loads[file] != 1 {
+			t.Fatalf("%q loaded %d times, want once", file, loads[file])
+		}
+		fd, _ := reg.Match(strings.TrimSuffix(file, ".ttf"), 400, false)
+		if _, err := reg.Face(fd); err == nil {
+			t.Fatalf("no error for %q", file)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package font
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// fontOffsets returns the offsets of the fonts in data, which are more than one in collections (.ttc)
+func fontOffsets(data []byte) ([]int, error) {
+	t := table(data)
+	if t.tag(0) != "ttcf" {
+		return []int{0}, nil
+	}
+	n := int(t.u32(8))
+	if len(t) < 12+4*n {
+		return nil, errors.New("truncated collection header")
+	}
+	offsets := make([]int, n)
+	for i := range offsets {
+		offsets[i] = int(t.u32(12 + 4*i))
+	}
+	return offsets, nil
+}
+
+// collectionFont returns the font with index idx of the collection data as a font file of its own.
+// Data of a single font is returned as it is.
+func collectionFont(data []byte, idx int) ([]byte, error) {
+	offsets, err := fontOffsets(data)
+	if err != nil {
+		return nil, err
+	}
+	if table(data).tag(0) != "ttcf" {
+		return data, nil
+	}
+	if idx < 0 || idx >= len(offsets) {
+		return nil, errors.Errorf("no font %d in collection of %d", idx, len(offsets))
+	}
+	tables, err := tableDirectory(data, offsets[idx])
+	if err != nil {
+		return nil, err
+	}
+	dir := table(data).sub(offsets[idx])
+	n := int(dir.u16(4))
+	out := append([]byte{}, dir[:12]...)
+	off := 12 + 16*n
+	var body []byte
+	for i := 0; i < n; i++ {
+		rec := append([]byte{}, dir[12+16*i:12+16*(i+1)]...)
+		t := tables[dir.tag(12+16*i)]
+		binary.BigEndian.PutUint32(rec[8:], uint32(off+len(body)))
+		out = append(out, rec...)
+		body = append(body, t...)
+		//tables are aligned to 4 bytes
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(out, body...), nil
+}
+
+// name table ids
+const (
+	nameFamily            = 1
+	nameTypographicFamily = 16
+)
+
+// fontName returns the name with the id of the name table t, which is preferably the english one of the windows platform
+func fontName(t table, id uint16) string {
+	n := int(t.u16(2))
+	storage := t.sub(int(t.u16(4)))
+	var name string
+	rank := 0
+	for i := 0; i < n; i++ {
+		rec := 6 + 12*i
+		if t.u16(rec+6) != id {
+			continue
+		}
+		platform, encoding, lang := t.u16(rec), t.u16(rec+2), t.u16(rec+4)
+		length, off := int(t.u16(rec+8)), int(t.u16(rec+10))
+		if off+length > len(storage) {
+			continue
+		}
+		raw := storage[off : off+length]
+		r := 0
+		switch {
+		case platform == 3 && (encoding == 1 || encoding == 10) && lang == 0x409:
+			r = 4
+		case platform == 3 && (encoding == 1 || encoding == 10), platform == 0:
+			r = 3
+		case platform == 1 && encoding == 0:
+			r = 2
+		}
+		if r <= rank {
+			continue
+		}
+		if platform == 1 {
+			//mac roman, where only the ascii characters are used
+			name = string(raw)
+		} else {
+			u := make([]uint16, len(raw)/2)
+			for j := range u {
+				u[j] = table(raw).u16(2 * j)
+			}
+			name = string(utf16.Decode(u))
+		}
+		rank = r
+	}
+	return strings.TrimSpace(name)
+}
+
+// faceInfo is what the registry needs to know about a font file to match requests
+type faceInfo struct {
+	family string
+	weight int
+	italic bool
+	mono   bool
+}
+
+// parseInfo returns the family, weight and style of the font at offset in data. The family is the typographic one of the
+// name table, which groups all weights, or else the legacy one. Weight and style are taken from the OS/2 table
+// or else from the head table.
+func parseInfo(data []byte, offset int) (faceInfo, error) {
+	tables, err := tableDirectory(data, offset)
+	if err != nil {
+		return faceInfo{}, err
+	}
+	if _, ok := tables["glyf"]; !ok {
+		return faceInfo{}, errors.New("no truetype outlines")
+	}
+	info := faceInfo{
+		family: fontName(tables["name"], nameTypographicFamily),
+		mono:   tables["post"].u32(12) != 0,
+	}
+	if info.family == "" {
+		info.family = fontName(tables["name"], nameFamily)
+	}
+	if info.family == "" {
+		return faceInfo{}, errors.New("no family name")
+	}
+	if os2, ok := tables["OS/2"]; ok {
+		info.weight = int(os2.u16(4))
+		//italic or oblique
+		info.italic = os2.u16(62)&0x201 != 0
+	} else {
+		macStyle := tables["head"].u16(44)
+		info.weight = 400
+		if macStyle&0x1 != 0 {
+			info.weight = 700
+		}
+		info.italic = macStyle&0x2 != 0
+	}
+	if info.weight > 0 && info.weight < 10 {
+		//some fonts use a scale from 1 to 9
+		info.weight *= 100
+	}
+	info.weight = clampWeight(info.weight)
+	return info, nil
+}
+
+// clampWeight returns the weight w in the range from 100 to 900
+func clampWeight(w int) int {
+	switch {
+	case w < 100:
+		return 100
+	case w > 900:
+		return 900
+	}
+	return w
+}
+
+// fontExtensions are the extensions of the font files, which are scanned
+var fontExtensions = map[string]bool{
+	".ttf": true,
+	".otf": true,
+	".ttc": true,
+}
+
+// Builder builds a registry of the fonts found in directories
+type Builder struct {
+	dirs     []string
+	monoFont string
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// AddDir adds a directory, which is scanned with its subdirectories
+func (b *Builder) AddDir(dir string) *Builder {
+	b.dirs = append(b.dirs, dir)
+	return b
+}
+
+// SetMonoFont sets the family of the monospaced font. Without one, it is the first monospaced family found.
+func (b *Builder) SetMonoFont(family string) *Builder {
+	b.monoFont = family
+	return b
+}
+
+// Build scans the directories for TrueType and OpenType fonts and collections. Family, weight and style of the fonts
+// are read from their name and OS/2 tables. Files, which can't be parsed, are skipped as well as fonts without
+// TrueType outlines (CFF), as they can't be embedded. Fonts are registered in the order of their paths.
+func (b *Builder) Build() (*Registry, error) {
+	var files []string
+	for _, dir := range b.dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && fontExtensions[strings.ToLower(filepath.Ext(path))] {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "scan dir %q", dir)
+		}
+	}
+	sort.Strings(files)
+
+	reg := NewRegistry()
+	reg.monoFont = b.monoFont
+	var monoFamilies []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read file %q", file)
+		}
+		offsets, err := fontOffsets(data)
+		if err != nil {
+			continue
+		}
+		for idx, offset := range offsets {
+			info, err := parseInfo(data, offset)
+			if err != nil {
+				continue
+			}
+			reg.Register(Descriptor{
+				Name:     info.family,
+				Style:    styleOf(info.weight, info.italic),
+				Weight:   info.weight,
+				FilePath: file,
+				Index:    idx,
+			})
+			if info.mono {
+				monoFamilies = append(monoFamilies, info.family)
+			}
+		}
+	}
+	if reg.monoFont == "" && len(monoFamilies) > 0 {
+		reg.monoFont = monoFamilies[0]
+	}
+	return reg, nil
+}
+
+// LoadRegistryFromDirs returns the registry of the fonts found in the directories
+func LoadRegistryFromDirs(dirs ...string) (*Registry, error) {
+	b := NewBuilder()
+	for _, dir := range dirs {
+		b.AddDir(dir)
+	}
+	return b.Build()
+}
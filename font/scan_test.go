@@ -0,0 +1,172 @@
+package font
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testNamedTables returns the tables of a font of family with the weight and style
+func testNamedTables(family string, weight int, italic, mono bool) map[string][]byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	var name []any
+	for _, u := range utf16.Encode([]rune(family)) {
+		name = append(name, int(u))
+	}
+	nameData := be(name...)
+	os2 := make([]byte, 78)
+	binary.BigEndian.PutUint16(os2[4:], uint16(weight))
+	if italic {
+		binary.BigEndian.PutUint16(os2[62:], 0x1)
+	}
+	post := make([]byte, 32)
+	if mono {
+		binary.BigEndian.PutUint32(post[12:], 1)
+	}
+	return map[string][]byte{
+		"head": head,
+		"cmap": testCmap(map[rune]int{'a': 1}),
+		"glyf": make([]byte, 4),
+		"name": be(0, 1, 6+12, 3, 1, 0x409, nameTypographicFamily, len(nameData), 0, nameData),
+		"OS/2": os2,
+		"post": post,
+	}
+}
+
+// collectionData returns a font collection of fonts with the given tables
+func collectionData(fonts ...map[string][]byte) []byte {
+	header := 12 + 4*len(fonts)
+	var offsets []any
+	var body []byte
+	for _, tables := range fonts {
+		offsets = append(offsets, uint32(header+len(body)))
+		body = append(body, sfntDataAt(tables, header+len(body))...)
+	}
+	data := be("ttcf", uint32(0x00010000), uint32(len(fonts)))
+	data = append(data, be(offsets...)...)
+	return append(data, body...)
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"a.ttf":       sfntData(testNamedTables("Test Sans", 400, false, false)),
+		"b.TTF":       sfntData(testNamedTables("Test Sans", 700, true, false)),
+		"sub/c.ttc":   collectionData(testNamedTables("Test Mono", 400, false, true), testNamedTables("Test Sans", 3, false, false)),
+		"junk.ttf":    []byte("no font"),
+		"readme.txt":  []byte("Test Serif"),
+		"outline.otf": sfntData(map[string][]byte{"head": make([]byte, 54)}),
+	}
+	for name, data := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	reg, err := LoadRegistryFromDirs(dir)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	var have []string
+	reg.Each(func(fd Descriptor) error {
+		have = append(have, fmt.Sprintf("%s/%s/%d/%s/%d", fd.Name, fd.Style, fd.Weight, filepath.Base(fd.FilePath), fd.Index))
+		return nil
+	})
+	exp := "Test Sans/regular/400/a.ttf/0 Test Sans/bold+italic/700/b.TTF/0 Test Mono/regular/400/c.ttc/0 Test Sans/regular/300/c.ttc/1"
+	if strings.Join(have, " ") != exp {
+		t.Fatalf("have %s, want %s", strings.Join(have, " "), exp)
+	}
+	if reg.MonoFont() != "Test Mono" {
+		t.Fatalf("have mono font %q, want Test Mono", reg.MonoFont())
+	}
+
+	//fonts of collections are loaded as fonts of their own
+	fd, ok := reg.Match("test sans", 300, false)
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	data, err := reg.Load(fd)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	info, err := parseInfo(data, 0)
+	if err != nil {
+		t.Fatalf("parse info: %v", err)
+	}
+	if info.family != "Test Sans" || info.weight != 300 {
+		t.Fatalf("have %s %d, want Test Sans 300", info.family, info.weight)
+	}
+	if !reg.HasGlyph("Test Sans", Regular, 'a') {
+		t.Fatalf("expected glyph of a")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	reg := NewRegistry()
+	for _, fd := range []Descriptor{
+		{Name: "sans", Style: Regular, Weight: 300, FilePath: "light"},
+		{Name: "sans", Style: Regular, FilePath: "regular"},
+		{Name: "sans", Style: Bold, FilePath: "bold"},
+		{Name: "sans", Style: Italic, FilePath: "italic"},
+		{Name: "serif", Style: Italic, FilePath: "serif-italic"},
+	} {
+		reg.Register(fd)
+	}
+	tests := []struct {
+		family string
+		weight int
+		italic bool
+		exp    string
+	}{
+		{family: "sans", weight: 400, exp: "regular"},
+		{family: "SANS", weight: 700, exp: "bold"},
+		{family: "sans", weight: 300, exp: "light"},
+		{family: "sans", weight: 500, exp: "regular"},
+		{family: "sans", weight: 450, exp: "regular"},
+		{family: "sans", weight: 600, exp: "bold"},
+		{family: "sans", weight: 900, exp: "bold"},
+		{family: "sans", weight: 350, exp: "light"},
+		{family: "sans", weight: 100, exp: "light"},
+		{family: "sans", weight: 400, italic: true, exp: "italic"},
+		{family: "sans", weight: 700, italic: true, exp: "italic"},
+		{family: "serif", weight: 400, exp: "serif-italic"},
+		{family: "mono", weight: 400, exp: ""},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			fd, _ := reg.Match(test.family, test.weight, test.italic)
+			if fd.FilePath != test.exp {
+				t.Fatalf("have %q, want %q", fd.FilePath, test.exp)
+			}
+		})
+	}
+}
+
+func TestWeightRank(t *testing.T) {
+	tests := []struct {
+		desired int
+		order   []int
+	}{
+		{desired: 400, order: []int{400, 500, 300, 100, 600, 900}},
+		{desired: 500, order: []int{500, 400, 300, 600, 700}},
+		{desired: 300, order: []int{300, 200, 100, 400, 500}},
+		{desired: 600, order: []int{600, 700, 900, 500, 400}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			for j := 1; j < len(test.order); j++ {
+				if weightRank(test.desired, test.order[j-1]) >= weightRank(test.desired, test.order[j]) {
+					t.Fatalf("for %d expected %d before %d", test.desired, test.order[j-1], test.order[j])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package font
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// table is the data of a table of a TrueType or OpenType font. Reads beyond its end return zero values,
+// so truncated tables result in missing entries rather than errors.
+type table []byte
+
+func (t table) u16(off int) uint16 {
+	if off < 0 || off+2 > len(t) {
+		return 0
+	}
+	return binary.BigEndian.Uint16(t[off:])
+}
+
+func (t table) i16(off int) int16 {
+	return int16(t.u16(off))
+}
+
+func (t table) u32(off int) uint32 {
+	if off < 0 || off+4 > len(t) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(t[off:])
+}
+
+func (t table) tag(off int) string {
+	if off < 0 || off+4 > len(t) {
+		return ""
+	}
+	return string(t[off : off+4])
+}
+
+// sub returns the part of t starting at off
+func (t table) sub(off int) table {
+	if off <= 0 || off > len(t) {
+		return nil
+	}
+	return t[off:]
+}
+
+// tableDirectory returns the tables of the font starting at offset in data, which is only non-zero in collections
+func tableDirectory(data []byte, offset int) (map[string]table, error) {
+	dir := table(data)
+	if offset > 0 {
+		dir = dir.sub(offset)
+	}
+	if len(dir) < 12 {
+		return nil, errors.New("truncated table directory")
+	}
+	switch version := dir.tag(0); version {
+	case "\x00\x01\x00\x00", "OTTO", "true":
+	default:
+		return nil, errors.Errorf("invalid sfnt version %q", version)
+	}
+	n := int(dir.u16(4))
+	if len(dir) < 12+16*n {
+		return nil, errors.New("truncated table records")
+	}
+	tables := map[string]table{}
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		tag := dir.tag(rec)
+		off, length := int(dir.u32(rec+8)), int(dir.u32(rec+12))
+		if off < 0 || length < 0 || off+length > len(data) {
+			return nil, errors.Errorf("table %q exceeds the font data", tag)
+		}
+		tables[tag] = table(data[off : off+length])
+	}
+	return tables, nil
+}
+
+// parseCmap returns the glyphs of the characters of the best unicode subtable of the cmap table t
+func parseCmap(t table) (map[rune]uint16, error) {
+	var best table
+	bestRank := 0
+	n := int(t.u16(2))
+	for i := 0; i < n; i++ {
+		platform, encoding := t.u16(4+8*i), t.u16(6+8*i)
+		sub := t.sub(int(t.u32(8 + 8*i)))
+		rank := 0
+		switch format := sub.u16(0); {
+		case format == 12 && (platform == 0 || platform == 3 && encoding == 10):
+			rank = 3
+		case format == 4 && (platform == 0 || platform == 3 && encoding == 1):
+			rank = 2
+		case format == 4 && platform == 3 && encoding == 0:
+			//symbol fonts
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = sub, rank
+		}
+	}
+	switch bestRank {
+	case 0:
+		return nil, errors.New("no unicode cmap subtable")
+	case 3:
+		return cmap12(best), nil
+	default:
+		return cmap4(best), nil
+	}
+}
+
+// cmap4 returns the glyphs of a segmented cmap subtable
+func cmap4(t table) map[rune]uint16 {
+	glyphs := map[rune]uint16{}
+	segX2 := int(t.u16(6))
+	for s := 0; s < segX2/2; s++ {
+		end := int(t.u16(14 + 2*s))
+		start := int(t.u16(16 + segX2 + 2*s))
+		delta := t.u16(16 + 2*segX2 + 2*s)
+		rangeOff := 16 + 3*segX2 + 2*s
+		rangeOffset := int(t.u16(rangeOff))
+		for c := start; c <= end && c != 0xffff; c++ {
+			var g uint16
+			if rangeOffset == 0 {
+				g = uint16(c) + delta
+			} else if g = t.u16(rangeOff + rangeOffset + 2*(c-start)); g != 0 {
+				g += delta
+			}
+			if g != 0 {
+				glyphs[rune(c)] = g
+			}
+		}
+	}
+	return glyphs
+}
+
+// cmap12 returns the glyphs of a segmented coverage cmap subtable
+func cmap12(t table) map[rune]uint16 {
+	glyphs := map[rune]uint16{}
+	n := int(t.u32(12))
+	for i := 0; i < n; i++ {
+		grp := 16 + 12*i
+		start, end, glyph := t.u32(grp), t.u32(grp+4), t.u32(grp+8)
+		if end > 0x10ffff || start > end {
+			continue
+		}
+		for c := start; c <= end; c++ {
+			glyphs[rune(c)] = uint16(glyph + c - start)
+		}
+	}
+	return glyphs
+}
+
+// coverageIndex returns the index of glyph g in the coverage table t of a GPOS or GSUB subtable
+func coverageIndex(t table, g uint16) (int, bool) {
+	switch t.u16(0) {
+	case 1:
+		n := int(t.u16(2))
+		i := sort.Search(n, func(i int) bool { return t.u16(4+2*i) >= g })
+		if i < n && t.u16(4+2*i) == g {
+			return i, true
+		}
+	case 2:
+		n := int(t.u16(2))
+		i := sort.Search(n, func(i int) bool { return t.u16(4+6*i+2) >= g })
+		if i < n && t.u16(4+6*i) <= g {
+			return int(t.u16(4+6*i+4)) + int(g-t.u16(4+6*i)), true
+		}
+	}
+	return 0, false
+}
+
+// glyphClass returns the class of glyph g in the class definition table t, which is 0 for glyphs without one
+func glyphClass(t table, g uint16) int {
+	switch t.u16(0) {
+	case 1:
+		start, n := t.u16(2), int(t.u16(4))
+		if g >= start && int(g-start) < n {
+			return int(t.u16(6 + 2*int(g-start)))
+		}
+	case 2:
+		n := int(t.u16(2))
+		i := sort.Search(n, func(i int) bool { return t.u16(4+6*i+2) >= g })
+		if i < n && t.u16(4+6*i) <= g {
+			return int(t.u16(4 + 6*i + 4))
+		}
+	}
+	return 0
+}
+
+// featureLookups returns the subtables of the lookups of the feature tag in the GPOS or GSUB table t, which have
+// the lookup type typ. Extension subtables of the type ext are resolved. Lookups keep the order of the lookup list.
+// The features of all scripts and languages are used.
+func featureLookups(t table, tag string, typ, ext uint16) [][]table {
+	features := t.sub(int(t.u16(6)))
+	lookupList := t.sub(int(t.u16(8)))
+	seen := map[int]bool{}
+	var indices []int
+	for i := 0; i < int(features.u16(0)); i++ {
+		rec := 2 + 6*i
+		if features.tag(rec) != tag {
+			continue
+		}
+		feature := features.sub(int(features.u16(rec + 4)))
+		for j := 0; j < int(feature.u16(2)); j++ {
+			idx := int(feature.u16(4 + 2*j))
+			if !seen[idx] {
+				seen[idx] = true
+				indices = append(indices, idx)
+			}
+		}
+	}
+	sort.Ints(indices)
+	var lookups [][]table
+	for _, idx := range indices {
+		if idx >= int(lookupList.u16(0)) {
+			continue
+		}
+		lookup := lookupList.sub(int(lookupList.u16(2 + 2*idx)))
+		lookupType := lookup.u16(0)
+		var subtables []table
+		for i := 0; i < int(lookup.u16(4)); i++ {
+			sub := lookup.sub(int(lookup.u16(6 + 2*i)))
+			switch {
+			case lookupType == ext && sub.u16(2) == typ:
+				subtables = append(subtables, sub.sub(int(sub.u32(4))))
+			case lookupType == typ:
+				subtables = append(subtables, sub)
+			}
+		}
+		if len(subtables) > 0 {
+			lookups = append(lookups, subtables)
+		}
+	}
+	return lookups
+}
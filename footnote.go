@@ -0,0 +1,225 @@
+package xpdf
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+const (
+	// default font size of footnotes relative to the document font
+	footnoteFontScale = 0.8
+	// space above the footnotes of a page, which contains the separator rule
+	footnoteRuleSpace = 4.0
+	// width of the separator rule relative to the printable width
+	footnoteRuleRatio = 1.0 / 3.0
+	footnoteRuleWidth = 0.2
+	// distance, by which footnotes end above the bottom of the printable area, so that rounding errors
+	// don't trigger the automatic page break of the engine
+	footnoteTolerance = 0.01
+)
+
+// footnote is the content of a footnote, which is put at the bottom of the page containing its marker
+type footnote struct {
+	number int
+	iss    []xdoc.Instruction
+	sty    style.Styles
+	// font of the text containing the marker
+	textFont style.Font
+	height   float64
+}
+
+// footnoteMark is the marker preceding the content of a footnote at the page bottom
+type footnoteMark struct {
+	xdoc.NoStyles
+	number int
+}
+
+// footnoteMarkItem returns the text item of a footnote marker and its width. Markers are superscripts sticking to the preceding word.
+func (p *Processor) footnoteMarkItem(number int, sty style.Styles) (*textItem, float64) {
+	msty := sty
+	msty.VerticalAlign = style.VerticalAlign{Mode: style.VerticalAlignSuper}
+	msty, dy := p.shiftedRun(msty)
+	item := &textItem{
+		sty:   msty,
+		text:  strconv.Itoa(number),
+		glued: true,
+		dy:    dy,
+	}
+	return item, p.runWidth(item.text, msty)
+}
+
+// footnoteItem returns the marker item of the footnote fn with the given number in a text with styles sty
+func (p *Processor) footnoteItem(fn *xdoc.Footnote, number int, sty style.Styles) (*textItem, float64) {
+	base := p.currStyles
+	base.Font.PointSize *= footnoteFontScale
+	item, width := p.footnoteMarkItem(number, sty)
+	item.footnote = &footnote{
+		number:   number,
+		iss:      fn.ISS,
+		sty:      fn.MutatedStyles(p.doc.StyleClasses(), base),
+		textFont: sty.Font,
+	}
+	return item, width
+}
+
+// instructions returns the content of the footnote preceded by its marker
+func (fn *footnote) instructions() []xdoc.Instruction {
+	return append([]xdoc.Instruction{&footnoteMark{number: fn.number}}, fn.iss...)
+}
+
+// footnoteSpace returns the height at the bottom of the page, which is occupied by its footnotes
+func (p *Processor) footnoteSpace() float64 {
+	if len(p.footnotes) == 0 {
+		return 0
+	}
+	space := footnoteRuleSpace
+	for _, fn := range p.footnotes {
+		space += fn.height
+	}
+	return space
+}
+
+// footnoteFits reports if a footnote of the given height fits on the current page below a text reaching to bottom
+func footnoteFits(bottom, y1, space, height float64) bool {
+	if space == 0 {
+		space = footnoteRuleSpace
+	}
+	return bottom+space+height <= y1
+}
+
+// measureFootnote sets the height of fn, if it isn't measured yet. The font of the text containing its marker is the current font afterwards.
+func (p *Processor) measureFootnote(fn *footnote) {
+	if fn.height > 0 {
+		return
+	}
+	x0, _, x1, _ := p.engine.PrintableArea()
+	fn.height = p.textHeightFnc(fn.sty)(fn.instructions(), x1-x0, fn.sty)
+	p.engine.ChangeFont(fn.textFont)
+}
+
+// footnotesBottom returns the bottom of the text above the footnotes, when the current line reaches to bottom
+func (p *Processor) footnotesBottom(bottom float64) float64 {
+	if p.columns != nil && p.columns.bottom > bottom {
+		return p.columns.bottom
+	}
+	return bottom
+}
+
+// footnotesFit reports if the footnotes of the markers in line fit on the current page, when the line starts at the
+// current position. At the top of a page they are taken to fit, as the line can't gain space on the next page.
+func (p *Processor) footnotesFit(line textLine, fontHeight float64) bool {
+	if p.preventPageBreak || p.atPageTop() {
+		return true
+	}
+	_, y := p.engine.GetXY()
+	_, y0, _, y1 := p.engine.PrintableArea()
+	bottom := p.footnotesBottom(y + line.spaceAbove() + fontHeight + line.below)
+	space := p.footnoteSpace()
+	for _, item := range line.items {
+		if item.footnote == nil {
+			continue
+		}
+		p.measureFootnote(item.footnote)
+		if !footnoteFits(y0, y1, 0, item.footnote.height) {
+			//it stays on the page of its marker anyway
+			continue
+		}
+		if len(p.deferredFootnotes) > 0 || !footnoteFits(bottom, y1, space, item.footnote.height) {
+			return false
+		}
+		if space == 0 {
+			space = footnoteRuleSpace
+		}
+		space += item.footnote.height
+	}
+	return true
+}
+
+// putFootnote places the footnote of item, whose line reaches to bottom. If the footnote doesn't fit on the current page, it is deferred to the next one.
+func (p *Processor) putFootnote(item *textItem, bottom float64) {
+	fn := item.footnote
+	if fn == nil {
+		return
+	}
+	p.measureFootnote(fn)
+	if fn.number > p.footnoteNumber {
+		p.footnoteNumber = fn.number
+	}
+	p.placeFootnote(fn, p.footnotesBottom(bottom), false)
+}
+
+// placeFootnote adds fn to the footnotes of the current page or defers it. Forced, it is added even if it doesn't fit.
+// Footnotes, which don't fit on an empty page either, stay on the page of their marker.
+func (p *Processor) placeFootnote(fn *footnote, bottom float64, force bool) {
+	_, y0, _, y1 := p.engine.PrintableArea()
+	force = force || !footnoteFits(y0, y1, 0, fn.height)
+	if len(p.deferredFootnotes) == 0 && (force || footnoteFits(bottom, y1, p.footnoteSpace(), fn.height)) {
+		p.footnotes = append(p.footnotes, fn)
+		return
+	}
+	p.deferredFootnotes = append(p.deferredFootnotes, fn)
+}
+
+// placeDeferredFootnotes moves the deferred footnotes to the page, as far as they fit below the top of the printable area
+func (p *Processor) placeDeferredFootnotes() {
+	deferred := p.deferredFootnotes
+	p.deferredFootnotes = nil
+	_, y0, _, _ := p.engine.PrintableArea()
+	for i, fn := range deferred {
+		p.placeFootnote(fn, y0, i == 0)
+	}
+}
+
+// renderFootnotes puts the footnotes of the current page at its bottom above the footer
+func (p *Processor) renderFootnotes() {
+	if len(p.footnotes) == 0 {
+		return
+	}
+	x, y := p.engine.GetXY()
+	columns := p.columns
+	p.columns = nil
+	//footnotes overflowing the page continue on new pages, which are started before the deferred ones are placed
+	prevent, deferred := p.preventPageBreak, p.deferredFootnotes
+	p.preventPageBreak, p.deferredFootnotes = false, nil
+	defer func() {
+		p.preventPageBreak, p.deferredFootnotes = prevent, deferred
+		p.columns = columns
+		p.engine.SetX(x)
+		p.engine.SetY(y)
+		p.resetStyles()
+	}()
+
+	notes := p.footnotes
+	//footnotes overflowing the page start below its text
+	top := math.Max(p.pageArea().y1-footnoteTolerance, p.footnotesBottom(y))
+	p.footnotes = nil
+	x0, _, x1, _ := p.engine.PrintableArea()
+	width := x1 - x0
+
+	ruleY := top + footnoteRuleSpace/2
+	p.engine.SetLineWidth(footnoteRuleWidth)
+	p.engine.SetDrawColor(notes[0].sty.Color.Foreground.Values())
+	p.engine.MoveTo(x0, ruleY)
+	p.engine.LineTo(x0+width*footnoteRuleRatio, ruleY)
+	p.engine.DrawPath()
+
+	y0 := top + footnoteRuleSpace
+	for _, fn := range notes {
+		p.engine.SetX(x0)
+		p.engine.SetY(y0)
+		p.writeTextFnc(fn.sty)(fn.instructions(), fixedSpan(width), fn.sty)
+		_, y0 = p.engine.GetXY()
+	}
+}
+
+// flushFootnotes renders the footnotes of the current page. Footnotes, which are still deferred, get pages of their own,
+// where each page takes at least one of them.
+func (p *Processor) flushFootnotes() {
+	for len(p.deferredFootnotes) > 0 {
+		p.newPage()
+	}
+	p.renderFootnotes()
+}
@@ -0,0 +1,125 @@
+package xpdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFootnoteFits(t *testing.T) {
+	tests := []struct {
+		bottom, y1, space, height float64
+		exp                       bool
+	}{
+		{bottom: 100, y1: 200, space: 0, height: 10, exp: true},
+		{bottom: 100, y1: 114, space: 0, height: 10, exp: true},
+		{bottom: 100, y1: 113, space: 0, height: 10, exp: false},
+		{bottom: 100, y1: 130, space: 20, height: 10, exp: true},
+		{bottom: 100, y1: 129, space: 20, height: 10, exp: false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := footnoteFits(test.bottom, test.y1, test.space, test.height)
+			if have != test.exp {
+				t.Fatalf("have %t, want %t", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestLineSpaces(t *testing.T) {
+	tests := []struct {
+		glued []bool
+		exp   int
+	}{
+		{glued: nil, exp: 0},
+		{glued: []bool{false}, exp: 0},
+		{glued: []bool{false, false, false}, exp: 2},
+		{glued: []bool{false, true, false}, exp: 1},
+		{glued: []bool{true, false, true}, exp: 1},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var line textLine
+			for _, g := range test.glued {
+				line.items = append(line.items, &textItem{glued: g})
+			}
+			have := line.spaces()
+			if have != test.exp {
+				t.Fatalf("have %d, want %d", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestFootnotePageBreak(t *testing.T) {
+	tests := []struct {
+		note string
+		// pages of the marker and of the note
+		markerPage int
+		notePage   int
+	}{
+		{note: "short note", markerPage: 1, notePage: 1},
+		//the line with the marker moves to the page of its note
+		{note: repeated("note", 300), markerPage: 2, notePage: 2},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			body := `<sety y="240"/><text style="orphans: 1; widows: 1">` + repeated("filler", 18) + ` target<footnote>` + test.note + `</footnote> after</text>`
+			e := processTestDoc(t, "", body)
+			marker, note := e.find(t, "target"), e.find(t, "note")
+			if marker.page != test.markerPage || note.page != test.notePage {
+				t.Fatalf("have marker on page %d, note on page %d, want %d and %d", marker.page, note.page, test.markerPage, test.notePage)
+			}
+			if first := e.find(t, "filler"); first.page != 1 {
+				t.Fatalf("have first line on page %d, want 1", first.page)
+			}
+		})
+	}
+}
+
+func TestDeferredFootnotes(t *testing.T) {
+	//the second note of the line at the top of the page doesn't fit below the first one
+	body := `<text>target<footnote>first ` + repeated("note", 700) + `</footnote> and<footnote>second ` + repeated("note", 700) + `</footnote></text>`
+	e := processTestDoc(t, "", body)
+	if marker := e.find(t, "target"); marker.page != 1 {
+		t.Fatalf("have marker on page %d, want 1", marker.page)
+	}
+	if first := e.find(t, "first"); first.page != 1 {
+		t.Fatalf("have first note on page %d, want 1", first.page)
+	}
+	//the deferred note gets a page of its own at the end
+	if second := e.find(t, "second"); second.page != 2 {
+		t.Fatalf("have second note on page %d, want 2", second.page)
+	}
+	if last := e.texts[len(e.texts)-1]; last.page != 2 {
+		t.Fatalf("have %d pages, want 2", last.page)
+	}
+}
+
+func TestOverflowingFootnote(t *testing.T) {
+	//the note doesn't fit on any page, so it starts below its marker on the first page and continues on the next page
+	body := `<text>first line<br/>target<footnote>` + repeated("note", 2000) + `</footnote> after</text><text>last<footnote>short</footnote></text>`
+	e := processTestDoc(t, "", body)
+	marker, note := e.find(t, "target"), e.find(t, "note")
+	if marker.page != 1 || note.page != marker.page || note.y <= marker.y {
+		t.Fatalf("have note on page %d at %.2f, want it below the marker on page %d at %.2f", note.page, note.y, marker.page, marker.y)
+	}
+	//the note is split into lines ending within the printable area of the first and second page
+	var ys []float64
+	for _, rt := range e.texts {
+		if strings.Contains(rt.text, "note") && (len(ys) == 0 || rt.y != ys[len(ys)-1]) {
+			ys = append(ys, rt.y)
+		}
+	}
+	lineHeight := ys[1] - ys[0]
+	for _, rt := range e.texts {
+		if strings.Contains(rt.text, "note") && (rt.page > 2 || rt.y < 20 || rt.y+lineHeight > 277) {
+			t.Fatalf("have note line on page %d from %.2f to %.2f, want it on page 1 or 2 between 20 and 277", rt.page, rt.y, rt.y+lineHeight)
+		}
+	}
+	last, short := e.find(t, "last"), e.find(t, "short")
+	if last.page != 3 || short.page != 3 {
+		t.Fatalf("have last text on page %d and its note on page %d, want both on page 3", last.page, short.page)
+	}
+}
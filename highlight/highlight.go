@@ -0,0 +1,292 @@
+// Package highlight splits source code into tokens for simple syntax highlighting
+package highlight
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Kind int
+
+const (
+	Plain Kind = iota
+	Keyword
+	String
+	Comment
+	Number
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Keyword:
+		return "keyword"
+	case String:
+		return "string"
+	case Comment:
+		return "comment"
+	case Number:
+		return "number"
+	default:
+		return "plain"
+	}
+}
+
+// Kinds are all token kinds, which are highlighted
+var Kinds = []Kind{Keyword, String, Comment, Number}
+
+type Token struct {
+	Kind Kind
+	Text string
+}
+
+// language describes the lexical elements of a language
+type language struct {
+	keywords      map[string]bool
+	caseFold      bool
+	lineComments  []string
+	blockComments [][2]string
+	// longStrings are delimiters of strings, which may span lines and have no escapes
+	longStrings [][2]string
+	quotes      string
+}
+
+func words(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var goLang = &language{
+	keywords: words(`break case chan const continue default defer else fallthrough for func go goto if import interface
+		map package range return select struct switch type var true false nil iota`),
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	longStrings:   [][2]string{{"`", "`"}},
+	quotes:        `"'`,
+}
+
+var pythonLang = &language{
+	keywords: words(`False None True and as assert async await break class continue def del elif else except finally
+		for from global if import in is lambda nonlocal not or pass raise return try while with yield`),
+	lineComments: []string{"#"},
+	longStrings:  [][2]string{{`"""`, `"""`}, {`'''`, `'''`}},
+	quotes:       `"'`,
+}
+
+var jsLang = &language{
+	keywords: words(`async await break case catch class const continue debugger default delete do else export extends
+		false finally for function if import in instanceof let new null of return super switch this throw true try
+		typeof undefined var void while with yield`),
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	longStrings:   [][2]string{{"`", "`"}},
+	quotes:        `"'`,
+}
+
+var jsonLang = &language{
+	keywords: words(`true false null`),
+	quotes:   `"`,
+}
+
+var sqlLang = &language{
+	keywords: words(`add all alter and as asc avg begin between by case commit count create default delete desc distinct
+		drop else end exists foreign from group having in index inner insert into is join key left like limit max min
+		not null offset on or order outer primary references right rollback select set sum table then union unique
+		update values view when where`),
+	caseFold:      true,
+	lineComments:  []string{"--"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	quotes:        `'"`,
+}
+
+var shellLang = &language{
+	keywords:     words(`case do done echo elif else esac exit export fi for function if in local return then until while`),
+	lineComments: []string{"#"},
+	quotes:       `"'`,
+}
+
+var languages = map[string]*language{
+	"go":         goLang,
+	"python":     pythonLang,
+	"py":         pythonLang,
+	"javascript": jsLang,
+	"js":         jsLang,
+	"typescript": jsLang,
+	"ts":         jsLang,
+	"json":       jsonLang,
+	"sql":        sqlLang,
+	"shell":      shellLang,
+	"sh":         shellLang,
+	"bash":       shellLang,
+}
+
+// Supported reports if lang can be highlighted
+func Supported(lang string) bool {
+	lang = strings.ToLower(lang)
+	_, ok := languages[lang]
+	return ok || lang == "xml" || lang == "html"
+}
+
+// Tokenize splits src into tokens of the language lang. Unknown languages result in a single plain token.
+func Tokenize(lang, src string) []Token {
+	lang = strings.ToLower(lang)
+	if lang == "xml" || lang == "html" {
+		return tokenizeXML(src)
+	}
+	l, ok := languages[lang]
+	if !ok {
+		return []Token{{Kind: Plain, Text: src}}
+	}
+	return l.tokenize(src)
+}
+
+// tokens collects tokens, where adjacent ones of the same kind are merged
+type tokens []Token
+
+func (ts *tokens) add(kind Kind, s string) {
+	if s == "" {
+		return
+	}
+	if n := len(*ts); n > 0 && (*ts)[n-1].Kind == kind {
+		(*ts)[n-1].Text += s
+		return
+	}
+	*ts = append(*ts, Token{Kind: kind, Text: s})
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// until returns the length of s up to and including end. If end is not found, it is the length of s.
+func until(s, end string) int {
+	if i := strings.Index(s, end); i >= 0 {
+		return i + len(end)
+	}
+	return len(s)
+}
+
+// lineEnd returns the length of s up to the line break
+func lineEnd(s string) int {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return i
+	}
+	return len(s)
+}
+
+// quoted returns the length of the string starting with quote q in s, which ends at the line end at the latest
+func quoted(s string, q byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\n':
+			return i
+		case q:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func (l *language) tokenize(src string) []Token {
+	var ts tokens
+	prev := ' '
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		n, kind := l.next(rest, prev)
+		ts.add(kind, rest[:n])
+		prev, _ = utf8.DecodeLastRuneInString(rest[:n])
+		i += n
+	}
+	return ts
+}
+
+// next returns the length and kind of the token at the start of s, which follows the rune prev
+func (l *language) next(s string, prev rune) (int, Kind) {
+	for _, c := range l.blockComments {
+		if strings.HasPrefix(s, c[0]) {
+			return len(c[0]) + until(s[len(c[0]):], c[1]), Comment
+		}
+	}
+	for _, c := range l.lineComments {
+		if strings.HasPrefix(s, c) {
+			return lineEnd(s), Comment
+		}
+	}
+	for _, q := range l.longStrings {
+		if strings.HasPrefix(s, q[0]) {
+			return len(q[0]) + until(s[len(q[0]):], q[1]), String
+		}
+	}
+	if strings.IndexByte(l.quotes, s[0]) >= 0 {
+		return quoted(s, s[0]), String
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if isIdent(prev) {
+		return size, Plain
+	}
+	if unicode.IsDigit(r) || (r == '.' && len(s) > 1 && s[1] >= '0' && s[1] <= '9') {
+		n := 0
+		for n < len(s) && (isIdent(rune(s[n])) || s[n] == '.') {
+			n++
+		}
+		return n, Number
+	}
+	if isIdent(r) {
+		n := 0
+		for n < len(s) {
+			r, size := utf8.DecodeRuneInString(s[n:])
+			if !isIdent(r) {
+				break
+			}
+			n += size
+		}
+		word := s[:n]
+		if l.caseFold {
+			word = strings.ToLower(word)
+		}
+		if l.keywords[word] {
+			return n, Keyword
+		}
+		return n, Plain
+	}
+	return size, Plain
+}
+
+// tokenizeXML splits XML or HTML into tokens, where tag names are keywords and attribute values strings
+func tokenizeXML(src string) []Token {
+	var ts tokens
+	inTag := false
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		var n int
+		var kind Kind
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			n, kind = until(rest, "-->"), Comment
+		case !inTag && rest[0] == '<':
+			n = 1
+			for n < len(rest) && (rest[n] == '/' || rest[n] == '?' || rest[n] == '!' || isIdent(rune(rest[n])) || rest[n] == ':' || rest[n] == '-') {
+				n++
+			}
+			kind = Keyword
+			inTag = true
+		case inTag && (rest[0] == '>' || strings.HasPrefix(rest, "/>") || strings.HasPrefix(rest, "?>")):
+			n = strings.IndexByte(rest, '>') + 1
+			kind = Keyword
+			inTag = false
+		case inTag && (rest[0] == '"' || rest[0] == '\''):
+			n, kind = quoted(rest, rest[0]), String
+		default:
+			_, n = utf8.DecodeRuneInString(rest)
+			kind = Plain
+		}
+		ts.add(kind, rest[:n])
+		i += n
+	}
+	return ts
+}
@@ -0,0 +1,83 @@
+package highlight
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		in   string
+		exp  []Token
+	}{
+		{
+			name: "unknown",
+			lang: "cobol",
+			in:   `MOVE "a" TO b`,
+			exp:  []Token{{Plain, `MOVE "a" TO b`}},
+		},
+		{
+			name: "go",
+			lang: "go",
+			in:   "func f() { return \"a\\\"b\" } // done\nx1 := 0x1f",
+			exp: []Token{
+				{Keyword, "func"}, {Plain, " f() { "}, {Keyword, "return"}, {Plain, " "}, {String, `"a\"b"`},
+				{Plain, " } "}, {Comment, "// done"}, {Plain, "\nx1 := "}, {Number, "0x1f"},
+			},
+		},
+		{
+			name: "go raw string",
+			lang: "Go",
+			in:   "s := `a\nb` /* c */",
+			exp:  []Token{{Plain, "s := "}, {String, "`a\nb`"}, {Plain, " "}, {Comment, "/* c */"}},
+		},
+		{
+			name: "python",
+			lang: "py",
+			in:   "def f(): # x\n    return '''a'''",
+			exp: []Token{
+				{Keyword, "def"}, {Plain, " f(): "}, {Comment, "# x"}, {Plain, "\n    "}, {Keyword, "return"}, {Plain, " "},
+				{String, "'''a'''"},
+			},
+		},
+		{
+			name: "sql case",
+			lang: "sql",
+			in:   "SELECT a FROM t -- all",
+			exp:  []Token{{Keyword, "SELECT"}, {Plain, " a "}, {Keyword, "FROM"}, {Plain, " t "}, {Comment, "-- all"}},
+		},
+		{
+			name: "json",
+			lang: "json",
+			in:   `{"a": [1.5, true]}`,
+			exp: []Token{
+				{Plain, "{"}, {String, `"a"`}, {Plain, ": ["}, {Number, "1.5"}, {Plain, ", "}, {Keyword, "true"}, {Plain, "]}"},
+			},
+		},
+		{
+			name: "unterminated string ends at line end",
+			lang: "js",
+			in:   "'a\nlet",
+			exp:  []Token{{String, "'a"}, {Plain, "\n"}, {Keyword, "let"}},
+		},
+		{
+			name: "xml",
+			lang: "xml",
+			in:   `<a href="x">t<!-- c --></a>`,
+			exp: []Token{
+				{Keyword, "<a"}, {Plain, " href="}, {String, `"x"`}, {Keyword, ">"}, {Plain, "t"}, {Comment, "<!-- c -->"},
+				{Keyword, "</a>"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := Tokenize(test.lang, test.in)
+			if !reflect.DeepEqual(test.exp, res) {
+				t.Fatalf("want %v, have %v", test.exp, res)
+			}
+		})
+	}
+}
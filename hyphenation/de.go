@@ -0,0 +1,12 @@
+package hyphenation
+
+import _ "embed"
+
+//go:embed patterns/hyph-de-1996.pat.txt
+var dePatterns string
+
+// NewDe returns a hyphenator for German in the orthography of 1996 with the patterns hyph-de-1996.pat.txt
+// of the TeX hyph-utf8 project
+func NewDe() *Hyphenator {
+	return loadBundled(dePatterns)
+}
@@ -2,10 +2,11 @@ package hyphenation
 
 import "bytes"
 
+// NewEnUs returns a hyphenator for American English, which keeps at least 3 runes after the last hyphenation point like TeX
 func NewEnUs() *Hyphenator {
 	buf := bytes.NewBufferString(enUsPatterns)
 	pl, _ := loadPatternLookup(buf)
-	return new(pl)
+	return new(pl).WithMinLengths(defaultLeftMin, 3)
 }
 
 // func NewEnUsLookup() *PatternLookup {
@@ -0,0 +1,11 @@
+package hyphenation
+
+import _ "embed"
+
+//go:embed patterns/hyph-es.pat.txt
+var esPatterns string
+
+// NewEs returns a hyphenator for Spanish with the patterns hyph-es.pat.txt of the TeX hyph-utf8 project
+func NewEs() *Hyphenator {
+	return loadBundled(esPatterns)
+}
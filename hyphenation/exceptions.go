@@ -0,0 +1,112 @@
+package hyphenation
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// exceptions maps lowercase words to the rune positions, where they are hyphenated
+type exceptions map[string][]int
+
+// parseException parses a word like "ta-ble", where hyphens mark the hyphenation points
+func parseException(s string) (string, []int, error) {
+	parts := strings.Split(s, "-")
+	var word string
+	var breaks []int
+	for i, part := range parts {
+		if part == "" {
+			return "", nil, errors.Errorf("invalid exception %q", s)
+		}
+		if i > 0 {
+			breaks = append(breaks, len([]rune(word)))
+		}
+		word += part
+	}
+	return strings.ToLower(word), breaks, nil
+}
+
+// exceptionWords returns the words of an exception list. They may be enclosed in TeX's \hyphenation{...},
+// where everything outside is ignored. Percent signs start comments.
+func exceptionWords(s string) ([]string, error) {
+	s = stripComments(s)
+	const cmd = `\hyphenation`
+	if !strings.Contains(s, cmd) {
+		return strings.Fields(s), nil
+	}
+	groups, err := texGroups(s, cmd)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	for _, group := range groups {
+		words = append(words, strings.Fields(group)...)
+	}
+	return words, nil
+}
+
+func parseExceptions(r io.Reader) (exceptions, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "read-all")
+	}
+	words, err := exceptionWords(string(bs))
+	if err != nil {
+		return nil, err
+	}
+	ex := exceptions{}
+	for _, w := range words {
+		word, breaks, err := parseException(w)
+		if err != nil {
+			return nil, err
+		}
+		ex[word] = breaks
+	}
+	return ex, nil
+}
+
+// WithExceptions returns a hyphenator using the patterns of h and its exceptions extended by the ones read from r.
+// Exceptions are words like "ta-ble" or "project", which is never hyphenated, separated by whitespace.
+// They may be enclosed in TeX's \hyphenation{...}.
+func (h *Hyphenator) WithExceptions(r io.Reader) (*Hyphenator, error) {
+	ex, err := parseExceptions(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse-exceptions")
+	}
+	hc := h.clone()
+	for word, breaks := range ex {
+		hc.exceptions[word] = breaks
+	}
+	return hc, nil
+}
+
+// WithExceptionsFromFile returns a hyphenator like WithExceptions with the exceptions read from file
+func (h *Hyphenator) WithExceptionsFromFile(file string) (*Hyphenator, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open-file %q", file)
+	}
+	defer f.Close()
+	return h.WithExceptions(f)
+}
+
+// hyphenateException returns the parts of s, if it is an exception
+func (h *Hyphenator) hyphenateException(s string) ([]string, bool) {
+	breaks, ok := h.exceptions[strings.ToLower(s)]
+	if !ok {
+		return nil, false
+	}
+	rs := []rune(s)
+	var parts []string
+	last := 0
+	for _, b := range breaks {
+		if b > len(rs) {
+			break
+		}
+		parts = append(parts, string(rs[last:b]))
+		last = b
+	}
+	return append(parts, string(rs[last:])), true
+}
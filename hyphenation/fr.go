@@ -0,0 +1,12 @@
+package hyphenation
+
+import _ "embed"
+
+//go:embed patterns/hyph-fr.pat.txt
+var frPatterns string
+
+// NewFr returns a hyphenator for French with the patterns hyph-fr.pat.txt of the TeX hyph-utf8 project,
+// which keeps at least 3 runes after the last hyphenation point like TeX
+func NewFr() *Hyphenator {
+	return loadBundled(frPatterns).WithMinLengths(defaultLeftMin, 3)
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
 )
@@ -25,28 +26,103 @@ func Load(r io.Reader) (*Hyphenator, error) {
 	return new(pl), nil
 }
 
+// default minimum lengths of the first and last fragment of hyphenated words like TeX's lefthyphenmin and righthyphenmin
+const (
+	defaultLeftMin  = 2
+	defaultRightMin = 2
+)
+
+// maximum length of words, which are hyphenated
+const maxWordLength = 100
+
 type Hyphenator struct {
-	lookup *patternLookup
+	lookup     *patternLookup
+	exceptions exceptions
+	// leftMin and rightMin are the minimum number of runes of the first and last fragment
+	leftMin  int
+	rightMin int
 }
 
 func new(pl *patternLookup) *Hyphenator {
 	return &Hyphenator{
-		lookup: pl,
+		lookup:     pl,
+		exceptions: exceptions{},
+		leftMin:    defaultLeftMin,
+		rightMin:   defaultRightMin,
 	}
 }
 
-func (h *Hyphenator) Hyphenate(s string) []string {
-	if len(s) < 3 {
-		//don't hyphenate words with less than 3 runes
-		return []string{s}
+// clone returns a copy of h, whose exceptions may be changed independently
+func (h *Hyphenator) clone() *Hyphenator {
+	hc := *h
+	hc.exceptions = exceptions{}
+	for word, breaks := range h.exceptions {
+		hc.exceptions[word] = breaks
+	}
+	return &hc
+}
+
+// WithMinLengths returns a hyphenator like h, which keeps at least left runes before the first and right runes
+// after the last hyphenation point of a word, like TeX's lefthyphenmin and righthyphenmin. Values below 1 are taken as 1.
+func (h *Hyphenator) WithMinLengths(left, right int) *Hyphenator {
+	hc := h.clone()
+	hc.leftMin = max(left, 1)
+	hc.rightMin = max(right, 1)
+	return hc
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
 	}
+	return b
+}
+
+// isWordRune reports if r belongs to a word, which is hyphenated. Other runes like punctuation are stripped
+// from the start and end of words before hyphenation.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r)
+}
+
+// Hyphenate splits s into the fragments between its hyphenation points. Punctuation at the start and end of s
+// is kept with the first and last fragment.
+func (h *Hyphenator) Hyphenate(s string) []string {
 	if strings.Contains(s, " ") {
 		return []string{s}
 	}
-	if len(s) > 100 {
+	rs := []rune(s)
+	start, end := 0, len(rs)
+	for start < end && !isWordRune(rs[start]) {
+		start++
+	}
+	for end > start && !isWordRune(rs[end-1]) {
+		end--
+	}
+	if start == end {
 		return []string{s}
 	}
-	rs := []rune("." + strings.ToLower(s) + ".")
+	parts := h.hyphenateWord(rs[start:end])
+	parts[0] = string(rs[:start]) + parts[0]
+	parts[len(parts)-1] += string(rs[end:])
+	return parts
+}
+
+// hyphenateWord splits word into the fragments between its hyphenation points
+func (h *Hyphenator) hyphenateWord(word []rune) []string {
+	if parts, ok := h.hyphenateException(string(word)); ok {
+		return parts
+	}
+	if len(word) < h.leftMin+h.rightMin || len(word) > maxWordLength {
+		return []string{string(word)}
+	}
+	//lower runes one by one to keep the positions of the weights
+	rs := make([]rune, 0, len(word)+2)
+	rs = append(rs, '.')
+	for _, r := range word {
+		rs = append(rs, unicode.ToLower(r))
+	}
+	rs = append(rs, '.')
+	//ws[i] is the weight before rs[i]
 	ws := make([]int, len(rs)+1)
 	for subSize := 1; subSize <= len(rs); subSize++ {
 		for i := 0; i < len(rs)-subSize+1; i++ {
@@ -64,21 +140,13 @@ func (h *Hyphenator) Hyphenate(s string) []string {
 	}
 
 	sl := []string{}
-	var last int
-	//skip first and last for the dots (.).
-	//skip next to first and prev. to last for start and end of word
-	for i, w := range ws[2 : len(ws)-2] {
-		if w%2 == 1 {
-			part := s[last : i+1]
-			if len(part) > 1 {
-				sl = append(sl, part)
-				last = i + 1
-			}
+	last := 0
+	//the weight before word[i] is ws[i+1] due to the leading dot
+	for i := h.leftMin; i <= len(word)-h.rightMin; i++ {
+		if ws[i+1]%2 == 1 {
+			sl = append(sl, string(word[last:i]))
+			last = i
 		}
 	}
-	part := s[last:]
-	if len(part) > 1 {
-		sl = append(sl, part)
-	}
-	return sl
+	return append(sl, string(word[last:]))
 }
@@ -1,6 +1,9 @@
 package hyphenation
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -79,3 +82,147 @@ func TestLatinHyhenation(t *testing.T) {
 	hsl := hyp.Hyphenate(s)
 	t.Logf("hyph: %v (%s)", hsl, time.Since(t0))
 }
+
+func TestExceptions(t *testing.T) {
+	hyp, err := NewEnUs().WithExceptions(strings.NewReader(`
+% exceptions
+\hyphenation{ta-ble pro-ject % nouns
+  present}
+outside-ignored`))
+	if err != nil {
+		t.Fatalf("load exceptions: %v", err)
+	}
+	tests := []struct {
+		in  string
+		exp []string
+	}{
+		{in: "table", exp: []string{"ta", "ble"}},
+		{in: "Project", exp: []string{"Pro", "ject"}},
+		{in: "present", exp: []string{"present"}},
+		{in: "outside", exp: NewEnUs().Hyphenate("outside")},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := hyp.Hyphenate(test.in)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+
+	plain, err := NewEnUs().WithExceptions(strings.NewReader("Über-gang\nab-c-d"))
+	if err != nil {
+		t.Fatalf("load plain exceptions: %v", err)
+	}
+	if have := plain.Hyphenate("übergang"); !reflect.DeepEqual(have, []string{"über", "gang"}) {
+		t.Fatalf("have %q", have)
+	}
+	for _, in := range []string{`\hyphenation{ta-ble`, "ta--ble", "-table"} {
+		if _, err := NewEnUs().WithExceptions(strings.NewReader(in)); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+}
+
+func TestLoadTeX(t *testing.T) {
+	pat := `% hyph-xx.pat.txt
+1b 1c % consonants
+1d
+`
+	hyp, err := LoadTeX(strings.NewReader(pat))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if have, exp := hyp.Hyphenate("abacada"), []string{"aba", "ca", "da"}; !reflect.DeepEqual(have, exp) {
+		t.Fatalf("have %q, want %q", have, exp)
+	}
+
+	tex := `\message{patterns}
+\patterns{ % comment }
+1b 1c
+}
+\hyphenation{aba-cada}`
+	hyp, err = LoadTeX(strings.NewReader(tex))
+	if err != nil {
+		t.Fatalf("load tex: %v", err)
+	}
+	if have, exp := hyp.Hyphenate("abacada"), []string{"aba", "cada"}; !reflect.DeepEqual(have, exp) {
+		t.Fatalf("have %q, want %q", have, exp)
+	}
+	if have, exp := hyp.Hyphenate("obecede"), []string{"obe", "cede"}; !reflect.DeepEqual(have, exp) {
+		t.Fatalf("have %q, want %q", have, exp)
+	}
+
+	for _, in := range []string{`\patterns{1b`, `\patterns 1b`} {
+		if _, err := LoadTeX(strings.NewReader(in)); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	tests := []struct {
+		lang string
+		in   string
+		exp  []string
+	}{
+		{lang: "de", in: "Fenster", exp: []string{"Fens", "ter"}},
+		{lang: "de-CH", in: "Zucker", exp: []string{"Zu", "cker"}},
+		{lang: "de", in: "Angst", exp: []string{"Angst"}},
+		{lang: "de", in: "Silbentrennungsalgorithmus", exp: []string{"Sil", "ben", "tren", "nungs", "al", "go", "rith", "mus"}},
+		{lang: "de", in: "Urinstinkt", exp: []string{"Ur", "instinkt"}},
+		{lang: "fr", in: "montagne", exp: []string{"mon", "tagne"}},
+		{lang: "fr", in: "d’approvisionnement", exp: []string{"d’ap", "pro", "vi", "sion", "ne", "ment"}},
+		{lang: "fr", in: "aujourd'hui", exp: []string{"au", "jour", "d'hui"}},
+		{lang: "it", in: "costruzione", exp: []string{"co", "stru", "zio", "ne"}},
+		{lang: "it", in: "l'orologio", exp: []string{"l'o", "ro", "lo", "gio"}},
+		{lang: "es", in: "muchacho", exp: []string{"mu", "cha", "cho"}},
+		{lang: "nl_NL", in: "schrijven", exp: []string{"schrij", "ven"}},
+		{lang: "nl", in: "ziekenhuisopname", exp: []string{"zie", "ken", "huis", "op", "na", "me"}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			hyp, ok := ForLang(test.lang)
+			if !ok {
+				t.Fatalf("no hyphenator for %q", test.lang)
+			}
+			have := hyp.Hyphenate(test.in)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+	if _, ok := ForLang("xx"); ok {
+		t.Fatalf("unexpected hyphenator for xx")
+	}
+	if _, ok := ForLang(""); ok {
+		t.Fatalf("unexpected hyphenator for empty language")
+	}
+}
+
+func TestHyphenateRunes(t *testing.T) {
+	tests := []struct {
+		hyp *Hyphenator
+		in  string
+		exp []string
+	}{
+		{hyp: NewDe(), in: "Bäckerei", exp: []string{"Bä", "cke", "rei"}},
+		{hyp: NewFr(), in: "hyphénation", exp: []string{"hy", "phé", "na", "tion"}},
+		{hyp: NewDe(), in: "(Fenster),", exp: []string{"(Fens", "ter),"}},
+		{hyp: NewDe(), in: "„Übergröße“", exp: []string{"„Über", "grö", "ße“"}},
+		{hyp: NewDe(), in: "...", exp: []string{"..."}},
+		{hyp: NewDe(), in: "Ufer", exp: []string{"Ufer"}},
+		{hyp: NewDe().WithMinLengths(1, 1), in: "Ufer", exp: []string{"U", "fer"}},
+		{hyp: NewDe().WithMinLengths(3, 3), in: "Wasserrad", exp: []string{"Was", "ser", "rad"}},
+		{hyp: NewDe().WithMinLengths(4, 3), in: "Wasserrad", exp: []string{"Wasser", "rad"}},
+		{hyp: NewDe().WithMinLengths(4, 4), in: "Wasser", exp: []string{"Wasser"}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := test.hyp.Hyphenate(test.in)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
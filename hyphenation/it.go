@@ -0,0 +1,11 @@
+package hyphenation
+
+import _ "embed"
+
+//go:embed patterns/hyph-it.pat.txt
+var itPatterns string
+
+// NewIt returns a hyphenator for Italian with the patterns hyph-it.pat.txt of the TeX hyph-utf8 project
+func NewIt() *Hyphenator {
+	return loadBundled(itPatterns)
+}
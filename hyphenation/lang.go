@@ -0,0 +1,54 @@
+package hyphenation
+
+import (
+	"strings"
+	"sync"
+)
+
+// bundled are the constructors of the hyphenators by language
+var bundled = map[string]func() *Hyphenator{
+	"en":    NewEnUs,
+	"en-us": NewEnUs,
+	"la":    NewLatin,
+	"de":    NewDe,
+	"fr":    NewFr,
+	"it":    NewIt,
+	"es":    NewEs,
+	"nl":    NewNl,
+}
+
+var (
+	bundledMx    sync.Mutex
+	bundledCache = map[string]*Hyphenator{}
+)
+
+// LangTags returns the normalized language tag lang followed by its primary language, e.g. "de-ch" and "de" for "de_CH"
+func LangTags(lang string) []string {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	if tag == "" {
+		return nil
+	}
+	tags := []string{tag}
+	if i := strings.IndexByte(tag, '-'); i > 0 {
+		tags = append(tags, tag[:i])
+	}
+	return tags
+}
+
+// ForLang returns the bundled hyphenator of the language lang like "de" or "fr-CH".
+// If there is none for the full tag, the one of the primary language is used.
+func ForLang(lang string) (*Hyphenator, bool) {
+	bundledMx.Lock()
+	defer bundledMx.Unlock()
+	for _, tag := range LangTags(lang) {
+		if h, ok := bundledCache[tag]; ok {
+			return h, true
+		}
+		if fnc, ok := bundled[tag]; ok {
+			h := fnc()
+			bundledCache[tag] = h
+			return h, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,11 @@
+package hyphenation
+
+import _ "embed"
+
+//go:embed patterns/hyph-nl.pat.txt
+var nlPatterns string
+
+// NewNl returns a hyphenator for Dutch with the patterns hyph-nl.pat.txt of the TeX hyph-utf8 project
+func NewNl() *Hyphenator {
+	return loadBundled(nlPatterns)
+}
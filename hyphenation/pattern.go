@@ -30,6 +30,19 @@ func loadPatternLookup(r io.Reader) (*patternLookup, error) {
 	return parsePatterns(r)
 }
 
+// add adds p to the patterns. The weights of a pattern with the same letters are merged by their maximum.
+func (pl *patternLookup) add(p pattern) {
+	key := string(p.Letters)
+	if prev, ok := pl.patterns[key]; ok {
+		for i, w := range prev.Weights {
+			if w > p.Weights[i] {
+				p.Weights[i] = w
+			}
+		}
+	}
+	pl.patterns[key] = p
+}
+
 func (pl *patternLookup) find(key string) (pattern, bool) {
 	p, ok := pl.patterns[key]
 	return p, ok
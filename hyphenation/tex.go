@@ -0,0 +1,112 @@
+package hyphenation
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// stripComments removes TeX comments, which start with a percent sign and end at the line end
+func stripComments(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if idx := strings.IndexByte(line, '%'); idx >= 0 {
+			lines[i] = line[:idx]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// texGroups returns the contents of the groups following the TeX command cmd like \patterns{...}
+func texGroups(s, cmd string) ([]string, error) {
+	var groups []string
+	for {
+		i := strings.Index(s, cmd)
+		if i < 0 {
+			return groups, nil
+		}
+		s = strings.TrimSpace(s[i+len(cmd):])
+		if !strings.HasPrefix(s, "{") {
+			return nil, errors.Errorf("%s without {", cmd)
+		}
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return nil, errors.Errorf("%s without }", cmd)
+		}
+		groups = append(groups, s[1:end])
+		s = s[end+1:]
+	}
+}
+
+// LoadTeX loads TeX hyphenation patterns like the hyph-*.pat.txt files of the hyph-utf8 project, where the patterns are
+// separated by whitespace and percent signs start comments. In TeX sources, the patterns are taken from \patterns{...}
+// and the exceptions from \hyphenation{...}.
+func LoadTeX(r io.Reader) (*Hyphenator, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "read-all")
+	}
+	s := stripComments(string(bs))
+	patterns := []string{s}
+	var exceptionGroups []string
+	if strings.Contains(s, `\patterns`) {
+		patterns, err = texGroups(s, `\patterns`)
+		if err != nil {
+			return nil, err
+		}
+		exceptionGroups, err = texGroups(s, `\hyphenation`)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pl := newPatternLookup()
+	for _, group := range patterns {
+		for _, f := range strings.Fields(group) {
+			p, err := parsePattern(f)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse-pattern %q", f)
+			}
+			pl.add(p)
+		}
+	}
+	h := new(pl)
+	for _, group := range exceptionGroups {
+		ex, err := parseExceptions(strings.NewReader(group))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse-exceptions")
+		}
+		for word, breaks := range ex {
+			h.exceptions[word] = breaks
+		}
+	}
+	return h, nil
+}
+
+// LoadTeXFiles loads the TeX patterns of patternFile and, if given, the exceptions of exceptionFile like hyph-*.hyp.txt
+func LoadTeXFiles(patternFile, exceptionFile string) (*Hyphenator, error) {
+	f, err := os.Open(patternFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open-file %q", patternFile)
+	}
+	defer f.Close()
+	h, err := LoadTeX(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load %q", patternFile)
+	}
+	if exceptionFile == "" {
+		return h, nil
+	}
+	return h.WithExceptionsFromFile(exceptionFile)
+}
+
+// loadBundled loads the TeX patterns, which are bundled with the package and therefore valid
+func loadBundled(patterns string) *Hyphenator {
+	h, err := LoadTeX(strings.NewReader(patterns))
+	if err != nil {
+		panic(errors.Wrap(err, "load bundled patterns"))
+	}
+	return h
+}
@@ -0,0 +1,98 @@
+package xpdf
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// imageDpi returns the resolution stored in the metadata of png (pHYs chunk) and jpeg (JFIF segment) images.
+// It returns zeros, if the resolution is not available.
+func imageDpi(data []byte, format string) (dpiX, dpiY float64) {
+	switch format {
+	case "png":
+		return pngDpi(data)
+	case "jpeg":
+		return jfifDpi(data)
+	default:
+		return 0, 0
+	}
+}
+
+func pngDpi(data []byte) (float64, float64) {
+	const signatureLen = 8
+	pos := signatureLen
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		typ := string(data[pos+4 : pos+8])
+		body := pos + 8
+		if length < 0 || body+length > len(data) {
+			break
+		}
+		switch typ {
+		case "pHYs":
+			if length < 9 {
+				return 0, 0
+			}
+			ppuX := binary.BigEndian.Uint32(data[body:])
+			ppuY := binary.BigEndian.Uint32(data[body+4:])
+			//unit 1 is pixels per meter, else only the aspect ratio is given
+			if data[body+8] != 1 {
+				return 0, 0
+			}
+			return float64(ppuX) * 0.0254, float64(ppuY) * 0.0254
+		case "IDAT", "IEND":
+			//pHYs must precede the image data
+			return 0, 0
+		}
+		//skip length, type, data and crc
+		pos = body + length + 4
+	}
+	return 0, 0
+}
+
+func jfifDpi(data []byte) (dpiX, dpiY float64) {
+	eachJPEGSegment(data, func(marker byte, seg []byte) bool {
+		if marker != 0xE0 || len(seg) < 12 || !bytes.HasPrefix(seg, []byte("JFIF\x00")) {
+			return true
+		}
+		units := seg[7]
+		x := float64(binary.BigEndian.Uint16(seg[8:]))
+		y := float64(binary.BigEndian.Uint16(seg[10:]))
+		switch units {
+		case 1:
+			dpiX, dpiY = x, y
+		case 2:
+			//dots per cm
+			dpiX, dpiY = x*2.54, y*2.54
+		}
+		return false
+	})
+	return
+}
+
+// eachJPEGSegment calls fn with the marker and the content of each segment before the image data, until fn returns false
+func eachJPEGSegment(data []byte, fn func(marker byte, seg []byte) bool) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return
+		}
+		marker := data[pos+1]
+		if marker == 0xDA {
+			//start of scan - no more metadata
+			return
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		body := pos + 4
+		if length < 2 || body+length-2 > len(data) {
+			return
+		}
+		if !fn(marker, data[body:body+length-2]) {
+			return
+		}
+		pos = body + length - 2
+	}
+}
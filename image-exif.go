@@ -0,0 +1,111 @@
+package xpdf
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// ExifOrientation is the orientation tag of the exif metadata. It tells, how the stored pixels must be transformed to display the image upright.
+type ExifOrientation int
+
+const (
+	OrientationNormal                   ExifOrientation = 1
+	OrientationFlipHorizontal           ExifOrientation = 2
+	OrientationRotate180                ExifOrientation = 3
+	OrientationFlipVertical             ExifOrientation = 4
+	OrientationTranspose                ExifOrientation = 5
+	OrientationRotate90Clockwise        ExifOrientation = 6
+	OrientationTransverse               ExifOrientation = 7
+	OrientationRotate90CounterClockwise ExifOrientation = 8
+)
+
+const (
+	exifTagOrientation = 0x0112
+	exifTypeShort      = 3
+)
+
+// SwapsAxes reports if width and height of the displayed image are the ones of the stored image swapped
+func (o ExifOrientation) SwapsAxes() bool {
+	return o >= OrientationTranspose && o <= OrientationRotate90CounterClockwise
+}
+
+// matrix returns the transformation, which maps the stored image placed at (0,0) with the stored size to the displayed box at (0,0)
+func (o ExifOrientation) matrix(storedWidth, storedHeight float64) matrix {
+	sw, sh := storedWidth, storedHeight
+	switch o {
+	case OrientationFlipHorizontal:
+		return matrix{a: -1, d: 1, e: sw}
+	case OrientationRotate180:
+		return matrix{a: -1, d: -1, e: sw, f: sh}
+	case OrientationFlipVertical:
+		return matrix{a: 1, d: -1, f: sh}
+	case OrientationTranspose:
+		return matrix{b: 1, c: 1}
+	case OrientationRotate90Clockwise:
+		return matrix{b: 1, c: -1, e: sh}
+	case OrientationTransverse:
+		return matrix{b: -1, c: -1, e: sh, f: sw}
+	case OrientationRotate90CounterClockwise:
+		return matrix{b: -1, c: 1, f: sw}
+	default:
+		return identity()
+	}
+}
+
+// exifOrientation returns the orientation from the exif metadata of a jpeg image or OrientationNormal, if there is none
+func exifOrientation(data []byte) ExifOrientation {
+	orientation := OrientationNormal
+	eachJPEGSegment(data, func(marker byte, seg []byte) bool {
+		if marker != 0xE1 || !bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return true
+		}
+		if o, ok := tiffOrientation(seg[6:]); ok {
+			orientation = o
+		}
+		return false
+	})
+	return orientation
+}
+
+// tiffOrientation reads the orientation tag of the first image file directory of the tiff structure inside the exif segment
+func tiffOrientation(tiff []byte) (ExifOrientation, bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0, false
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) != exifTagOrientation {
+			continue
+		}
+		if order.Uint16(tiff[entry+2:]) != exifTypeShort {
+			return 0, false
+		}
+		//a single short is stored left-aligned in the value field
+		o := ExifOrientation(order.Uint16(tiff[entry+8:]))
+		if o < OrientationNormal || o > OrientationRotate90CounterClockwise {
+			return 0, false
+		}
+		return o, true
+	}
+	return 0, false
+}
@@ -1,13 +1,20 @@
 package xpdf
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"image"
+	"math"
 	"os"
 
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 
+	"github.com/mazzegi/xpdf/resource"
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/svg"
 	"github.com/mazzegi/xpdf/xdoc"
 	"github.com/pkg/errors"
 )
@@ -18,54 +25,131 @@ type ImageDescriptor struct {
 	Format   string
 	WidthPx  int
 	HeightPx int
+	// resolution from the image metadata - zero, if unknown
+	DpiX float64
+	DpiY float64
+	// Orientation of the exif metadata. Width and height are the ones of the displayed image.
+	Orientation ExifOrientation
 }
 
-func (id ImageDescriptor) WidthMm(dpi int) float64 {
-	pxPerMm := float64(dpi) / 25.4
-	return float64(id.WidthPx) / pxPerMm
+// SizeMm returns the size of the image in mm. The resolution is dpi, if greater than zero, else the one of the image metadata or 96 DPI.
+// SVG images are always measured at 96 DPI.
+func (id ImageDescriptor) SizeMm(dpi float64) (width, height float64) {
+	dpiX, dpiY := float64(Dpi96), float64(Dpi96)
+	switch {
+	case id.Format == FormatSVG:
+	case dpi > 0:
+		dpiX, dpiY = dpi, dpi
+	case id.DpiX > 0 && id.DpiY > 0:
+		dpiX, dpiY = id.DpiX, id.DpiY
+	}
+	return float64(id.WidthPx) / dpiX * 25.4, float64(id.HeightPx) / dpiY * 25.4
 }
 
-func (id ImageDescriptor) HeightMm(dpi int) float64 {
-	pxPerMm := float64(dpi) / 25.4
-	return float64(id.HeightPx) / pxPerMm
-}
+const FormatSVG = "svg"
 
 func DescribeImage(src string) (ImageDescriptor, error) {
-	f, err := os.Open(src)
+	data, err := os.ReadFile(src)
 	if err != nil {
 		return ImageDescriptor{}, errors.Wrapf(err, "open image %q", src)
 	}
-	defer f.Close()
-	cfg, format, err := image.DecodeConfig(f)
+	desc, err := DescribeImageData(data)
+	if err != nil {
+		return ImageDescriptor{}, errors.Wrapf(err, "describe %q", src)
+	}
+	return desc, nil
+}
+
+// DescribeImageData returns the descriptor of the image content data
+func DescribeImageData(data []byte) (ImageDescriptor, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if !svg.Sniff(data) {
+			return ImageDescriptor{}, errors.Wrap(err, "decode config")
+		}
+		return describeSVG(data)
+	}
+	dpiX, dpiY := imageDpi(data, format)
+	desc := ImageDescriptor{
+		Format:      format,
+		WidthPx:     cfg.Width,
+		HeightPx:    cfg.Height,
+		DpiX:        dpiX,
+		DpiY:        dpiY,
+		Orientation: OrientationNormal,
+	}
+	if format == "jpeg" {
+		desc.Orientation = exifOrientation(data)
+	}
+	if desc.Orientation.SwapsAxes() {
+		desc.WidthPx, desc.HeightPx = desc.HeightPx, desc.WidthPx
+		desc.DpiX, desc.DpiY = desc.DpiY, desc.DpiX
+	}
+	return desc, nil
+}
+
+// describeSVG returns the descriptor of a svg image, where the size in pixels is given for 96 DPI
+func describeSVG(data []byte) (ImageDescriptor, error) {
+	img, err := svg.ParseBytes(data)
 	if err != nil {
-		return ImageDescriptor{}, errors.Wrapf(err, "decode config of %q", src)
+		return ImageDescriptor{}, errors.Wrap(err, "parse svg")
+	}
+	width, height := img.Size()
+	px := func(mm float64) int {
+		return int(math.Max(1, math.Round(mm/25.4*Dpi96)))
 	}
 	return ImageDescriptor{
-		Format:   format,
-		WidthPx:  cfg.Width,
-		HeightPx: cfg.Height,
+		Format:   FormatSVG,
+		WidthPx:  px(width),
+		HeightPx: px(height),
 	}, nil
 }
 
-func (p *Processor) renderImage(img *xdoc.Image, pa PrintableArea) {
-	imgSrc := p.resolveFile(img.Source)
+// imageName returns the name, under which the image of source with content data is registered with the engine.
+// Images of data URIs are named by the hash of their content instead of the whole URI.
+func imageName(source string, data []byte) string {
+	if !resource.IsDataURI(source) {
+		return source
+	}
+	sum := sha256.Sum256(data)
+	return "data:sha256," + hex.EncodeToString(sum[:])
+}
 
-	iDesc, err := DescribeImage(imgSrc)
+// putImage puts the image with name and content data into the box. SVG images are drawn with vector primitives, where text uses the font of sty.
+// Images with an exif orientation other than normal are transformed to be displayed upright.
+func (p *Processor) putImage(name string, data []byte, desc ImageDescriptor, x, y, width, height float64, sty style.Styles) {
+	if desc.Format != FormatSVG {
+		name = imageName(name, data)
+		if desc.Orientation <= OrientationNormal {
+			p.engine.PutImage(name, data, desc.Format, x, y, width, height)
+			return
+		}
+		//the box of the stored image, before it's transformed into the displayed box
+		storedWidth, storedHeight := width, height
+		if desc.Orientation.SwapsAxes() {
+			storedWidth, storedHeight = height, width
+		}
+		p.transformed(desc.Orientation.matrix(storedWidth, storedHeight).translated(x, y), func() {
+			p.engine.PutImage(name, data, desc.Format, 0, 0, storedWidth, storedHeight)
+		})
+		return
+	}
+	img, err := svg.ParseBytes(data)
 	if err != nil {
-		Logf("ERROR: describe image: %v", err)
+		Logf("ERROR: parse svg: %v", err)
 		return
 	}
-	sty := img.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
-	x, y := p.engine.GetXY()
-	x += sty.OffsetX
-	y += sty.OffsetY
+	img.Draw(p.engine, x, y, width, height, sty.Font)
+	p.engine.ChangeFont(sty.Font)
+	p.engine.SetTextColor(sty.Text.Values())
+}
+
+// imageBox returns the size of the box reserved for an image with styles sty in pa and the size of its bounding box,
+// which the box occupies, when it is rotated. Both are scaled down to fit into pa.
+func imageBox(sty style.Styles, desc ImageDescriptor, pa PrintableArea) (width, height, boxWidth, boxHeight float64) {
 	paWidth := pa.Width() - sty.OffsetX
 	paHeight := pa.Height() - sty.OffsetY
-
-	idWidth := iDesc.WidthMm(Dpi96)
-	idHeight := iDesc.HeightMm(Dpi96)
-
-	var width, height float64
+	idWidth, idHeight := desc.SizeMm(sty.Image.Dpi)
 	switch {
 	case sty.Width > 0 && sty.Height > 0:
 		width, height = sty.Width, sty.Height
@@ -79,16 +163,143 @@ func (p *Processor) renderImage(img *xdoc.Image, pa PrintableArea) {
 		width, height = float64(idWidth), float64(idHeight)
 	}
 
+	//the rotated image occupies the bounding box of the rotated box
+	boxWidth, boxHeight = rotatedSize(sty.Rotate, width, height)
+
 	//scale to printable area
-	//TODO: probably width and height are in millimeters here !!!
-	if width > paWidth {
-		height = height * paWidth / width
-		width = paWidth
+	scale := 1.0
+	if boxWidth > paWidth {
+		scale = paWidth / boxWidth
+	}
+	if boxHeight*scale > paHeight {
+		scale = paHeight / boxHeight
+	}
+	return width * scale, height * scale, boxWidth * scale, boxHeight * scale
+}
+
+// imageHeight returns the height below the current position, which an image with styles sty and a bounding box of boxHeight needs
+func imageHeight(sty style.Styles, boxHeight float64) float64 {
+	//floating images start below their top margin
+	if sty.Float == style.FloatLeft || sty.Float == style.FloatRight {
+		return boxHeight + sty.OffsetY + sty.Margin.Top
+	}
+	return boxHeight + sty.OffsetY
+}
+
+// imageBlockHeight returns the height of img in pa, if its source can be loaded
+func (p *Processor) imageBlockHeight(img *xdoc.Image, pa PrintableArea) (float64, bool) {
+	data, err := p.resource(img.Source)
+	if err != nil {
+		return 0, false
+	}
+	desc, err := DescribeImageData(data)
+	if err != nil {
+		return 0, false
+	}
+	sty := img.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+	_, _, _, boxHeight := imageBox(sty, desc, pa)
+	return imageHeight(sty, boxHeight), true
+}
+
+func (p *Processor) renderImage(img *xdoc.Image, pa PrintableArea) {
+	data, err := p.resource(img.Source)
+	if err != nil {
+		Logf("ERROR: load image: %v", err)
+		return
+	}
+	iDesc, err := DescribeImageData(data)
+	if err != nil {
+		Logf("ERROR: describe image: %v", err)
+		return
 	}
-	if height > paHeight {
-		width = width * paHeight / height
-		height = paHeight
+	sty := img.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+	xStart, yStart := p.engine.GetXY()
+	x := xStart + sty.OffsetX
+	y := yStart + sty.OffsetY
+	idWidth, idHeight := iDesc.SizeMm(sty.Image.Dpi)
+	width, height, boxWidth, boxHeight := imageBox(sty, iDesc, pa)
+
+	floating := sty.Float == style.FloatLeft || sty.Float == style.FloatRight
+	if !p.preventPageBreak && !p.fitsOnPage(imageHeight(sty, boxHeight)) && !p.atPageTop() {
+		xStart = p.newPageAt(xStart)
+		_, yStart = p.engine.GetXY()
+		x = xStart + sty.OffsetX
+		y = yStart + sty.OffsetY
+		pa = p.page().printableArea
+	}
+	if floating {
+		outerWidth := boxWidth + sty.Margin.Left + sty.Margin.Right
+		outerHeight := boxHeight + sty.Margin.Top + sty.Margin.Bottom
+		x = p.floatPosition(pa, sty.Float, y, outerWidth, outerHeight) + sty.Margin.Left + sty.OffsetX
+		y += sty.Margin.Top
+		p.addFloat(PrintableArea{
+			x0: x - sty.Margin.Left,
+			y0: y - sty.Margin.Top,
+			x1: x + boxWidth + sty.Margin.Right,
+			y1: y + boxHeight + sty.Margin.Bottom,
+		}, sty.Float)
+	}
+
+	draw := func(x, y float64) {
+		imgWidth, imgHeight := fitImage(sty.ObjectFit, width, height, idWidth, idHeight)
+		dx, dy := alignInBox(sty.HAlign, sty.VAlign, width, height, imgWidth, imgHeight)
+		clip := imgWidth > width+0.001 || imgHeight > height+0.001
+		if clip {
+			p.engine.ClipRect(x, y, width, height)
+		}
+		p.putImage(img.Source, data, iDesc, x+dx, y+dy, imgWidth, imgHeight, sty)
+		if clip {
+			p.engine.ClipEnd()
+		}
+	}
+	if sty.Rotate == 0 {
+		draw(x, y)
+	} else {
+		//rotate the image box around the center of its bounding box
+		cx, cy := x+boxWidth/2, y+boxHeight/2
+		p.transformed(rotation(sty.Rotate, cx, cy), func() {
+			draw(cx-width/2, cy-height/2)
+		})
+	}
+
+	if !floating {
+		p.engine.SetX(xStart)
+		p.engine.SetY(y + boxHeight)
 	}
+}
+
+// fitImage returns the size of an image with the given intrinsic size in a box according to fit
+func fitImage(fit style.ObjectFit, boxWidth, boxHeight, width, height float64) (float64, float64) {
+	if width <= 0 || height <= 0 {
+		return boxWidth, boxHeight
+	}
+	switch fit {
+	case style.ObjectFitContain:
+		s := math.Min(boxWidth/width, boxHeight/height)
+		return width * s, height * s
+	case style.ObjectFitCover:
+		s := math.Max(boxWidth/width, boxHeight/height)
+		return width * s, height * s
+	case style.ObjectFitNone:
+		return width, height
+	default:
+		return boxWidth, boxHeight
+	}
+}
 
-	p.engine.PutImage(imgSrc, x, y, width, height)
+// alignInBox returns the offset of an element with width and height inside a box
+func alignInBox(hAlign style.HAlign, vAlign style.VAlign, boxWidth, boxHeight, width, height float64) (dx, dy float64) {
+	switch hAlign {
+	case style.HAlignCenter:
+		dx = (boxWidth - width) / 2
+	case style.HAlignRight:
+		dx = boxWidth - width
+	}
+	switch vAlign {
+	case style.VAlignMiddle:
+		dy = (boxHeight - height) / 2
+	case style.VAlignBottom:
+		dy = boxHeight - height
+	}
+	return
 }
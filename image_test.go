@@ -0,0 +1,239 @@
+package xpdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"testing"
+
+	"github.com/mazzegi/xpdf/resource"
+	"github.com/mazzegi/xpdf/style"
+)
+
+func pngChunk(typ string, data []byte) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+	return buf.Bytes()
+}
+
+func testPNG(phys []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	buf.Write(pngChunk("IHDR", []byte{0, 0, 0, 1, 0, 0, 0, 1, 8, 2, 0, 0, 0}))
+	if phys != nil {
+		buf.Write(pngChunk("pHYs", phys))
+	}
+	buf.Write(pngChunk("IDAT", nil))
+	buf.Write(pngChunk("IEND", nil))
+	return buf.Bytes()
+}
+
+func testJPEG(units byte, x, y uint16) []byte {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 16})
+	buf.WriteString("JFIF\x00")
+	buf.Write([]byte{1, 1, units})
+	binary.Write(buf, binary.BigEndian, x)
+	binary.Write(buf, binary.BigEndian, y)
+	buf.Write([]byte{0, 0})
+	buf.Write([]byte{0xFF, 0xDA, 0, 2})
+	return buf.Bytes()
+}
+
+func TestImageDpi(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		dpiX   float64
+		dpiY   float64
+	}{
+		{name: "png 300 dpi", data: testPNG([]byte{0, 0, 0x2E, 0x23, 0, 0, 0x2E, 0x23, 1}), format: "png", dpiX: 299.9994, dpiY: 299.9994},
+		{name: "png aspect only", data: testPNG([]byte{0, 0, 0, 1, 0, 0, 0, 1, 0}), format: "png"},
+		{name: "png without phys", data: testPNG(nil), format: "png"},
+		{name: "jpeg dpi", data: testJPEG(1, 72, 144), format: "jpeg", dpiX: 72, dpiY: 144},
+		{name: "jpeg dpcm", data: testJPEG(2, 100, 100), format: "jpeg", dpiX: 254, dpiY: 254},
+		{name: "jpeg aspect only", data: testJPEG(0, 1, 1), format: "jpeg"},
+		{name: "truncated", data: []byte{0xFF, 0xD8, 0xFF}, format: "jpeg"},
+		{name: "gif", data: []byte("GIF89a"), format: "gif"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, y := imageDpi(test.data, test.format)
+			if math.Abs(x-test.dpiX) > 0.001 || math.Abs(y-test.dpiY) > 0.001 {
+				t.Fatalf("have %f x %f, want %f x %f", x, y, test.dpiX, test.dpiY)
+			}
+		})
+	}
+}
+
+func TestImageSizeMm(t *testing.T) {
+	tests := []struct {
+		desc   ImageDescriptor
+		dpi    float64
+		width  float64
+		height float64
+	}{
+		{desc: ImageDescriptor{Format: "png", WidthPx: 96, HeightPx: 192}, width: 25.4, height: 50.8},
+		{desc: ImageDescriptor{Format: "png", WidthPx: 300, HeightPx: 600, DpiX: 300, DpiY: 300}, width: 25.4, height: 50.8},
+		{desc: ImageDescriptor{Format: "png", WidthPx: 300, HeightPx: 600, DpiX: 300, DpiY: 300}, dpi: 150, width: 50.8, height: 101.6},
+		{desc: ImageDescriptor{Format: FormatSVG, WidthPx: 96, HeightPx: 96}, dpi: 300, width: 25.4, height: 25.4},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			w, h := test.desc.SizeMm(test.dpi)
+			if math.Abs(w-test.width) > 1e-9 || math.Abs(h-test.height) > 1e-9 {
+				t.Fatalf("have %f x %f, want %f x %f", w, h, test.width, test.height)
+			}
+		})
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	tests := []struct {
+		fit           style.ObjectFit
+		hAlign        style.HAlign
+		vAlign        style.VAlign
+		width, height float64
+		dx, dy        float64
+	}{
+		{fit: style.ObjectFitFill, width: 40, height: 20},
+		{fit: style.ObjectFitContain, width: 20, height: 20},
+		{fit: style.ObjectFitContain, hAlign: style.HAlignCenter, width: 20, height: 20, dx: 10},
+		{fit: style.ObjectFitContain, hAlign: style.HAlignRight, width: 20, height: 20, dx: 20},
+		{fit: style.ObjectFitCover, width: 40, height: 40},
+		{fit: style.ObjectFitCover, vAlign: style.VAlignMiddle, width: 40, height: 40, dy: -10},
+		{fit: style.ObjectFitNone, vAlign: style.VAlignBottom, hAlign: style.HAlignCenter, width: 10, height: 10, dx: 15, dy: 10},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d %s", i+1, test.fit), func(t *testing.T) {
+			//a 10x10 image in a 40x20 box
+			w, h := fitImage(test.fit, 40, 20, 10, 10)
+			dx, dy := alignInBox(test.hAlign, test.vAlign, 40, 20, w, h)
+			if w != test.width || h != test.height || dx != test.dx || dy != test.dy {
+				t.Fatalf("have %gx%g at %g,%g, want %gx%g at %g,%g", w, h, dx, dy, test.width, test.height, test.dx, test.dy)
+			}
+		})
+	}
+}
+
+func testExifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := &bytes.Buffer{}
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	binary.Write(tiff, order, uint16(42))
+	binary.Write(tiff, order, uint32(8))
+	//two entries: image width and orientation
+	binary.Write(tiff, order, uint16(2))
+	binary.Write(tiff, order, []uint16{0x0100, 3})
+	binary.Write(tiff, order, uint32(1))
+	binary.Write(tiff, order, []uint16{640, 0})
+	binary.Write(tiff, order, []uint16{0x0112, 3})
+	binary.Write(tiff, order, uint32(1))
+	binary.Write(tiff, order, []uint16{orientation, 0})
+	binary.Write(tiff, order, uint32(0))
+
+	seg := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0xFF, 0xD8, 0xFF, 0xE1})
+	binary.Write(buf, binary.BigEndian, uint16(len(seg)+2))
+	buf.Write(seg)
+	buf.Write([]byte{0xFF, 0xDA, 0, 2})
+	return buf.Bytes()
+}
+
+func TestExifOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		out  ExifOrientation
+	}{
+		{name: "little endian", data: testExifJPEG(binary.LittleEndian, 6), out: OrientationRotate90Clockwise},
+		{name: "big endian", data: testExifJPEG(binary.BigEndian, 3), out: OrientationRotate180},
+		{name: "invalid value", data: testExifJPEG(binary.BigEndian, 9), out: OrientationNormal},
+		{name: "jfif only", data: testJPEG(1, 72, 72), out: OrientationNormal},
+		{name: "truncated", data: testExifJPEG(binary.BigEndian, 8)[:20], out: OrientationNormal},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if have := exifOrientation(test.data); have != test.out {
+				t.Fatalf("have %d, want %d", have, test.out)
+			}
+		})
+	}
+}
+
+func TestOrientationMatrix(t *testing.T) {
+	//the corners (0,0) and (w,0) of a stored 40x20 image must end up at these points of the displayed box
+	tests := []struct {
+		orientation   ExifOrientation
+		origin, xAxis [2]float64
+	}{
+		{orientation: OrientationNormal, origin: [2]float64{0, 0}, xAxis: [2]float64{40, 0}},
+		{orientation: OrientationFlipHorizontal, origin: [2]float64{40, 0}, xAxis: [2]float64{0, 0}},
+		{orientation: OrientationRotate180, origin: [2]float64{40, 20}, xAxis: [2]float64{0, 20}},
+		{orientation: OrientationFlipVertical, origin: [2]float64{0, 20}, xAxis: [2]float64{40, 20}},
+		{orientation: OrientationTranspose, origin: [2]float64{0, 0}, xAxis: [2]float64{0, 40}},
+		{orientation: OrientationRotate90Clockwise, origin: [2]float64{20, 0}, xAxis: [2]float64{20, 40}},
+		{orientation: OrientationTransverse, origin: [2]float64{20, 40}, xAxis: [2]float64{20, 0}},
+		{orientation: OrientationRotate90CounterClockwise, origin: [2]float64{0, 40}, xAxis: [2]float64{0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d", test.orientation), func(t *testing.T) {
+			m := test.orientation.matrix(40, 20)
+			x0, y0 := m.apply(0, 0)
+			x1, y1 := m.apply(40, 0)
+			have := [4]float64{x0, y0, x1, y1}
+			want := [4]float64{test.origin[0], test.origin[1], test.xAxis[0], test.xAxis[1]}
+			if have != want {
+				t.Fatalf("have %v, want %v", have, want)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	m := rotation(90, 10, 10)
+	x, y := m.apply(20, 10)
+	if math.Abs(x-10) > 1e-9 || math.Abs(y-20) > 1e-9 {
+		t.Fatalf("have %f,%f, want 10,20", x, y)
+	}
+	w, h := rotatedSize(90, 40, 20)
+	if math.Abs(w-20) > 1e-9 || math.Abs(h-40) > 1e-9 {
+		t.Fatalf("have %fx%f, want 20x40", w, h)
+	}
+}
+
+func TestImageName(t *testing.T) {
+	uri := testImageURI(t)
+	e := processTestDoc(t, "", `<image style="width: 10">`+uri+`</image><text>icon <img>`+uri+`</img></text>`+
+		`<image style="width: 10">`+testImageURI(t)+` </image>`)
+	if len(e.images) != 3 {
+		t.Fatalf("have %d images, want 3", len(e.images))
+	}
+	//the same data is registered once under the hash of its content
+	data, err := resource.DecodeDataURI(uri)
+	if err != nil {
+		t.Fatalf("decode data uri: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	exp := "data:sha256," + hex.EncodeToString(sum[:])
+	for _, img := range e.images {
+		if img.name != exp {
+			t.Fatalf("have image name %q, want %q", img.name, exp)
+		}
+	}
+	if have := imageName("logo.png", data); have != "logo.png" {
+		t.Fatalf("have image name %q, want the file name", have)
+	}
+}
@@ -0,0 +1,304 @@
+package xpdf
+
+import (
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+// linesOnPage returns how many of n lines are put on a page, which has space for avail lines.
+// A page break never leaves less than orphans lines at the bottom of a page and less than widows lines
+// at the top of the next page. If the page is empty, it takes at least one line, even if the constraints cannot be satisfied.
+func linesOnPage(n, avail, orphans, widows int, empty bool) int {
+	if n <= avail {
+		return n
+	}
+	take := avail
+	if n-take < widows {
+		take = n - widows
+	}
+	if take < orphans {
+		take = 0
+	}
+	if take <= 0 && empty {
+		take = avail
+		if take < 1 {
+			take = 1
+		}
+	}
+	return take
+}
+
+// lineExtent is the space a line needs beyond the regular line height, e.g. for inline images exceeding the line
+type lineExtent struct {
+	above, below float64
+}
+
+// lineExtents returns the extents of the text lines
+func lineExtents(lines []textLine) []lineExtent {
+	extents := make([]lineExtent, len(lines))
+	for i, l := range lines {
+		extents[i] = lineExtent{above: l.spaceAbove(), below: l.below}
+	}
+	return extents
+}
+
+// linesFitting returns the number of lines with the given extents fitting into the vertical space from y to y1.
+func linesFitting(y, y1, fontHeight, lineHeight float64, extents []lineExtent) int {
+	n := 0
+	for _, ext := range extents {
+		if y+ext.above+fontHeight+ext.below > y1 {
+			break
+		}
+		n++
+		y += ext.above + lineHeight + ext.below
+	}
+	return n
+}
+
+// lineBreaks returns a function, which reports if a page break has to be inserted before line idx
+// of the text lines. After a page break, it has to be asked again for the same line.
+func (p *Processor) lineBreaks(lines []textLine, lineHeight float64, sty style.Styles) func(idx int) bool {
+	fontHeight := p.engine.FontHeight()
+	extents := lineExtents(lines)
+	next := 0
+	return func(idx int) bool {
+		if p.preventPageBreak {
+			return false
+		}
+		_, y := p.engine.GetXY()
+		avail := linesFitting(y, p.page().printableArea.y1, fontHeight, lineHeight, extents[idx:])
+		//footnotes may have shrunk the page since the lines were taken
+		if idx < next && avail > 0 {
+			return false
+		}
+		take := linesOnPage(len(lines)-idx, avail, sty.Orphans, sty.Widows, p.atPageTop())
+		if take == 0 {
+			return true
+		}
+		next = idx + take
+		return false
+	}
+}
+
+// keepPageBreakPrevention prevents page breaks until the returned function is called
+func (p *Processor) keepPageBreakPrevention() func() {
+	prev := p.preventPageBreak
+	p.preventPageBreak = true
+	return func() {
+		p.preventPageBreak = prev
+	}
+}
+
+// newPage continues the document flow on a new page - or in the next column, if the flow is in columns
+func (p *Processor) newPage() {
+	p.floats = nil
+	if p.columns != nil && p.nextColumn() {
+		return
+	}
+	p.renderFootnotes()
+	p.placeDeferredFootnotes()
+	p.engine.AddPage()
+	if p.columns != nil {
+		p.newColumnsPage()
+	}
+}
+
+// newPageAt continues the flow like newPage and returns x translated into the new printable area
+func (p *Processor) newPageAt(x float64) float64 {
+	x0 := p.page().printableArea.x0
+	p.newPage()
+	return x + p.page().printableArea.x0 - x0
+}
+
+// fitsOnPage reports if height fits below the current position
+func (p *Processor) fitsOnPage(height float64) bool {
+	_, y := p.engine.GetXY()
+	return y+height <= p.page().printableArea.y1
+}
+
+// fitsOnEmptyPage reports if height fits on an empty page
+func (p *Processor) fitsOnEmptyPage(height float64) bool {
+	return height <= p.page().printableArea.Height()
+}
+
+// keepTogether starts a new page, if a block of height with styles sty is to be kept together, but only fits on an empty page.
+// It reports if it started a new page.
+func (p *Processor) keepTogether(sty style.Styles, height float64) bool {
+	if !sty.KeepTogether || p.preventPageBreak || p.fitsOnPage(height) || !p.fitsOnEmptyPage(height) {
+		return false
+	}
+	p.newPage()
+	return true
+}
+
+// blockHeight returns the height of a block instruction, if it can be measured in advance
+func (p *Processor) blockHeight(i xdoc.Instruction) (float64, bool) {
+	switch i := i.(type) {
+	case *xdoc.Box:
+		sty := i.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+		return p.textBoxHeight(i, p.page().printableArea) + sty.Padding.Top + sty.Padding.Bottom + sty.OffsetY, true
+	case *xdoc.Text:
+		if len(i.ISS) == 0 {
+			return 0, true
+		}
+		defer p.resetStyles()
+		sty := i.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+		width := p.page().EffectiveWidth(sty.Width)
+		return p.textHeightFnc(sty)(i.ISS, width, sty), true
+	case *xdoc.LineFeed:
+		return p.engine.FontHeight() * i.Lines, true
+	case *xdoc.Barcode:
+		sty := i.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+		l, err := p.layoutBarcode(i, sty, p.page().printableArea.Width()-sty.OffsetX)
+		if err != nil {
+			return 0, false
+		}
+		return l.rotatedH + sty.OffsetY, true
+	case *xdoc.Chart:
+		sty := i.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+		return chartHeight(sty, chartWidth(sty, p.page().printableArea)) + sty.OffsetY, true
+	case *xdoc.Pre:
+		return p.preBlockHeight(i, p.page().printableArea), true
+	case *xdoc.Table:
+		defer p.resetStyles()
+		return p.transformTable(i).height(), true
+	case *xdoc.Image:
+		return p.imageBlockHeight(i, p.page().printableArea)
+	}
+	return 0, false
+}
+
+// leadHeight returns the height of the beginning of a block instruction, which must not be separated from its predecessor,
+// if this one is to be kept with its next.
+func (p *Processor) leadHeight(i xdoc.Instruction) (float64, bool) {
+	switch i := i.(type) {
+	case *xdoc.Text:
+		if len(i.ISS) == 0 {
+			return 0, true
+		}
+		defer p.resetStyles()
+		sty := i.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+		width := p.page().EffectiveWidth(sty.Width)
+		p.engine.ChangeFont(sty.Font)
+		lines := len(p.textLinesFnc(sty)(i.ISS, fixedSpan(width), sty))
+		if lines > sty.Orphans && sty.Orphans > 0 {
+			lines = sty.Orphans
+		}
+		if lines == 0 {
+			return 0, true
+		}
+		p.engine.ChangeFont(sty.Font)
+		lineHeight := p.engine.FontHeight() * sty.LineSpacing
+		return float64(lines-1)*lineHeight + p.engine.FontHeight(), true
+	case *xdoc.Table:
+		//the table starts on a new page with its first row after the repeated header
+		defer p.resetStyles()
+		return p.transformTable(i).headHeight(i.RepeatHeader), true
+	default:
+		return p.blockHeight(i)
+	}
+}
+
+// keepWithNext inserts a page break before is[idx], if it is to be kept with its successor, but both don't fit on the current page.
+func (p *Processor) keepWithNext(is []xdoc.Instruction, idx int) {
+	if p.preventPageBreak {
+		return
+	}
+	var next xdoc.Instruction
+	var lineFeeds []xdoc.Instruction
+	for _, i := range is[idx+1:] {
+		switch i.(type) {
+		case *xdoc.Font, *xdoc.SetX, *xdoc.TextBlock:
+			continue
+		case *xdoc.LineFeed:
+			lineFeeds = append(lineFeeds, i)
+			continue
+		}
+		next = i
+		break
+	}
+	sty := is[idx].MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+	keep := sty.KeepWithNext || sty.PageBreakAfter == style.PageBreakAvoid
+	if next != nil {
+		nextSty := next.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
+		keep = keep || nextSty.PageBreakBefore == style.PageBreakAvoid
+	}
+	if !keep {
+		return
+	}
+	//blocks are only measured, if a keep rule applies
+	height, ok := p.blockHeight(is[idx])
+	if !ok {
+		return
+	}
+	for _, lf := range lineFeeds {
+		h, _ := p.blockHeight(lf)
+		height += h
+	}
+	if next != nil {
+		if h, ok := p.leadHeight(next); ok {
+			height += h
+		}
+	}
+	if !p.fitsOnPage(height) && p.fitsOnEmptyPage(height) {
+		p.newPage()
+	}
+}
+
+// isBlock reports if the instruction is a block, which is subject to page-break styles
+func isBlock(i xdoc.Instruction) bool {
+	switch i.(type) {
+	case *xdoc.Box, *xdoc.Text, *xdoc.Table, *xdoc.Image, *xdoc.Barcode, *xdoc.Chart, *xdoc.Pre, *xdoc.Grid, *xdoc.Columns:
+		return true
+	default:
+		return false
+	}
+}
+
+// atPageTop reports if the current position is at the top of the printable area
+func (p *Processor) atPageTop() bool {
+	_, y := p.engine.GetXY()
+	return y <= p.page().printableArea.y0
+}
+
+// pageBreakBefore applies the page-break-before style of i together with a pending page-break-after of its predecessor
+func (p *Processor) pageBreakBefore(i xdoc.Instruction) {
+	if p.preventPageBreak {
+		return
+	}
+	brk := i.MutatedStyles(p.doc.StyleClasses(), p.currStyles).PageBreakBefore
+	if !brk.Forced() || brk == style.PageBreakAlways {
+		if p.pendingPageBreak.Forced() {
+			brk = p.pendingPageBreak
+		}
+	}
+	p.pendingPageBreak = style.PageBreakAuto
+	p.forcePageBreak(brk)
+}
+
+// pageBreakAfter remembers the page-break-after style of i. The break is done, as soon as a following block is rendered.
+func (p *Processor) pageBreakAfter(i xdoc.Instruction) {
+	if p.preventPageBreak {
+		return
+	}
+	p.pendingPageBreak = i.MutatedStyles(p.doc.StyleClasses(), p.currStyles).PageBreakAfter
+}
+
+// forcePageBreak starts a new page for forced page breaks. For left and right breaks, a blank page is inserted if necessary,
+// so that the flow continues on an even (left) or odd (right) page. Inside columns, always continues in the next column,
+// while left and right continue in the first column of a new page.
+func (p *Processor) forcePageBreak(brk style.PageBreak) {
+	if !brk.Forced() {
+		return
+	}
+	if !p.atPageTop() {
+		p.newPage()
+	}
+	for brk != style.PageBreakAlways && p.columns != nil && p.columns.idx > 0 {
+		p.newPage()
+	}
+	for (brk == style.PageBreakRight && p.engine.CurrentPage()%2 == 0) ||
+		(brk == style.PageBreakLeft && p.engine.CurrentPage()%2 == 1) {
+		p.newPage()
+	}
+}
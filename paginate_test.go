@@ -0,0 +1,173 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+func TestLinesOnPage(t *testing.T) {
+	tests := []struct {
+		n, avail        int
+		orphans, widows int
+		empty           bool
+		exp             int
+	}{
+		{n: 5, avail: 10, orphans: 2, widows: 2, exp: 5},
+		{n: 10, avail: 4, orphans: 2, widows: 2, exp: 4},
+		{n: 10, avail: 1, orphans: 2, widows: 2, exp: 0},
+		{n: 5, avail: 4, orphans: 2, widows: 2, exp: 3},
+		{n: 3, avail: 2, orphans: 2, widows: 2, exp: 0},
+		{n: 10, avail: 0, orphans: 2, widows: 2, exp: 0},
+		{n: 10, avail: 4, orphans: 0, widows: 0, exp: 4},
+		{n: 10, avail: 3, orphans: 4, widows: 4, empty: true, exp: 3},
+		{n: 10, avail: 0, orphans: 2, widows: 2, empty: true, exp: 1},
+		{n: 5, avail: 4, orphans: 4, widows: 2, empty: true, exp: 4},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := linesOnPage(test.n, test.avail, test.orphans, test.widows, test.empty)
+			if have != test.exp {
+				t.Fatalf("have %d, want %d", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestLinesFitting(t *testing.T) {
+	tests := []struct {
+		y, y1, fontHeight, lineHeight float64
+		extents                       []lineExtent
+		exp                           int
+	}{
+		{y: 0, y1: 10, fontHeight: 4, lineHeight: 6, extents: make([]lineExtent, 10), exp: 2},
+		{y: 0, y1: 9.9, fontHeight: 4, lineHeight: 6, extents: make([]lineExtent, 10), exp: 1},
+		{y: 8, y1: 10, fontHeight: 4, lineHeight: 6, extents: make([]lineExtent, 10), exp: 0},
+		{y: 0, y1: 100, fontHeight: 4, lineHeight: 4, extents: make([]lineExtent, 30), exp: 25},
+		{y: 0, y1: 100, fontHeight: 4, lineHeight: 4, extents: make([]lineExtent, 3), exp: 3},
+		//a tall inline image in the second line
+		{y: 0, y1: 20, fontHeight: 4, lineHeight: 6, extents: []lineExtent{{}, {above: 12}, {}}, exp: 1},
+		{y: 0, y1: 20, fontHeight: 4, lineHeight: 6, extents: []lineExtent{{}, {above: 4, below: 2}, {}}, exp: 2},
+		{y: 0, y1: 10, fontHeight: 4, lineHeight: 6, extents: []lineExtent{{above: 7}}, exp: 0},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := linesFitting(test.y, test.y1, test.fontHeight, test.lineHeight, test.extents)
+			if have != test.exp {
+				t.Fatalf("have %d, want %d", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestForcePageBreak(t *testing.T) {
+	tests := []struct {
+		body string
+		// page and column, where the text "target" starts
+		page   int
+		column int
+	}{
+		{body: `<text>a</text><text style="page-break-before: always">target</text>`, page: 2},
+		{body: `<text style="page-break-after: always">a</text><text>target</text>`, page: 2},
+		{body: `<text>a</text><text style="page-break-before: avoid">target</text>`, page: 1},
+		//a blank page is inserted to continue on an odd page
+		{body: `<text>a</text><text style="page-break-before: right">target</text>`, page: 3},
+		{body: `<text>a</text><text style="page-break-before: left">target</text>`, page: 2},
+		{body: `<text style="page-break-before: right">target</text>`, page: 1},
+		{body: `<text style="page-break-before: left">target</text>`, page: 2},
+		{body: `<text style="page-break-after: right">a</text><text style="page-break-before: always">target</text>`, page: 3},
+		//in columns, always continues in the next column
+		{body: `<columns count="2" gap="10"><text>a</text><text style="page-break-before: always">target</text></columns>`, page: 1, column: 1},
+		{body: `<columns count="2" gap="10"><text>a</text><text style="page-break-before: always">b</text>` +
+			`<text style="page-break-before: always">target</text></columns>`, page: 2},
+		{body: `<columns count="2" gap="10"><text>a</text><text style="page-break-before: right">target</text></columns>`, page: 3},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			e := processTestDoc(t, "", test.body)
+			have := e.find(t, "target")
+			//the columns of an A4 page with margins of 20mm and a gap of 10mm are 80mm wide
+			column := int(math.Round((have.x - 20) / 90))
+			if have.page != test.page || column != test.column {
+				t.Fatalf("have page %d, column %d, want page %d, column %d", have.page, column, test.page, test.column)
+			}
+		})
+	}
+}
+
+func TestKeepBlocks(t *testing.T) {
+	img := `<image style="height: 40">` + testImageURI(t) + `</image>`
+	row := `<tr><td style="height: 30">row</td></tr>`
+	pre := "<pre style=\"keep-together: true\">" + strings.Repeat("row\n", 10) + "</pre>"
+	tests := []struct {
+		body string
+		// pages, where the texts "heading" and "row" start
+		heading, row int
+	}{
+		//the heading is kept with the image or table, which doesn't fit below it
+		{body: `<sety y="240"/><text style="keep-with-next: true">heading</text>` + img + `<text>row</text>`, heading: 2, row: 2},
+		{body: `<sety y="250"/><text style="page-break-after: avoid">heading</text><table>` + row + `</table>`, heading: 2, row: 2},
+		{body: `<sety y="250"/><text>heading</text><table style="page-break-before: avoid">` + row + `</table>`, heading: 2, row: 2},
+		{body: `<sety y="240"/><text>heading</text>` + img + `<text>row</text>`, heading: 1, row: 2},
+		//blocks kept together start on a new page, if they don't fit on the current one
+		{body: `<sety y="200"/><text>heading</text><table style="keep-together: true">` + row + row + row + `</table>`, heading: 1, row: 2},
+		{body: `<sety y="200"/><text>heading</text><table>` + row + row + row + `</table>`, heading: 1, row: 1},
+		{body: `<sety y="250"/><text>heading</text>` + pre, heading: 1, row: 2},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			e := processTestDoc(t, "", test.body)
+			heading, row := e.find(t, "heading"), e.find(t, "row")
+			if heading.page != test.heading || row.page != test.row {
+				t.Fatalf("have heading on page %d and row on page %d, want %d and %d", heading.page, row.page, test.heading, test.row)
+			}
+		})
+	}
+}
+
+func TestInvalidPageBreak(t *testing.T) {
+	if _, err := xdoc.Load(strings.NewReader(`<document><body><text style="page-break-before: page">a</text></body></document>`)); err == nil {
+		t.Fatalf("expected error for invalid page-break-before")
+	}
+}
+
+func TestInlineImagePageBreak(t *testing.T) {
+	img := `<img style="height: 20">` + testImageURI(t) + `</img>`
+	//the second line is raised by the image, so that it doesn't fit above the bottom margin at 277mm
+	e := processTestDoc(t, "", `<sety y="252"/><text style="orphans: 1; widows: 1">first line<br/>icon `+img+` line</text>`)
+	if len(e.images) != 1 {
+		t.Fatalf("have %d images, want 1", len(e.images))
+	}
+	have := e.images[0]
+	if have.page != 2 || have.y+have.height > 277 {
+		t.Fatalf("have image on page %d from %.2f to %.2f, want it on page 2 above 277", have.page, have.y, have.y+have.height)
+	}
+	if first := e.find(t, "first"); first.page != 1 {
+		t.Fatalf("have first line on page %d, want 1", first.page)
+	}
+}
+
+func TestTextColorAcrossPages(t *testing.T) {
+	for _, align := range []string{"left", "block"} {
+		t.Run(align, func(t *testing.T) {
+			body := `<sety y="260"/><text class="red" style="h-align: ` + align + `">` + repeated("word", 100) + `</text>`
+			e := processTestDoc(t, "red{ text-color: #c80000; orphans: 1; widows: 1; }", body)
+			pages := map[int]bool{}
+			for _, rt := range e.texts {
+				if !strings.Contains(rt.text, "word") {
+					continue
+				}
+				pages[rt.page] = true
+				if rt.color != [3]int{200, 0, 0} {
+					t.Fatalf("have color %v on page %d, want [200 0 0]", rt.color, rt.page)
+				}
+			}
+			if !pages[1] || !pages[2] {
+				t.Fatalf("have text on pages %v, want it on pages 1 and 2", pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package xpdf
+
+import (
+	"strings"
+
+	"github.com/mazzegi/xpdf/highlight"
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+// number of columns between the tab stops of preformatted text
+const preTabSize = 4
+
+// default text colors of highlighted tokens, which may be overridden by the classes code-keyword, code-string, code-comment and code-number
+var tokenColors = map[highlight.Kind]style.RGB{
+	highlight.Keyword: {R: 0x00, G: 0x33, B: 0xb3},
+	highlight.String:  {R: 0x06, G: 0x7d, B: 0x17},
+	highlight.Comment: {R: 0x8c, G: 0x8c, B: 0x8c},
+	highlight.Number:  {R: 0x17, G: 0x50, B: 0xeb},
+}
+
+// preSpan is a part of a line of preformatted text, which is written in one style
+type preSpan struct {
+	text string
+	sty  style.Styles
+}
+
+type preLine []preSpan
+
+// expandTabColumns replaces tabs in s by spaces up to the next multiple of size columns
+func expandTabColumns(s string, size int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var sb strings.Builder
+	col := 0
+	for _, r := range s {
+		if r == '\t' {
+			n := size - col%size
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		sb.WriteRune(r)
+		col++
+	}
+	return sb.String()
+}
+
+// preformatted returns the lines of the preformatted text s. The line break following the start tag, the whitespace
+// preceding the end tag and the indentation common to all lines, which stems from the document source, are removed.
+func preformatted(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimPrefix(s, "\n")
+	lines := strings.Split(s, "\n")
+	if n := len(lines); n > 1 && strings.TrimSpace(lines[n-1]) == "" {
+		lines = lines[:n-1]
+	}
+	indent := -1
+	for i, l := range lines {
+		l = strings.TrimRight(expandTabColumns(l, preTabSize), " ")
+		lines[i] = l
+		if l == "" {
+			continue
+		}
+		if n := len(l) - len(strings.TrimLeft(l, " ")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return lines
+}
+
+// preStyles returns the styles of the preformatted text pre, which is written in the mono font
+func (p *Processor) preStyles(pre *xdoc.Pre) style.Styles {
+	base := p.currStyles
+	base.Font.Family = p.engine.MonoFont()
+	return pre.MutatedStyles(p.doc.StyleClasses(), base)
+}
+
+// tokenStyles returns the styles of the highlighted token kinds in a text with styles sty
+func (p *Processor) tokenStyles(sty style.Styles) map[highlight.Kind]style.Styles {
+	styles := map[highlight.Kind]style.Styles{
+		highlight.Plain: sty,
+	}
+	for _, kind := range highlight.Kinds {
+		ksty := sty
+		ksty.Color.Text = tokenColors[kind]
+		p.doc.StyleClasses().Mutate(&ksty, "code-"+kind.String())
+		styles[kind] = ksty
+	}
+	return styles
+}
+
+// preLines splits the preformatted text pre into highlighted lines, which are wrapped or clipped to width.
+// There is at least one line.
+func (p *Processor) preLines(pre *xdoc.Pre, width float64, sty style.Styles) []preLine {
+	var styles map[highlight.Kind]style.Styles
+	if highlight.Supported(pre.Lang) {
+		styles = p.tokenStyles(sty)
+	}
+	lines := []preLine{nil}
+	for _, tok := range highlight.Tokenize(pre.Lang, strings.Join(preformatted(pre.Text), "\n")) {
+		tsty := sty
+		if styles != nil {
+			tsty = styles[tok.Kind]
+		}
+		for i, s := range strings.Split(tok.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if s != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], preSpan{text: s, sty: tsty})
+			}
+		}
+	}
+	var fitted []preLine
+	for _, line := range lines {
+		fitted = append(fitted, p.fitPreLine(line, width, sty.Overflow)...)
+	}
+	return fitted
+}
+
+// fitPreLine breaks line at the characters exceeding width into several lines or cuts them off, if overflow is clip
+func (p *Processor) fitPreLine(line preLine, width float64, overflow style.Overflow) []preLine {
+	lines := []preLine{nil}
+	var lineWidth float64
+	for _, span := range line {
+		var part strings.Builder
+		flush := func() {
+			if part.Len() > 0 {
+				lines[len(lines)-1] = append(lines[len(lines)-1], preSpan{text: part.String(), sty: span.sty})
+				part.Reset()
+			}
+		}
+		for _, r := range span.text {
+			w := p.runWidth(string(r), span.sty)
+			if lineWidth+w > width && lineWidth > 0 {
+				flush()
+				if overflow == style.OverflowClip {
+					return lines
+				}
+				lines = append(lines, nil)
+				lineWidth = 0
+			}
+			part.WriteRune(r)
+			lineWidth += w
+		}
+		flush()
+	}
+	return lines
+}
+
+// preHeight returns the height of n lines of preformatted text with styles sty
+func (p *Processor) preHeight(n int, sty style.Styles) float64 {
+	p.engine.ChangeFont(sty.Font)
+	fontHeight := p.engine.FontHeight()
+	lineHeight := fontHeight * sty.LineSpacing
+	return float64(n-1)*lineHeight + fontHeight + sty.Padding.Top + sty.Padding.Bottom
+}
+
+// preBoxWidth returns the width of the box of preformatted text and the width of its content
+func preBoxWidth(sty style.Styles, pa PrintableArea) (box, content float64) {
+	box = pa.EffectiveWidth(sty.Width) - sty.OffsetX
+	return box, box - sty.Padding.Left - sty.Padding.Right
+}
+
+// preBlockHeight returns the height of the preformatted text pre
+func (p *Processor) preBlockHeight(pre *xdoc.Pre, pa PrintableArea) float64 {
+	defer p.resetStyles()
+	sty := p.preStyles(pre)
+	_, width := preBoxWidth(sty, pa)
+	return p.preHeight(len(p.preLines(pre, width, sty)), sty) + sty.OffsetY
+}
+
+// renderPre writes the preformatted text pre into its box. Boxes exceeding the page are continued on the next one.
+func (p *Processor) renderPre(pre *xdoc.Pre, pa PrintableArea) {
+	defer p.resetStyles()
+	sty := p.preStyles(pre)
+	boxWidth, width := preBoxWidth(sty, pa)
+	lines := p.preLines(pre, width, sty)
+	p.keepTogether(sty, p.preHeight(len(lines), sty)+sty.OffsetY)
+
+	p.engine.ChangeFont(sty.Font)
+	fontHeight := p.engine.FontHeight()
+	lineHeight := fontHeight * sty.LineSpacing
+	xStart, y := p.engine.GetXY()
+	y += sty.OffsetY
+	for idx := 0; idx < len(lines); {
+		take := len(lines) - idx
+		if !p.preventPageBreak {
+			avail := linesFitting(y+sty.Padding.Top, p.page().printableArea.y1-sty.Padding.Bottom, fontHeight, lineHeight, make([]lineExtent, take))
+			take = linesOnPage(take, avail, sty.Orphans, sty.Widows, p.atPageTop())
+			if take == 0 {
+				xStart = p.newPageAt(xStart)
+				_, y = p.engine.GetXY()
+				continue
+			}
+		}
+		x0 := xStart + sty.OffsetX
+		height := p.preHeight(take, sty)
+		p.drawBox(x0, y, x0+boxWidth, y+height, sty)
+		for i, line := range lines[idx : idx+take] {
+			p.engine.SetY(y + sty.Padding.Top + float64(i)*lineHeight)
+			p.engine.SetX(x0 + sty.Padding.Left)
+			for _, span := range line {
+				p.engine.SetTextColor(span.sty.Text.Values())
+				p.writeRun(span.text, span.sty)
+			}
+		}
+		idx += take
+		y += height
+		p.engine.SetY(y)
+		p.engine.SetX(xStart)
+		if idx < len(lines) {
+			xStart = p.newPageAt(xStart)
+			_, y = p.engine.GetXY()
+		}
+	}
+	p.engine.SetTextColor(p.currStyles.Text.Values())
+}
+
+// codeItem returns the text item of inline code in a text with styles sty
+func (p *Processor) codeItem(code *xdoc.Code, text string, sty style.Styles) *textItem {
+	base := sty
+	base.Font.Family = p.engine.MonoFont()
+	return p.runItem(code, text, base)
+}
@@ -0,0 +1,48 @@
+package xpdf
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestExpandTabColumns(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "abc", exp: "abc"},
+		{in: "\tx", exp: "    x"},
+		{in: "ab\tx", exp: "ab  x"},
+		{in: "abcd\tx\ty", exp: "abcd    x   y"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := expandTabColumns(test.in, 4)
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestPreformatted(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp []string
+	}{
+		{in: "", exp: []string{""}},
+		{in: "a  b", exp: []string{"a  b"}},
+		{in: "\n  a\n    b\n\n  c\n  ", exp: []string{"a", "  b", "", "c"}},
+		{in: "\n\tif x {\n\t\ty()  \n\t}\n", exp: []string{"if x {", "    y()", "}"}},
+		{in: "a\r\n b", exp: []string{"a", " b"}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := preformatted(test.in)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
@@ -3,13 +3,14 @@ package xpdf
 import (
 	"fmt"
 	"io"
-	"path/filepath"
 	"strings"
 
 	"github.com/mazzegi/xpdf/engine"
 	"github.com/mazzegi/xpdf/hyphenation"
+	"github.com/mazzegi/xpdf/resource"
 	"github.com/mazzegi/xpdf/style"
 	"github.com/mazzegi/xpdf/xdoc"
+	"github.com/pkg/errors"
 )
 
 type Processor struct {
@@ -17,27 +18,75 @@ type Processor struct {
 	doc              *xdoc.Document
 	currStyles       style.Styles
 	hyphenator       *hyphenation.Hyphenator
+	hyphenators      map[string]*hyphenation.Hyphenator
 	preventPageBreak bool
-	workingDir       string
+	pendingPageBreak style.PageBreak
+	columns          *columnFlow
+	floats           []floatArea
+	resources        resource.Resolver
+	// footnotes of the current page, footnotes waiting for the next page and the number of the last footnote
+	footnotes         []*footnote
+	deferredFootnotes []*footnote
+	footnoteNumber    int
+	// inlineImages are the resolved images of inline image instructions
+	inlineImages map[*xdoc.InlineImage]*resolvedImage
 }
 
+// NewProcessor returns a processor of doc. Resources like images are resolved relative to workingDir,
+// unless another resolver is set.
 func NewProcessor(engine engine.Engine, hyphenator *hyphenation.Hyphenator, doc *xdoc.Document, workingDir string) *Processor {
 	p := &Processor{
-		engine:     engine,
-		hyphenator: hyphenator,
-		doc:        doc,
-		currStyles: DefaultStyle(),
-		workingDir: workingDir,
+		engine:      engine,
+		hyphenator:  hyphenator,
+		hyphenators: map[string]*hyphenation.Hyphenator{},
+		doc:         doc,
+		currStyles:  DefaultStyle(),
+		resources:   resource.DataURI(resource.Dir(workingDir)),
 	}
 	return p
 }
 
-func (p *Processor) resolveFile(name string) string {
-	if filepath.IsAbs(name) {
-		return name
+// SetHyphenator sets the hyphenator of the language lang like "de" or "de-CH", which is used instead of the bundled one.
+// Text of a regional variant without an own hyphenator uses the one of its primary language.
+func (p *Processor) SetHyphenator(lang string, h *hyphenation.Hyphenator) {
+	if tags := hyphenation.LangTags(lang); len(tags) > 0 {
+		p.hyphenators[tags[0]] = h
 	}
-	rname := filepath.Join(p.workingDir, name)
-	return filepath.Clean(rname)
+}
+
+// hyphenatorFor returns the hyphenator of text in the language lang. Text without language uses the default hyphenator,
+// text in a language without patterns isn't hyphenated automatically and nil is returned.
+func (p *Processor) hyphenatorFor(lang string) *hyphenation.Hyphenator {
+	tags := hyphenation.LangTags(lang)
+	if len(tags) == 0 {
+		return p.hyphenator
+	}
+	for _, tag := range tags {
+		if h, ok := p.hyphenators[tag]; ok {
+			return h
+		}
+	}
+	h, _ := hyphenation.ForLang(lang)
+	p.hyphenators[tags[0]] = h
+	return h
+}
+
+// SetResourceResolver sets the resolver for resources like images. Data URIs are always resolved.
+func (p *Processor) SetResourceResolver(r resource.Resolver) {
+	p.resources = resource.DataURI(r)
+}
+
+// resource returns the content of the resource with the given name
+func (p *Processor) resource(name string) ([]byte, error) {
+	name = strings.TrimSpace(name)
+	data, err := p.resources.Resolve(name)
+	if err != nil {
+		if resource.IsDataURI(name) {
+			return nil, errors.Wrap(err, "resolve data uri")
+		}
+		return nil, errors.Wrapf(err, "resolve %q", name)
+	}
+	return data, nil
 }
 
 func (p *Processor) Process(w io.Writer) error {
@@ -53,29 +102,49 @@ func (p *Processor) Process(w io.Writer) error {
 	p.engine.OnHeader(func() {
 		x, y := p.engine.GetXY()
 		p.preventPageBreak = true
+		columns := p.columns
+		p.columns = nil
 		defer func() {
 			p.engine.SetX(x)
 			p.engine.SetY(y)
 			p.preventPageBreak = false
+			p.columns = columns
 		}()
 		p.processInstructions(p.doc.Header)
 	})
 	p.engine.OnFooter(func() {
 		x, y := p.engine.GetXY()
 		p.preventPageBreak = true
+		columns := p.columns
+		p.columns = nil
 		defer func() {
 			p.engine.SetX(x)
 			p.engine.SetY(y)
 			p.preventPageBreak = false
+			p.columns = columns
 		}()
 		p.processInstructions(p.doc.Footer)
 	})
 
+	p.currStyles.Lang = p.doc.Lang
+	if hyp := p.hyphenatorFor(p.doc.Lang); strings.TrimSpace(p.doc.Hyphenation) != "" && hyp != nil {
+		hyp, err := hyp.WithExceptions(strings.NewReader(p.doc.Hyphenation))
+		if err != nil {
+			return errors.Wrap(err, "load hyphenation exceptions")
+		}
+		if p.doc.Lang == "" {
+			p.hyphenator = hyp
+		} else {
+			p.SetHyphenator(p.doc.Lang, hyp)
+		}
+	}
+
 	//Change font to initial default font
 	p.changeFont(p.currStyles.Font)
 
 	p.engine.AddPage()
 	p.processInstructions(p.doc.Body)
+	p.flushFootnotes()
 
 	err := p.engine.Error()
 	if err != nil {
@@ -97,17 +166,30 @@ func (p *Processor) changeFont(fnt style.Font) {
 	p.engine.ChangeFont(p.currStyles.Font)
 }
 
-func (p *Processor) page() Page {
+// pageArea returns the printable area of the page above its footnotes
+func (p *Processor) pageArea() PrintableArea {
 	x0, y0, x1, y1 := p.engine.PrintableArea()
+	return PrintableArea{
+		x0: x0,
+		y0: y0,
+		x1: x1,
+		y1: y1 - p.footnoteSpace(),
+	}
+}
+
+// page returns the page, where the printable area is the one of the current flow (e.g. a column)
+func (p *Processor) page() Page {
 	page := Page{
-		width:  p.engine.PageWidth(),
-		height: p.engine.PageHeight(),
-		printableArea: PrintableArea{
-			x0: x0,
-			y0: y0,
-			x1: x1,
-			y1: y1,
-		},
+		width:         p.engine.PageWidth(),
+		height:        p.engine.PageHeight(),
+		printableArea: p.pageArea(),
+	}
+	if p.columns != nil {
+		area := p.columns.area()
+		if area.y1 > page.printableArea.y1 {
+			area.y1 = page.printableArea.y1
+		}
+		page.printableArea = area
 	}
 	return page
 }
@@ -118,7 +200,15 @@ func (p *Processor) resetStyles() {
 }
 
 func (p *Processor) processInstructions(is xdoc.Instructions) {
-	for _, i := range is.ISS {
+	for idx, i := range is.ISS {
+		if isBlock(i) {
+			if p.columns != nil {
+				p.engine.SetX(p.columns.area().x0)
+			}
+			p.clearFloats(i.MutatedStyles(p.doc.StyleClasses(), p.currStyles).Clear)
+			p.pageBreakBefore(i)
+			p.keepWithNext(is.ISS, idx)
+		}
 		switch i := i.(type) {
 		case *xdoc.Font:
 			p.changeFont(i.MutatedStyles(p.doc.StyleClasses(), p.currStyles).Font)
@@ -136,10 +226,21 @@ func (p *Processor) processInstructions(is xdoc.Instructions) {
 			p.renderTable(i)
 		case *xdoc.Image:
 			p.renderImage(i, p.page().printableArea)
+		case *xdoc.Barcode:
+			p.renderBarcode(i, p.page().printableArea)
+		case *xdoc.Chart:
+			p.renderChart(i, p.page().printableArea)
+		case *xdoc.Pre:
+			p.renderPre(i, p.page().printableArea)
 		case *xdoc.Grid:
 			p.renderGrid(i, p.page().printableArea)
+		case *xdoc.Columns:
+			p.renderColumns(i)
 		case *xdoc.PageBreak:
-			p.engine.AddPage()
+			p.newPage()
+		}
+		if isBlock(i) {
+			p.pageBreakAfter(i)
 		}
 	}
 }
@@ -152,7 +253,19 @@ func (p *Processor) renderText(text *xdoc.Text) {
 	sty := text.MutatedStyles(p.doc.StyleClasses(), p.currStyles)
 	width := p.page().EffectiveWidth(sty.Width)
 
-	p.writeTextFnc(sty)(text.ISS, width, sty)
+	p.engine.ChangeFont(sty.Font)
+	x, y := p.engine.GetXY()
+	span := p.floatSpan(x, y, width, p.engine.FontHeight()*sty.LineSpacing)
+	if sty.KeepTogether && !p.preventPageBreak {
+		//the lines are measured as shortened around floats
+		height := p.linesHeight(p.textLinesFnc(sty)(text.ISS, span, sty), sty)
+		if p.keepTogether(sty, height) {
+			p.engine.ChangeFont(sty.Font)
+			x, y = p.engine.GetXY()
+			span = p.floatSpan(x, y, width, p.engine.FontHeight()*sty.LineSpacing)
+		}
+	}
+	p.writeTextFnc(sty)(text.ISS, span, sty)
 }
 
 func (p *Processor) textBoxHeight(box *xdoc.Box, pa PrintableArea) float64 {
@@ -189,23 +302,51 @@ func (p *Processor) renderTextBox(box *xdoc.Box, pa PrintableArea) {
 	}
 
 	x0, y0 := p.engine.GetXY()
-	_, _, _, bm := p.engine.Margins()
-	effHeight := p.engine.PageHeight() - bm
-	if !p.preventPageBreak && y0+height >= effHeight {
-		p.engine.AddPage()
+	if !p.preventPageBreak && y0+height >= p.page().printableArea.y1 {
+		p.newPage()
 		x0, y0 = p.engine.GetXY()
 	}
+	xStart, yStart := x0, y0
+	floating := sty.Float == style.FloatLeft || sty.Float == style.FloatRight
+	if floating {
+		outerHeight := height + sty.Padding.Top + sty.Padding.Bottom + sty.Margin.Top + sty.Margin.Bottom
+		outerWidth := width + sty.Padding.Left + sty.Padding.Right + sty.Margin.Left + sty.Margin.Right
+		x0 = p.floatPosition(pa, sty.Float, y0, outerWidth, outerHeight) + sty.Margin.Left
+		y0 += sty.Margin.Top
+	}
 
 	x0 += sty.Dimension.OffsetX
 	y0 += sty.Dimension.OffsetY
 	y1 := y0 + height + sty.Box.Padding.Top + sty.Box.Padding.Bottom
 	x1 := x0 + width + sty.Padding.Left + sty.Padding.Right
-	p.drawBox(x0, y0, x1, y1, sty)
+	if floating {
+		p.addFloat(PrintableArea{
+			x0: x0 - sty.Margin.Left,
+			y0: y0 - sty.Margin.Top,
+			x1: x1 + sty.Margin.Right,
+			y1: y1 + sty.Margin.Bottom,
+		}, sty.Float)
+		defer func() {
+			p.engine.SetX(xStart)
+			p.engine.SetY(yStart)
+		}()
+	}
 
-	p.engine.SetY(y0 + sty.Box.Padding.Top)
-	p.engine.SetX(x0 + sty.Box.Padding.Left)
-	if len(box.ISS) > 0 {
-		p.writeTextFnc(sty)(box.ISS, width, sty)
+	render := func() {
+		p.drawBox(x0, y0, x1, y1, sty)
+		p.engine.SetY(y0 + sty.Box.Padding.Top)
+		p.engine.SetX(x0 + sty.Box.Padding.Left)
+		if len(box.ISS) > 0 {
+			//the box is already drawn, so its text must not be split across pages
+			defer p.keepPageBreakPrevention()()
+			p.writeTextFnc(sty)(box.ISS, fixedSpan(width), sty)
+		}
+	}
+	if sty.Rotate == 0 {
+		render()
+	} else {
+		//like a css transform, the rotation doesn't affect the layout
+		p.transformed(rotation(sty.Rotate, (x0+x1)/2, (y0+y1)/2), render)
 	}
 	p.engine.SetY(y1)
 }
@@ -220,7 +361,16 @@ func (p *Processor) textHeightFnc(sty style.Styles) func([]xdoc.Instruction, flo
 	}
 }
 
-func (p *Processor) writeTextFnc(sty style.Styles) func([]xdoc.Instruction, float64, style.Styles) {
+func (p *Processor) textLinesFnc(sty style.Styles) func([]xdoc.Instruction, lineSpan, style.Styles) []textLine {
+	switch sty.HAlign {
+	case style.HAlignBlock:
+		return p.textLinesHyphenated
+	default:
+		return p.textLines
+	}
+}
+
+func (p *Processor) writeTextFnc(sty style.Styles) func([]xdoc.Instruction, lineSpan, style.Styles) {
 	switch sty.HAlign {
 	case style.HAlignBlock:
 		return p.writeTextHyphenated
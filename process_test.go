@@ -0,0 +1,134 @@
+package xpdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/engine"
+	"github.com/mazzegi/xpdf/font"
+	"github.com/mazzegi/xpdf/hyphenation"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+// recordEngine records the text written by the engine with its page, position and color
+type recordEngine struct {
+	engine.Engine
+	color  [3]int
+	texts  []recordedText
+	images []recordedImage
+}
+
+type recordedText struct {
+	text  string
+	page  int
+	x, y  float64
+	color [3]int
+}
+
+// recordedImage is an image put by the engine
+type recordedImage struct {
+	name          string
+	page          int
+	x, y          float64
+	width, height float64
+}
+
+func (e *recordEngine) SetTextColor(r, g, b int) {
+	e.color = [3]int{r, g, b}
+	e.Engine.SetTextColor(r, g, b)
+}
+
+func (e *recordEngine) WriteText(s string) {
+	x, y := e.GetXY()
+	e.texts = append(e.texts, recordedText{text: s, page: e.CurrentPage(), x: x, y: y, color: e.color})
+	e.Engine.WriteText(s)
+}
+
+func (e *recordEngine) PutImage(name string, data []byte, format string, x, y, width, height float64) {
+	e.images = append(e.images, recordedImage{name: name, page: e.CurrentPage(), x: x, y: y, width: width, height: height})
+	e.Engine.PutImage(name, data, format, x, y, width, height)
+}
+
+// find returns the first text containing s
+func (e *recordEngine) find(t *testing.T, s string) recordedText {
+	for _, rt := range e.texts {
+		if strings.Contains(rt.text, s) {
+			return rt
+		}
+	}
+	t.Fatalf("no text %q written", s)
+	return recordedText{}
+}
+
+// testDocument returns a document with A4 pages and margins of 20mm. The header and footer contain text,
+// so they reset the styles like in real documents.
+func testDocument(t *testing.T, classes, body string) *xdoc.Document {
+	src := `<document>
+	<page><orientation>portrait</orientation><format>a4</format>
+		<margins><left>20</left><top>20</top><right>20</right><bottom>20</bottom></margins>
+	</page>
+	<style>` + classes + `</style>
+	<header><text>header</text></header>
+	<footer><text>footer</text></footer>
+	<body>` + body + `</body>
+</document>`
+	doc, err := xdoc.Load(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("load document: %v", err)
+	}
+	return doc
+}
+
+// testProcessor returns a processor of an empty document with core fonts, which is on its first page
+func testProcessor(t *testing.T) *Processor {
+	doc := testDocument(t, "", "")
+	fpdf, err := engine.NewFPDF(font.NewRegistry(), doc)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	p := NewProcessor(fpdf, hyphenation.NewEnUs(), doc, "")
+	p.changeFont(p.currStyles.Font)
+	fpdf.AddPage()
+	return p
+}
+
+// processTestDoc processes a document with core fonts
+func processTestDoc(t *testing.T, classes, body string) *recordEngine {
+	doc := testDocument(t, classes, body)
+	fpdf, err := engine.NewFPDF(font.NewRegistry(), doc)
+	if err != nil {
+		t.Fatalf("create engine: %v", err)
+	}
+	e := &recordEngine{Engine: fpdf}
+	p := NewProcessor(e, hyphenation.NewEnUs(), doc, "")
+	if err := p.Process(io.Discard); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	return e
+}
+
+// repeated returns n times s separated by spaces
+func repeated(s string, n int) string {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(s)
+	}
+	return buf.String()
+}
+
+// testImageURI returns a data URI of a PNG image with 2x2 pixels
+func testImageURI(t *testing.T) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
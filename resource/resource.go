@@ -0,0 +1,135 @@
+// Package resource provides resolvers, which load resources like images and fonts by name
+// from the file system, a fs.FS (e.g. embed.FS), memory or data URIs.
+package resource
+
+import (
+	"encoding/base64"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Resolver returns the content of the resource with the given name.
+// If the resource doesn't exist, the returned error matches fs.ErrNotExist.
+type Resolver interface {
+	Resolve(name string) ([]byte, error)
+}
+
+// ResolverFunc is a function implementing Resolver
+type ResolverFunc func(name string) ([]byte, error)
+
+func (f ResolverFunc) Resolve(name string) ([]byte, error) {
+	return f(name)
+}
+
+// Dir resolves names in the file system relative to a directory. Absolute names are used as they are.
+type Dir string
+
+func (d Dir) Resolve(name string) ([]byte, error) {
+	file := name
+	if !filepath.IsAbs(file) {
+		file = filepath.Clean(filepath.Join(string(d), name))
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read file %q", file)
+	}
+	return data, nil
+}
+
+type fsResolver struct {
+	fsys fs.FS
+}
+
+// FS returns a resolver for names in fsys. Leading slashes are ignored, as fs.FS only accepts unrooted paths.
+func FS(fsys fs.FS) Resolver {
+	return fsResolver{fsys: fsys}
+}
+
+func (r fsResolver) Resolve(name string) ([]byte, error) {
+	clean := path.Clean("/" + filepath.ToSlash(name))[1:]
+	data, err := fs.ReadFile(r.fsys, clean)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read fs file %q", clean)
+	}
+	return data, nil
+}
+
+// Map resolves names from memory
+type Map map[string][]byte
+
+func (m Map) Resolve(name string) ([]byte, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, errors.Wrapf(fs.ErrNotExist, "resolve %q", name)
+	}
+	return data, nil
+}
+
+// Chain tries the resolvers in order, until one of them knows the resource
+func Chain(rs ...Resolver) Resolver {
+	return ResolverFunc(func(name string) ([]byte, error) {
+		for _, r := range rs {
+			data, err := r.Resolve(name)
+			if err == nil {
+				return data, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+		return nil, errors.Wrapf(fs.ErrNotExist, "resolve %q", name)
+	})
+}
+
+// DataURI returns a resolver, which decodes data URIs (RFC 2397) and passes all other names to next
+func DataURI(next Resolver) Resolver {
+	return ResolverFunc(func(name string) ([]byte, error) {
+		if IsDataURI(name) {
+			return DecodeDataURI(name)
+		}
+		if next == nil {
+			return nil, errors.Wrapf(fs.ErrNotExist, "resolve %q", name)
+		}
+		return next.Resolve(name)
+	})
+}
+
+// IsDataURI reports if s is a data URI
+func IsDataURI(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "data:")
+}
+
+// DecodeDataURI returns the content of a data URI like "data:image/png;base64,iVBOR..."
+func DecodeDataURI(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "data:") {
+		return nil, errors.Errorf("not a data uri")
+	}
+	meta, content, ok := strings.Cut(s[len("data:"):], ",")
+	if !ok {
+		return nil, errors.Errorf("data uri without data")
+	}
+	if strings.HasSuffix(meta, ";base64") {
+		//whitespace is allowed in base64 content, e.g. for line breaks in documents
+		content = strings.Join(strings.Fields(content), "")
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			data, err = base64.RawStdEncoding.DecodeString(content)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "decode base64 data uri")
+		}
+		return data, nil
+	}
+	unescaped, err := url.PathUnescape(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "unescape data uri")
+	}
+	return []byte(unescaped), nil
+}
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+)
+
+func TestResolvers(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "logo.txt"), []byte("from dir"), 0644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"img/logo.txt": &fstest.MapFile{Data: []byte("from fs")},
+	}
+	mem := Map{
+		"logo": []byte("from map"),
+	}
+
+	tests := []struct {
+		name     string
+		resolver Resolver
+		in       string
+		out      string
+		notExist bool
+		fail     bool
+	}{
+		{name: "dir", resolver: Dir(dir), in: "logo.txt", out: "from dir"},
+		{name: "dir absolute", resolver: Dir("/nowhere"), in: filepath.Join(dir, "logo.txt"), out: "from dir"},
+		{name: "dir missing", resolver: Dir(dir), in: "missing.txt", notExist: true},
+		{name: "fs", resolver: FS(fsys), in: "img/logo.txt", out: "from fs"},
+		{name: "fs rooted", resolver: FS(fsys), in: "/img/../img/logo.txt", out: "from fs"},
+		{name: "fs missing", resolver: FS(fsys), in: "logo.txt", notExist: true},
+		{name: "map", resolver: mem, in: "logo", out: "from map"},
+		{name: "map missing", resolver: mem, in: "other", notExist: true},
+		{name: "chain", resolver: Chain(mem, FS(fsys), Dir(dir)), in: "logo.txt", out: "from dir"},
+		{name: "chain missing", resolver: Chain(mem, FS(fsys)), in: "missing", notExist: true},
+		{name: "data base64", resolver: DataURI(nil), in: "data:text/plain;base64,aGVs\n bG8=", out: "hello"},
+		{name: "data base64 unpadded", resolver: DataURI(nil), in: "data:;base64,aGVsbG8", out: "hello"},
+		{name: "data escaped", resolver: DataURI(nil), in: "data:,hello%20world", out: "hello world"},
+		{name: "data fallback", resolver: DataURI(mem), in: "logo", out: "from map"},
+		{name: "data without next", resolver: DataURI(nil), in: "logo", notExist: true},
+		{name: "data invalid", resolver: DataURI(nil), in: "data:;base64,!!!", fail: true},
+		{name: "data without comma", resolver: DataURI(nil), in: "data:text/plain", fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := test.resolver.Resolve(test.in)
+			switch {
+			case test.notExist:
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("want not-exist error, have %v", err)
+				}
+			case test.fail:
+				if err == nil {
+					t.Fatalf("resolve %q should fail but did not", test.in)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("resolve %q failed: %v", test.in, err)
+				}
+				if string(data) != test.out {
+					t.Fatalf("have %q, want %q", string(data), test.out)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package style
 
+import "github.com/pkg/errors"
+
 type HAlign string
 
 const (
@@ -9,6 +11,18 @@ const (
 	HAlignBlock  HAlign = "block"
 )
 
+// Mirrored returns the alignment of right-to-left text, where left and right are swapped as they denote the start and end of lines
+func (a HAlign) Mirrored() HAlign {
+	switch a {
+	case HAlignLeft:
+		return HAlignRight
+	case HAlignRight:
+		return HAlignLeft
+	default:
+		return a
+	}
+}
+
 type VAlign string
 
 const (
@@ -17,7 +31,39 @@ const (
 	VAlignBottom VAlign = "bottom"
 )
 
+type VerticalAlignMode string
+
+const (
+	VerticalAlignBaseline VerticalAlignMode = "baseline"
+	VerticalAlignMiddle   VerticalAlignMode = "middle"
+	VerticalAlignSuper    VerticalAlignMode = "super"
+	VerticalAlignSub      VerticalAlignMode = "sub"
+	VerticalAlignLength   VerticalAlignMode = "length"
+)
+
+// VerticalAlign is the vertical alignment of inline elements relative to the text line.
+// Besides the keywords, it may be a length, which raises (positive) or lowers (negative) the baseline.
+type VerticalAlign struct {
+	Mode   VerticalAlignMode
+	Length float64
+}
+
+func (va *VerticalAlign) UnmarshalStyle(v string) error {
+	switch m := VerticalAlignMode(trimWS(v)); m {
+	case VerticalAlignBaseline, VerticalAlignMiddle, VerticalAlignSuper, VerticalAlignSub:
+		*va = VerticalAlign{Mode: m}
+		return nil
+	}
+	l, err := ParseLength(v)
+	if err != nil {
+		return errors.Errorf("invalid vertical-align (%s) - must be one of baseline, middle, super, sub or a length", v)
+	}
+	*va = VerticalAlign{Mode: VerticalAlignLength, Length: l}
+	return nil
+}
+
 type Align struct {
-	HAlign `style:"h-align"`
-	VAlign `style:"v-align"`
+	HAlign        `style:"h-align"`
+	VAlign        `style:"v-align"`
+	VerticalAlign `style:"vertical-align"`
 }
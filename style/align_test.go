@@ -0,0 +1,41 @@
+package style
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestVerticalAlign(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  VerticalAlign
+		fail bool
+	}{
+		{in: "baseline", exp: VerticalAlign{Mode: VerticalAlignBaseline}},
+		{in: " super ", exp: VerticalAlign{Mode: VerticalAlignSuper}},
+		{in: "sub", exp: VerticalAlign{Mode: VerticalAlignSub}},
+		{in: "middle", exp: VerticalAlign{Mode: VerticalAlignMiddle}},
+		{in: "1.5", exp: VerticalAlign{Mode: VerticalAlignLength, Length: 1.5}},
+		{in: "-3pt", exp: VerticalAlign{Mode: VerticalAlignLength, Length: -3 * 25.4 / 72}},
+		{in: "top", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var have VerticalAlign
+			err := have.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if have.Mode != test.exp.Mode || math.Abs(have.Length-test.exp.Length) > 1e-9 {
+				t.Fatalf("have %v, want %v", have, test.exp)
+			}
+		})
+	}
+}
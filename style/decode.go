@@ -131,6 +131,14 @@ func makeSetValueFnc(kind reflect.Kind, styleValue string) (func(v reflect.Value
 		return func(v reflect.Value) {
 			v.SetString(styleValue)
 		}, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(styleValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse-bool (%s)", styleValue)
+		}
+		return func(v reflect.Value) {
+			v.SetBool(b)
+		}, nil
 	case reflect.Float32, reflect.Float64:
 		f, err := strconv.ParseFloat(styleValue, 64)
 		if err != nil {
@@ -1,9 +1,75 @@
 package style
 
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 type Dimension struct {
 	Width       float64 `style:"width"`
 	Height      float64 `style:"height"`
 	LineSpacing float64 `style:"line-spacing"`
 	OffsetX     float64 `style:"offset-x"`
 	OffsetY     float64 `style:"offset-y"`
+	Rotate      Angle   `style:"rotate"`
+}
+
+// Angle is an angle in degrees, where positive values rotate clockwise
+type Angle float64
+
+func (a *Angle) UnmarshalStyle(v string) error {
+	s := trimWS(strings.TrimSuffix(trimWS(v), "deg"))
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return errors.Wrapf(err, "parse angle (%s)", v)
+	}
+	*a = Angle(f)
+	return nil
+}
+
+// Radians returns the angle in radians
+func (a Angle) Radians() float64 {
+	return float64(a) * math.Pi / 180
+}
+
+// Length is a length in millimeters, which may be given with a unit
+type Length float64
+
+func (l *Length) UnmarshalStyle(v string) error {
+	f, err := ParseLength(v)
+	if err != nil {
+		return err
+	}
+	*l = Length(f)
+	return nil
+}
+
+// ParseLength parses a length with an optional unit (mm, cm, in, pt). Values without a unit are millimeters.
+func ParseLength(s string) (float64, error) {
+	s = trimWS(s)
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"mm", 1},
+		{"cm", 10},
+		{"in", 25.4},
+		{"pt", 25.4 / 72},
+	}
+	factor := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s = trimWS(strings.TrimSuffix(s, u.suffix))
+			factor = u.factor
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parse length (%s)", s)
+	}
+	return f * factor, nil
 }
@@ -0,0 +1,74 @@
+package style
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  float64
+		fail bool
+	}{
+		{in: "6", exp: 6},
+		{in: "6mm", exp: 6},
+		{in: " 1.5 cm ", exp: 15},
+		{in: "1in", exp: 25.4},
+		{in: "72pt", exp: 25.4},
+		{in: "-2mm", exp: -2},
+		{in: "mm", fail: true},
+		{in: "6px", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have, err := ParseLength(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if math.Abs(have-test.exp) > 1e-9 {
+				t.Fatalf("have %f, want %f", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestParseAngle(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  Angle
+		fail bool
+	}{
+		{in: "90", exp: 90},
+		{in: "90deg", exp: 90},
+		{in: " -45 deg ", exp: -45},
+		{in: "12.5", exp: 12.5},
+		{in: "deg", fail: true},
+		{in: "1rad", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var have Angle
+			err := have.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if have != test.exp {
+				t.Fatalf("have %f, want %f", have, test.exp)
+			}
+		})
+	}
+}
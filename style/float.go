@@ -0,0 +1,43 @@
+package style
+
+import "github.com/pkg/errors"
+
+type Float string
+
+const (
+	FloatNone  Float = "none"
+	FloatLeft  Float = "left"
+	FloatRight Float = "right"
+)
+
+type Clear string
+
+const (
+	ClearNone  Clear = "none"
+	ClearLeft  Clear = "left"
+	ClearRight Clear = "right"
+	ClearBoth  Clear = "both"
+)
+
+type Floating struct {
+	Float Float `style:"float"`
+	Clear Clear `style:"clear"`
+}
+
+func (f *Float) UnmarshalStyle(v string) error {
+	switch fl := Float(trimWS(v)); fl {
+	case FloatNone, FloatLeft, FloatRight:
+		*f = fl
+		return nil
+	}
+	return errors.Errorf("invalid float (%s) - must be one of none, left or right", v)
+}
+
+func (c *Clear) UnmarshalStyle(v string) error {
+	switch cl := Clear(trimWS(v)); cl {
+	case ClearNone, ClearLeft, ClearRight, ClearBoth:
+		*c = cl
+		return nil
+	}
+	return errors.Errorf("invalid clear (%s) - must be one of none, left, right or both", v)
+}
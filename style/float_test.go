@@ -0,0 +1,71 @@
+package style
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFloat(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  Float
+		fail bool
+	}{
+		{in: "none", exp: FloatNone},
+		{in: " left ", exp: FloatLeft},
+		{in: "right", exp: FloatRight},
+		{in: "lft", fail: true},
+		{in: "", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var have Float
+			err := have.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  Clear
+		fail bool
+	}{
+		{in: "none", exp: ClearNone},
+		{in: "left", exp: ClearLeft},
+		{in: " right", exp: ClearRight},
+		{in: "both", exp: ClearBoth},
+		{in: "all", fail: true},
+		{in: "", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var have Clear
+			err := have.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
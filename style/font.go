@@ -1,5 +1,10 @@
 package style
 
+import (
+	"strconv"
+	"strings"
+)
+
 type FontStyle string
 
 const (
@@ -7,6 +12,7 @@ const (
 	FontStyleItalic FontStyle = "italic"
 )
 
+// FontWeight is normal, bold or a numeric weight from 100 to 900
 type FontWeight string
 
 const (
@@ -14,6 +20,26 @@ const (
 	FontWeightBold   FontWeight = "bold"
 )
 
+// Value returns the numeric weight, which is 400 for normal and 700 for bold. Numbers are limited to the range from 100 to 900.
+func (w FontWeight) Value() int {
+	switch w {
+	case FontWeightBold:
+		return 700
+	case FontWeightNormal:
+		return 400
+	}
+	n, err := strconv.Atoi(string(w))
+	switch {
+	case err != nil:
+		return 400
+	case n < 100:
+		return 100
+	case n > 900:
+		return 900
+	}
+	return n
+}
+
 type FontDecoration string
 
 const (
@@ -21,10 +47,51 @@ const (
 	FontDecorationUnderline FontDecoration = "underline"
 )
 
+type FontVariant string
+
+const (
+	FontVariantNormal    FontVariant = "normal"
+	FontVariantSmallCaps FontVariant = "small-caps"
+)
+
+// FontKerning controls, if the kerning pairs of a font are applied. Auto applies them like normal.
+type FontKerning string
+
+const (
+	FontKerningAuto   FontKerning = "auto"
+	FontKerningNormal FontKerning = "normal"
+	FontKerningNone   FontKerning = "none"
+)
+
+// FontLigatures controls, if the standard ligatures of a font like fi are used
+type FontLigatures string
+
+const (
+	FontLigaturesNormal FontLigatures = "normal"
+	FontLigaturesNone   FontLigatures = "none"
+)
+
 type Font struct {
+	// Family is a family or a comma separated list of families, which are used for characters missing in the preceding ones.
+	// Characters beyond the basic multilingual plane like emoji aren't supported by the PDF engine and written as U+FFFD.
 	Family     string         `style:"font-family"`
 	PointSize  float64        `style:"font-point-size"`
 	Style      FontStyle      `style:"font-style"`
 	Weight     FontWeight     `style:"font-weight"`
 	Decoration FontDecoration `style:"font-decoration"`
+	Variant    FontVariant    `style:"font-variant"`
+	Kerning    FontKerning    `style:"font-kerning"`
+	Ligatures  FontLigatures  `style:"font-variant-ligatures"`
+}
+
+// Families returns the families of the list in Family, where quotes are removed
+func (f Font) Families() []string {
+	var families []string
+	for _, family := range strings.Split(f.Family, ",") {
+		family = strings.Trim(strings.TrimSpace(family), `"'`)
+		if family != "" {
+			families = append(families, family)
+		}
+	}
+	return families
 }
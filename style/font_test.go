@@ -0,0 +1,65 @@
+package style
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFamilies(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "", exp: "[]"},
+		{in: "noto", exp: "[noto]"},
+		{in: `noto, dejavu, "noto-cjk"`, exp: "[noto dejavu noto-cjk]"},
+		{in: " 'dejavu serif' ,, noto", exp: "[dejavu serif noto]"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := fmt.Sprint(Font{Family: test.in}.Families())
+			if have != test.exp {
+				t.Fatalf("have %s, want %s", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestDecodeFamilies(t *testing.T) {
+	m, err := DecodeMutator(strings.NewReader(`font-family: noto, dejavu, "noto-cjk"; font-kerning: none`))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var sty Styles
+	m.Mutate(&sty)
+	if have := fmt.Sprint(sty.Font.Families()); have != "[noto dejavu noto-cjk]" {
+		t.Fatalf("have families %s", have)
+	}
+	if sty.Font.Kerning != FontKerningNone {
+		t.Fatalf("have kerning %q, want none", sty.Font.Kerning)
+	}
+}
+
+func TestFontWeightValue(t *testing.T) {
+	tests := []struct {
+		in  FontWeight
+		exp int
+	}{
+		{in: FontWeightNormal, exp: 400},
+		{in: FontWeightBold, exp: 700},
+		{in: "", exp: 400},
+		{in: "300", exp: 300},
+		{in: "950", exp: 900},
+		{in: "50", exp: 100},
+		{in: "heavy", exp: 400},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := test.in.Value()
+			if have != test.exp {
+				t.Fatalf("have %d, want %d", have, test.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package style
+
+import "github.com/pkg/errors"
+
+type ObjectFit string
+
+const (
+	ObjectFitFill    ObjectFit = "fill"
+	ObjectFitContain ObjectFit = "contain"
+	ObjectFitCover   ObjectFit = "cover"
+	ObjectFitNone    ObjectFit = "none"
+)
+
+type Image struct {
+	ObjectFit ObjectFit `style:"object-fit"`
+	// Dpi overrides the resolution of the image file, if greater than zero
+	Dpi float64 `style:"dpi"`
+}
+
+func (of *ObjectFit) UnmarshalStyle(v string) error {
+	switch fit := ObjectFit(trimWS(v)); fit {
+	case ObjectFitFill, ObjectFitContain, ObjectFitCover, ObjectFitNone:
+		*of = fit
+		return nil
+	}
+	return errors.Errorf("invalid object-fit (%s) - must be one of fill, contain, cover or none", v)
+}
@@ -0,0 +1,39 @@
+package style
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestObjectFit(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  ObjectFit
+		fail bool
+	}{
+		{in: "fill", exp: ObjectFitFill},
+		{in: " contain ", exp: ObjectFitContain},
+		{in: "cover", exp: ObjectFitCover},
+		{in: "none", exp: ObjectFitNone},
+		{in: "contian", fail: true},
+		{in: "", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var have ObjectFit
+			err := have.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
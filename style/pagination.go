@@ -0,0 +1,43 @@
+package style
+
+import "github.com/pkg/errors"
+
+type PageBreak string
+
+const (
+	PageBreakAuto   PageBreak = "auto"
+	PageBreakAlways PageBreak = "always"
+	PageBreakAvoid  PageBreak = "avoid"
+	PageBreakLeft   PageBreak = "left"
+	PageBreakRight  PageBreak = "right"
+)
+
+// Pagination are the styles of page breaks. Texts, tables and preformatted texts are kept together, images are never split.
+// Blocks are kept with the beginning of their successor, but grids and columns aren't measured in advance and can't be kept.
+type Pagination struct {
+	Widows          int       `style:"widows"`
+	Orphans         int       `style:"orphans"`
+	KeepTogether    bool      `style:"keep-together"`
+	KeepWithNext    bool      `style:"keep-with-next"`
+	PageBreakBefore PageBreak `style:"page-break-before"`
+	PageBreakAfter  PageBreak `style:"page-break-after"`
+}
+
+func (pb *PageBreak) UnmarshalStyle(v string) error {
+	switch b := PageBreak(trimWS(v)); b {
+	case PageBreakAuto, PageBreakAlways, PageBreakAvoid, PageBreakLeft, PageBreakRight:
+		*pb = b
+		return nil
+	}
+	return errors.Errorf("invalid page-break (%s) - must be one of auto, always, avoid, left or right", v)
+}
+
+// Forced reports if the page break has to be done unconditionally
+func (pb PageBreak) Forced() bool {
+	switch pb {
+	case PageBreakAlways, PageBreakLeft, PageBreakRight:
+		return true
+	default:
+		return false
+	}
+}
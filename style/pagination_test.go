@@ -0,0 +1,40 @@
+package style
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPageBreak(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  PageBreak
+		fail bool
+	}{
+		{in: "auto", exp: PageBreakAuto},
+		{in: " always ", exp: PageBreakAlways},
+		{in: "avoid", exp: PageBreakAvoid},
+		{in: "left", exp: PageBreakLeft},
+		{in: "right", exp: PageBreakRight},
+		{in: "page", fail: true},
+		{in: "", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var have PageBreak
+			err := have.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
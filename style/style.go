@@ -8,4 +8,8 @@ type Styles struct {
 	Align
 	Color
 	Draw
+	Pagination
+	Floating
+	Image
+	Typography
 }
@@ -62,6 +62,41 @@ func TestMutate(t *testing.T) {
 			},
 			decodeFail: false,
 		},
+		{
+			name:     "pagination",
+			inStyles: Styles{},
+			phrase:   "widows: 3; orphans: 4; keep-together: true; keep-with-next: true; page-break-before: right; page-break-after: avoid",
+			outStyles: Styles{
+				Pagination: Pagination{
+					Widows:          3,
+					Orphans:         4,
+					KeepTogether:    true,
+					KeepWithNext:    true,
+					PageBreakBefore: PageBreakRight,
+					PageBreakAfter:  PageBreakAvoid,
+				},
+			},
+			decodeFail: false,
+		},
+		{
+			name:       "pagination fail",
+			inStyles:   Styles{},
+			phrase:     "keep-together: sometimes",
+			outStyles:  Styles{},
+			decodeFail: true,
+		},
+		{
+			name:     "floating",
+			inStyles: Styles{},
+			phrase:   "float: right; clear: both",
+			outStyles: Styles{
+				Floating: Floating{
+					Float: FloatRight,
+					Clear: ClearBoth,
+				},
+			},
+			decodeFail: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,122 @@
+package style
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+type TabAlign string
+
+const (
+	TabAlignLeft    TabAlign = "left"
+	TabAlignRight   TabAlign = "right"
+	TabAlignCenter  TabAlign = "center"
+	TabAlignDecimal TabAlign = "decimal"
+)
+
+// TabStop is a position relative to the left edge of a text, where the text following a tab is aligned.
+// The space before it is filled with the leader character, if any.
+type TabStop struct {
+	Position float64
+	Align    TabAlign
+	Leader   rune
+}
+
+// MaxTabStops is the maximum number of tab stops of a text
+const MaxTabStops = 16
+
+// TabStops is a list of tab stops ordered by position. It is an array to keep the styles comparable.
+type TabStops struct {
+	Count int
+	Stops [MaxTabStops]TabStop
+}
+
+// UnmarshalStyle parses comma separated tab stops like "40mm, 100 right ., 120 decimal '-'".
+// Each stop is a length followed by an optional alignment (left, right, center, decimal) and an optional leader character.
+func (ts *TabStops) UnmarshalStyle(v string) error {
+	*ts = TabStops{}
+	v = trimWS(v)
+	if v == "" || v == "none" {
+		return nil
+	}
+	for _, s := range strings.Split(v, ",") {
+		fields := strings.Fields(s)
+		if len(fields) == 0 || len(fields) > 3 {
+			return errors.Errorf("invalid tab stop (%s)", s)
+		}
+		if ts.Count >= MaxTabStops {
+			return errors.Errorf("too many tab stops (max %d)", MaxTabStops)
+		}
+		pos, err := ParseLength(fields[0])
+		if err != nil {
+			return errors.Wrapf(err, "parse tab stop (%s)", s)
+		}
+		stop := TabStop{
+			Position: pos,
+			Align:    TabAlignLeft,
+		}
+		for _, f := range fields[1:] {
+			switch a := TabAlign(f); a {
+			case TabAlignLeft, TabAlignRight, TabAlignCenter, TabAlignDecimal:
+				stop.Align = a
+				continue
+			}
+			leader, err := parseLeader(f)
+			if err != nil {
+				return errors.Wrapf(err, "parse tab stop (%s)", s)
+			}
+			stop.Leader = leader
+		}
+		ts.Stops[ts.Count] = stop
+		ts.Count++
+	}
+	stops := ts.Stops[:ts.Count]
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].Position < stops[j].Position
+	})
+	return nil
+}
+
+// parseLeader parses a single character, which may be quoted
+func parseLeader(s string) (rune, error) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, errors.Errorf("invalid leader (%s) - must be a single character", s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r, nil
+}
+
+// Next returns the first tab stop right of x
+func (ts TabStops) Next(x float64) (TabStop, bool) {
+	for _, stop := range ts.Stops[:ts.Count] {
+		if stop.Position > x {
+			return stop, true
+		}
+	}
+	return TabStop{}, false
+}
+
+func (ts TabStops) String() string {
+	var sl []string
+	for _, stop := range ts.Stops[:ts.Count] {
+		s := fmt.Sprintf("%.1f %s", stop.Position, stop.Align)
+		if stop.Leader != 0 {
+			s += fmt.Sprintf(" '%c'", stop.Leader)
+		}
+		sl = append(sl, s)
+	}
+	return strings.Join(sl, ", ")
+}
+
+// MarshalJSON marshals the tab stops as string, so that style diffs can compare them
+func (ts TabStops) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ts.String())
+}
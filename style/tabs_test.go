@@ -0,0 +1,67 @@
+package style
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTabStops(t *testing.T) {
+	tests := []struct {
+		in   string
+		exp  string
+		fail bool
+	}{
+		{in: "", exp: ""},
+		{in: "none", exp: ""},
+		{in: "40mm", exp: "40.0 left"},
+		{in: "100 right ., 2cm", exp: "20.0 left, 100.0 right '.'"},
+		{in: "60 decimal '-', 80 center", exp: "60.0 decimal '-', 80.0 center"},
+		{in: "50 _ left", exp: "50.0 left '_'"},
+		{in: "50 right ..", fail: true},
+		{in: "x right", fail: true},
+		{in: "10,,20", fail: true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var ts TabStops
+			err := ts.UnmarshalStyle(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if ts.String() != test.exp {
+				t.Fatalf("have %q, want %q", ts.String(), test.exp)
+			}
+		})
+	}
+}
+
+func TestNextTabStop(t *testing.T) {
+	var ts TabStops
+	if err := ts.UnmarshalStyle("80 right, 20, 50 center"); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	tests := []struct {
+		x   float64
+		exp float64
+		ok  bool
+	}{
+		{x: 0, exp: 20, ok: true},
+		{x: 20, exp: 50, ok: true},
+		{x: 79.9, exp: 80, ok: true},
+		{x: 80, ok: false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			stop, ok := ts.Next(test.x)
+			if ok != test.ok || (ok && stop.Position != test.exp) {
+				t.Fatalf("have %v %t, want %f %t", stop, ok, test.exp, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package style
+
+type TextTransform string
+
+const (
+	TextTransformNone       TextTransform = "none"
+	TextTransformUppercase  TextTransform = "uppercase"
+	TextTransformLowercase  TextTransform = "lowercase"
+	TextTransformCapitalize TextTransform = "capitalize"
+)
+
+type Overflow string
+
+const (
+	// OverflowWrap breaks lines of preformatted text exceeding the available width
+	OverflowWrap Overflow = "wrap"
+	// OverflowClip cuts them off
+	OverflowClip Overflow = "clip"
+)
+
+type WhiteSpace string
+
+const (
+	// WhiteSpaceNormal collapses whitespace and line breaks and wraps lines
+	WhiteSpaceNormal WhiteSpace = "normal"
+	// WhiteSpacePre preserves whitespace and line breaks and doesn't wrap lines
+	WhiteSpacePre WhiteSpace = "pre"
+	// WhiteSpacePreWrap preserves whitespace and line breaks and wraps lines
+	WhiteSpacePreWrap WhiteSpace = "pre-wrap"
+	// WhiteSpaceNowrap collapses whitespace and line breaks and doesn't wrap lines
+	WhiteSpaceNowrap WhiteSpace = "nowrap"
+)
+
+// Preserves reports if whitespace and line breaks are kept as they are
+func (ws WhiteSpace) Preserves() bool {
+	return ws == WhiteSpacePre || ws == WhiteSpacePreWrap
+}
+
+// Wraps reports if lines are broken at the available width
+func (ws WhiteSpace) Wraps() bool {
+	return ws != WhiteSpacePre && ws != WhiteSpaceNowrap
+}
+
+type Hyphens string
+
+const (
+	// HyphensNone doesn't hyphenate words, even at soft hyphens
+	HyphensNone Hyphens = "none"
+	// HyphensManual hyphenates words only at soft hyphens
+	HyphensManual Hyphens = "manual"
+	// HyphensAuto hyphenates words at soft hyphens and, in justified text, by the hyphenation patterns
+	HyphensAuto Hyphens = "auto"
+)
+
+type Direction string
+
+const (
+	DirectionLTR Direction = "ltr"
+	// DirectionRTL writes text from right to left and mirrors the alignment and the columns of tables
+	DirectionRTL Direction = "rtl"
+)
+
+// Typography holds the spacing and transformation of text runs
+type Typography struct {
+	// LetterSpacing is the extra space after each character
+	LetterSpacing Length `style:"letter-spacing"`
+	// WordSpacing is the extra space of each space character
+	WordSpacing   Length        `style:"word-spacing"`
+	TextTransform TextTransform `style:"text-transform"`
+	TabStops      TabStops      `style:"tab-stops"`
+	Overflow      Overflow      `style:"overflow"`
+	WhiteSpace    WhiteSpace    `style:"white-space"`
+	Hyphens       Hyphens       `style:"hyphens"`
+	Direction     Direction     `style:"direction"`
+	// Lang is the language tag of the text like "de" or "fr-CH", which selects the hyphenation patterns
+	Lang string `style:"lang"`
+}
@@ -0,0 +1,118 @@
+package svg
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type rgb struct {
+	r, g, b int
+}
+
+// paint is a fill or stroke paint. Only plain colors are supported, gradients are painted with the color of their first stop.
+type paint struct {
+	none  bool
+	color rgb
+	// current is set for "currentColor"
+	current bool
+	// ref is the id of a referenced paint server like a gradient
+	ref string
+}
+
+var namedColors = map[string]rgb{
+	"black":   {0, 0, 0},
+	"white":   {255, 255, 255},
+	"red":     {255, 0, 0},
+	"lime":    {0, 255, 0},
+	"green":   {0, 128, 0},
+	"blue":    {0, 0, 255},
+	"yellow":  {255, 255, 0},
+	"cyan":    {0, 255, 255},
+	"aqua":    {0, 255, 255},
+	"magenta": {255, 0, 255},
+	"fuchsia": {255, 0, 255},
+	"gray":    {128, 128, 128},
+	"grey":    {128, 128, 128},
+	"silver":  {192, 192, 192},
+	"maroon":  {128, 0, 0},
+	"olive":   {128, 128, 0},
+	"purple":  {128, 0, 128},
+	"teal":    {0, 128, 128},
+	"navy":    {0, 0, 128},
+	"orange":  {255, 165, 0},
+}
+
+// parseColor parses named colors, #rgb, #rrggbb and rgb(r,g,b) with values or percentages
+func parseColor(s string) (rgb, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+	switch {
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return rgb{}, errors.Errorf("invalid color %q", s)
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return rgb{}, errors.Wrapf(err, "parse color %q", s)
+		}
+		return rgb{int(uint8(n >> 16)), int(uint8(n >> 8)), int(uint8(n))}, nil
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return rgb{}, errors.Errorf("invalid color %q", s)
+		}
+		var vs [3]int
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			factor := 1.0
+			if strings.HasSuffix(part, "%") {
+				part = strings.TrimSuffix(part, "%")
+				factor = 2.55
+			}
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return rgb{}, errors.Wrapf(err, "parse color %q", s)
+			}
+			v *= factor
+			switch {
+			case v < 0:
+				v = 0
+			case v > 255:
+				v = 255
+			}
+			vs[i] = int(v + 0.5)
+		}
+		return rgb{vs[0], vs[1], vs[2]}, nil
+	}
+	return rgb{}, errors.Errorf("unknown color %q", s)
+}
+
+func parsePaint(s string) (paint, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "none" || s == "transparent":
+		return paint{none: true}, nil
+	case s == "currentColor":
+		return paint{current: true}, nil
+	case strings.HasPrefix(s, "url("):
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return paint{}, errors.Errorf("invalid paint %q", s)
+		}
+		ref := strings.Trim(strings.TrimSpace(s[4:end]), `'"`)
+		return paint{ref: strings.TrimPrefix(ref, "#")}, nil
+	}
+	c, err := parseColor(s)
+	if err != nil {
+		return paint{}, err
+	}
+	return paint{color: c}, nil
+}
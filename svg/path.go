@@ -0,0 +1,383 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type opKind int
+
+const (
+	opMove opKind = iota
+	opLine
+	opCubic
+	opClose
+)
+
+// op is a path operation with absolute coordinates. Cubic curves use all three points, moves and lines only the last one.
+type op struct {
+	kind opKind
+	pts  [3]point
+}
+
+type point struct {
+	x, y float64
+}
+
+// path is a sequence of operations, where quadratic curves and arcs are already converted to cubic curves
+type path []op
+
+func (p *path) moveTo(pt point) {
+	*p = append(*p, op{kind: opMove, pts: [3]point{{}, {}, pt}})
+}
+
+func (p *path) lineTo(pt point) {
+	*p = append(*p, op{kind: opLine, pts: [3]point{{}, {}, pt}})
+}
+
+func (p *path) cubicTo(c0, c1, pt point) {
+	*p = append(*p, op{kind: opCubic, pts: [3]point{c0, c1, pt}})
+}
+
+func (p *path) close() {
+	*p = append(*p, op{kind: opClose})
+}
+
+// transformed returns the path with all points transformed by m
+func (p path) transformed(m matrix) path {
+	tp := make(path, len(p))
+	for i, o := range p {
+		tp[i] = o
+		for j := range o.pts {
+			tp[i].pts[j] = m.apply(o.pts[j])
+		}
+	}
+	return tp
+}
+
+// pathScanner reads numbers and flags of path data
+type pathScanner struct {
+	s   string
+	pos int
+}
+
+func (sc *pathScanner) skipSeparators() {
+	for sc.pos < len(sc.s) {
+		switch sc.s[sc.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			sc.pos++
+		default:
+			return
+		}
+	}
+}
+
+// hasNumber reports if the next token is a number
+func (sc *pathScanner) hasNumber() bool {
+	sc.skipSeparators()
+	if sc.pos >= len(sc.s) {
+		return false
+	}
+	c := sc.s[sc.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+func (sc *pathScanner) number() (float64, error) {
+	sc.skipSeparators()
+	start := sc.pos
+	if sc.pos < len(sc.s) && (sc.s[sc.pos] == '-' || sc.s[sc.pos] == '+') {
+		sc.pos++
+	}
+	dot := false
+	digits := false
+	for sc.pos < len(sc.s) {
+		c := sc.s[sc.pos]
+		if c >= '0' && c <= '9' {
+			digits = true
+			sc.pos++
+		} else if c == '.' && !dot {
+			dot = true
+			sc.pos++
+		} else {
+			break
+		}
+	}
+	if digits && sc.pos < len(sc.s) && (sc.s[sc.pos] == 'e' || sc.s[sc.pos] == 'E') {
+		exp := sc.pos + 1
+		if exp < len(sc.s) && (sc.s[exp] == '-' || sc.s[exp] == '+') {
+			exp++
+		}
+		if exp < len(sc.s) && sc.s[exp] >= '0' && sc.s[exp] <= '9' {
+			sc.pos = exp
+			for sc.pos < len(sc.s) && sc.s[sc.pos] >= '0' && sc.s[sc.pos] <= '9' {
+				sc.pos++
+			}
+		}
+	}
+	if !digits {
+		return 0, errors.Errorf("expect number at %d in %q", start, sc.s)
+	}
+	return strconv.ParseFloat(sc.s[start:sc.pos], 64)
+}
+
+// flag reads an arc flag, which may be written without separator to the following value
+func (sc *pathScanner) flag() (bool, error) {
+	sc.skipSeparators()
+	if sc.pos >= len(sc.s) {
+		return false, errors.Errorf("expect flag at end of %q", sc.s)
+	}
+	switch sc.s[sc.pos] {
+	case '0':
+		sc.pos++
+		return false, nil
+	case '1':
+		sc.pos++
+		return true, nil
+	default:
+		return false, errors.Errorf("invalid flag at %d in %q", sc.pos, sc.s)
+	}
+}
+
+func (sc *pathScanner) numbers(n int) ([]float64, error) {
+	vs := make([]float64, n)
+	for i := range vs {
+		v, err := sc.number()
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// parsePath parses svg path data
+func parsePath(d string) (path, error) {
+	var p path
+	sc := &pathScanner{s: d}
+	var cmd byte
+	var curr, start, lastCtrl point
+	var lastCmd byte
+	for {
+		sc.skipSeparators()
+		if sc.pos >= len(sc.s) {
+			break
+		}
+		c := sc.s[sc.pos]
+		if strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0 {
+			cmd = c
+			sc.pos++
+		} else if cmd == 0 || !sc.hasNumber() {
+			return nil, errors.Errorf("unexpected %q at %d in path", c, sc.pos)
+		}
+		rel := cmd >= 'a' && cmd <= 'z'
+		abs := func(x, y float64) point {
+			if rel {
+				return point{curr.x + x, curr.y + y}
+			}
+			return point{x, y}
+		}
+		reflected := func(cmds string) point {
+			if strings.IndexByte(cmds, lastCmd) >= 0 {
+				return point{2*curr.x - lastCtrl.x, 2*curr.y - lastCtrl.y}
+			}
+			return curr
+		}
+
+		switch cmd {
+		case 'Z', 'z':
+			p.close()
+			curr = start
+			lastCmd = 'Z'
+			continue
+		case 'M', 'm':
+			vs, err := sc.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			curr = abs(vs[0], vs[1])
+			start = curr
+			p.moveTo(curr)
+			//following coordinate pairs are implicit line-tos
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L', 'l':
+			vs, err := sc.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			curr = abs(vs[0], vs[1])
+			p.lineTo(curr)
+		case 'H', 'h':
+			v, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			if rel {
+				curr.x += v
+			} else {
+				curr.x = v
+			}
+			p.lineTo(curr)
+		case 'V', 'v':
+			v, err := sc.number()
+			if err != nil {
+				return nil, err
+			}
+			if rel {
+				curr.y += v
+			} else {
+				curr.y = v
+			}
+			p.lineTo(curr)
+		case 'C', 'c':
+			vs, err := sc.numbers(6)
+			if err != nil {
+				return nil, err
+			}
+			c0, c1, pt := abs(vs[0], vs[1]), abs(vs[2], vs[3]), abs(vs[4], vs[5])
+			p.cubicTo(c0, c1, pt)
+			lastCtrl, curr = c1, pt
+		case 'S', 's':
+			vs, err := sc.numbers(4)
+			if err != nil {
+				return nil, err
+			}
+			c0 := reflected("CcSs")
+			c1, pt := abs(vs[0], vs[1]), abs(vs[2], vs[3])
+			p.cubicTo(c0, c1, pt)
+			lastCtrl, curr = c1, pt
+		case 'Q', 'q':
+			vs, err := sc.numbers(4)
+			if err != nil {
+				return nil, err
+			}
+			q, pt := abs(vs[0], vs[1]), abs(vs[2], vs[3])
+			p.quadTo(curr, q, pt)
+			lastCtrl, curr = q, pt
+		case 'T', 't':
+			vs, err := sc.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			q := reflected("QqTt")
+			pt := abs(vs[0], vs[1])
+			p.quadTo(curr, q, pt)
+			lastCtrl, curr = q, pt
+		case 'A', 'a':
+			vs, err := sc.numbers(3)
+			if err != nil {
+				return nil, err
+			}
+			large, err := sc.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := sc.flag()
+			if err != nil {
+				return nil, err
+			}
+			end, err := sc.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			pt := abs(end[0], end[1])
+			p.arcTo(curr, vs[0], vs[1], vs[2], large, sweep, pt)
+			curr = pt
+		}
+		lastCmd = cmd
+	}
+	return p, nil
+}
+
+// quadTo adds the quadratic curve from p0 with control point q to pt as cubic curve
+func (p *path) quadTo(p0, q, pt point) {
+	c0 := point{p0.x + 2.0/3.0*(q.x-p0.x), p0.y + 2.0/3.0*(q.y-p0.y)}
+	c1 := point{pt.x + 2.0/3.0*(q.x-pt.x), pt.y + 2.0/3.0*(q.y-pt.y)}
+	p.cubicTo(c0, c1, pt)
+}
+
+// arcTo adds an elliptical arc from p0 to pt as cubic curves (see SVG implementation notes, endpoint to center parameterization)
+func (p *path) arcTo(p0 point, rx, ry, rotation float64, large, sweep bool, pt point) {
+	if p0 == pt {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.lineTo(pt)
+		return
+	}
+	phi := rotation * math.Pi / 180
+	sinPhi, cosPhi := math.Sin(phi), math.Cos(phi)
+	dx, dy := (p0.x-pt.x)/2, (p0.y-pt.y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	//scale up radii, if they are too small
+	lambda := (x1*x1)/(rx*rx) + (y1*y1)/(ry*ry)
+	if lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx, ry = rx*s, ry*s
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := 0.0
+	if num > 0 && den > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (p0.x+pt.x)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (p0.y+pt.y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta1 := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	//split into segments of at most 90 degrees
+	segs := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	if segs < 1 {
+		segs = 1
+	}
+	d := delta / float64(segs)
+	k := 4.0 / 3.0 * math.Tan(d/4)
+	ellipse := func(t float64) (point, point) {
+		sin, cos := math.Sin(t), math.Cos(t)
+		pos := point{
+			cx + rx*cos*cosPhi - ry*sin*sinPhi,
+			cy + rx*cos*sinPhi + ry*sin*cosPhi,
+		}
+		deriv := point{
+			-rx*sin*cosPhi - ry*cos*sinPhi,
+			-rx*sin*sinPhi + ry*cos*cosPhi,
+		}
+		return pos, deriv
+	}
+	t := theta1
+	for i := 0; i < segs; i++ {
+		a, da := ellipse(t)
+		b, db := ellipse(t + d)
+		c0 := point{a.x + k*da.x, a.y + k*da.y}
+		c1 := point{b.x - k*db.x, b.y - k*db.y}
+		if i == segs-1 {
+			b = pt
+		}
+		p.cubicTo(c0, c1, b)
+		t += d
+	}
+}
@@ -0,0 +1,352 @@
+package svg
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+// maxUseDepth limits nested <use> references
+const maxUseDepth = 8
+
+// state holds the inherited presentation attributes and the current transformation
+type state struct {
+	m           matrix
+	fill        paint
+	fillRule    string
+	stroke      paint
+	strokeWidth float64
+	color       rgb
+	fontSize    float64
+	fontWeight  string
+	fontStyle   string
+	textAnchor  string
+}
+
+type renderer struct {
+	img    *Image
+	canvas Canvas
+	font   style.Font
+}
+
+// properties returns the presentation attributes of n, where declarations in its style attribute take precedence
+func properties(n *node) map[string]string {
+	props := map[string]string{}
+	for k, v := range n.attrs {
+		props[k] = v
+	}
+	for _, decl := range strings.Split(n.attrs["style"], ";") {
+		k, v, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return props
+}
+
+// inherit returns the state for n and its children
+func (r *renderer) inherit(n *node, st state) state {
+	props := properties(n)
+	if v, ok := props["color"]; ok {
+		if c, err := parseColor(v); err == nil {
+			st.color = c
+		}
+	}
+	if v, ok := props["fill"]; ok {
+		if p, err := parsePaint(v); err == nil {
+			st.fill = p
+		}
+	}
+	if v, ok := props["stroke"]; ok {
+		if p, err := parsePaint(v); err == nil {
+			st.stroke = p
+		}
+	}
+	if v, ok := props["stroke-width"]; ok {
+		st.strokeWidth = length(v, r.img.viewBox[2])
+	}
+	if v, ok := props["fill-rule"]; ok {
+		st.fillRule = v
+	}
+	if v, ok := props["font-size"]; ok {
+		if fs, ok := absLength(v); ok {
+			st.fontSize = fs
+		}
+	}
+	if v, ok := props["font-weight"]; ok {
+		st.fontWeight = v
+	}
+	if v, ok := props["font-style"]; ok {
+		st.fontStyle = v
+	}
+	if v, ok := props["text-anchor"]; ok {
+		st.textAnchor = v
+	}
+	if v, ok := props["transform"]; ok {
+		if m, err := parseTransform(v); err == nil {
+			st.m = st.m.mul(m)
+		}
+	}
+	return st
+}
+
+// hidden reports if n is not rendered at all
+func hidden(n *node) bool {
+	props := properties(n)
+	return props["display"] == "none" || props["visibility"] == "hidden" || strings.TrimSpace(props["opacity"]) == "0"
+}
+
+func (r *renderer) render(n *node, st state, depth int) {
+	if hidden(n) {
+		return
+	}
+	switch n.name {
+	case "svg":
+		//nested viewports are placed at their position, but not clipped
+		x := length(n.attrs["x"], r.img.viewBox[2])
+		y := length(n.attrs["y"], r.img.viewBox[3])
+		st.m = st.m.mul(translate(x, y))
+		st = r.inherit(n, st)
+		for _, child := range n.children {
+			r.render(child, st, depth)
+		}
+	case "g", "a", "switch":
+		st = r.inherit(n, st)
+		for _, child := range n.children {
+			r.render(child, st, depth)
+		}
+	case "use":
+		href := n.attrs["href"]
+		ref, ok := r.img.ids[strings.TrimPrefix(href, "#")]
+		if !ok || depth >= maxUseDepth {
+			return
+		}
+		st = r.inherit(n, st)
+		x := length(n.attrs["x"], r.img.viewBox[2])
+		y := length(n.attrs["y"], r.img.viewBox[3])
+		st.m = st.m.mul(translate(x, y))
+		if ref.name == "symbol" {
+			st = r.inherit(ref, st)
+			for _, child := range ref.children {
+				r.render(child, st, depth+1)
+			}
+			return
+		}
+		r.render(ref, st, depth+1)
+	case "path":
+		st = r.inherit(n, st)
+		p, err := parsePath(n.attrs["d"])
+		if err != nil && len(p) == 0 {
+			return
+		}
+		r.paint(p, st)
+	case "rect", "circle", "ellipse", "line", "polyline", "polygon":
+		st = r.inherit(n, st)
+		r.paint(r.shape(n), st)
+	case "text":
+		st = r.inherit(n, st)
+		r.text(n, st)
+	}
+}
+
+// shape returns the path of a basic shape
+func (r *renderer) shape(n *node) path {
+	vbw, vbh := r.img.viewBox[2], r.img.viewBox[3]
+	attr := func(name string, ref float64) float64 {
+		return length(n.attrs[name], ref)
+	}
+	var p path
+	switch n.name {
+	case "rect":
+		x, y := attr("x", vbw), attr("y", vbh)
+		w, h := attr("width", vbw), attr("height", vbh)
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, rxok := absLength(n.attrs["rx"])
+		ry, ryok := absLength(n.attrs["ry"])
+		switch {
+		case rxok && !ryok:
+			ry = rx
+		case ryok && !rxok:
+			rx = ry
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx <= 0 || ry <= 0 {
+			p.moveTo(point{x, y})
+			p.lineTo(point{x + w, y})
+			p.lineTo(point{x + w, y + h})
+			p.lineTo(point{x, y + h})
+			p.close()
+			return p
+		}
+		p.moveTo(point{x + rx, y})
+		p.lineTo(point{x + w - rx, y})
+		p.arcTo(point{x + w - rx, y}, rx, ry, 0, false, true, point{x + w, y + ry})
+		p.lineTo(point{x + w, y + h - ry})
+		p.arcTo(point{x + w, y + h - ry}, rx, ry, 0, false, true, point{x + w - rx, y + h})
+		p.lineTo(point{x + rx, y + h})
+		p.arcTo(point{x + rx, y + h}, rx, ry, 0, false, true, point{x, y + h - ry})
+		p.lineTo(point{x, y + ry})
+		p.arcTo(point{x, y + ry}, rx, ry, 0, false, true, point{x + rx, y})
+		p.close()
+	case "circle", "ellipse":
+		cx, cy := attr("cx", vbw), attr("cy", vbh)
+		var rx, ry float64
+		if n.name == "circle" {
+			rx = attr("r", vbw)
+			ry = rx
+		} else {
+			rx, ry = attr("rx", vbw), attr("ry", vbh)
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		pts := []point{{cx + rx, cy}, {cx, cy + ry}, {cx - rx, cy}, {cx, cy - ry}, {cx + rx, cy}}
+		p.moveTo(pts[0])
+		for i := 1; i < len(pts); i++ {
+			p.arcTo(pts[i-1], rx, ry, 0, false, true, pts[i])
+		}
+		p.close()
+	case "line":
+		p.moveTo(point{attr("x1", vbw), attr("y1", vbh)})
+		p.lineTo(point{attr("x2", vbw), attr("y2", vbh)})
+	case "polyline", "polygon":
+		sc := &pathScanner{s: n.attrs["points"]}
+		for i := 0; sc.hasNumber(); i++ {
+			vs, err := sc.numbers(2)
+			if err != nil {
+				break
+			}
+			if i == 0 {
+				p.moveTo(point{vs[0], vs[1]})
+			} else {
+				p.lineTo(point{vs[0], vs[1]})
+			}
+		}
+		if n.name == "polygon" && len(p) > 0 {
+			p.close()
+		}
+	}
+	return p
+}
+
+// resolve returns the color of the paint. Referenced paint servers (gradients) are painted with the color of their first stop.
+func (r *renderer) resolve(p paint, st state) (rgb, bool) {
+	switch {
+	case p.none:
+		return rgb{}, false
+	case p.current:
+		return st.color, true
+	case p.ref != "":
+		ref, ok := r.img.ids[p.ref]
+		if !ok {
+			return rgb{}, false
+		}
+		for _, stop := range ref.children {
+			if stop.name != "stop" {
+				continue
+			}
+			c, err := parseColor(properties(stop)["stop-color"])
+			if err != nil {
+				return rgb{}, true
+			}
+			return c, true
+		}
+		return rgb{}, false
+	default:
+		return p.color, true
+	}
+}
+
+// emit passes the path to the canvas
+func (r *renderer) emit(p path) {
+	for _, o := range p {
+		switch o.kind {
+		case opMove:
+			r.canvas.MoveTo(o.pts[2].x, o.pts[2].y)
+		case opLine:
+			r.canvas.LineTo(o.pts[2].x, o.pts[2].y)
+		case opCubic:
+			r.canvas.CurveTo(o.pts[0].x, o.pts[0].y, o.pts[1].x, o.pts[1].y, o.pts[2].x, o.pts[2].y)
+		case opClose:
+			r.canvas.ClosePath()
+		}
+	}
+}
+
+// paint fills and strokes the path
+func (r *renderer) paint(p path, st state) {
+	if len(p) == 0 || p[0].kind != opMove {
+		return
+	}
+	tp := p.transformed(st.m)
+	if c, ok := r.resolve(st.fill, st); ok {
+		r.canvas.SetFillColor(c.r, c.g, c.b)
+		r.emit(tp)
+		r.canvas.FillPath(st.fillRule == "evenodd")
+	}
+	if c, ok := r.resolve(st.stroke, st); ok && st.strokeWidth > 0 {
+		r.canvas.SetDrawColor(c.r, c.g, c.b)
+		r.canvas.SetLineWidth(st.strokeWidth * st.m.scaleFactor())
+		r.emit(tp)
+		r.canvas.DrawPath()
+	}
+}
+
+// textContent returns the collapsed character data of n and its tspan children
+func textContent(n *node) string {
+	var sb strings.Builder
+	sb.WriteString(n.text)
+	for _, child := range n.children {
+		if child.name == "tspan" && !hidden(child) {
+			sb.WriteString(" ")
+			sb.WriteString(textContent(child))
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// text writes the text of n. Only the position is transformed, so rotated or skewed text is written upright.
+func (r *renderer) text(n *node, st state) {
+	s := textContent(n)
+	if s == "" {
+		return
+	}
+	c, ok := r.resolve(st.fill, st)
+	if !ok {
+		return
+	}
+	first := func(name string) string {
+		return strings.Fields(strings.ReplaceAll(n.attrs[name]+" ", ",", " ") + "0")[0]
+	}
+	pos := st.m.apply(point{
+		length(first("x"), r.img.viewBox[2]),
+		length(first("y"), r.img.viewBox[3]),
+	})
+
+	fnt := r.font
+	sizeMm := st.fontSize * st.m.scaleFactor()
+	fnt.PointSize = sizeMm * 72 / 25.4
+	switch st.fontWeight {
+	case "bold", "bolder":
+		fnt.Weight = style.FontWeightBold
+	case "100", "200", "300", "400", "500", "600", "700", "800", "900":
+		fnt.Weight = style.FontWeight(st.fontWeight)
+	}
+	switch st.fontStyle {
+	case "italic", "oblique":
+		fnt.Style = style.FontStyleItalic
+	}
+	r.canvas.ChangeFont(fnt)
+	switch st.textAnchor {
+	case "middle":
+		pos.x -= r.canvas.TextWidth(s) / 2
+	case "end":
+		pos.x -= r.canvas.TextWidth(s)
+	}
+	r.canvas.SetTextColor(c.r, c.g, c.b)
+	r.canvas.TextAt(pos.x, pos.y, s)
+}
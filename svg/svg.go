@@ -0,0 +1,267 @@
+// Package svg draws a practical subset of SVG (paths, basic shapes, fills, strokes, transforms and text)
+// with vector primitives of a Canvas.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/pkg/errors"
+)
+
+// Canvas is the target of drawing an image. Coordinates are in the unit of the canvas.
+type Canvas interface {
+	SetLineWidth(float64)
+	SetDrawColor(r, g, b int)
+	SetFillColor(r, g, b int)
+	SetTextColor(r, g, b int)
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	CurveTo(cx0, cy0, cx1, cy1, x, y float64)
+	ClosePath()
+	FillPath(evenOdd bool)
+	DrawPath()
+	ChangeFont(fnt style.Font)
+	TextWidth(s string) float64
+	TextAt(x, y float64, s string)
+}
+
+// pxPerMm is the resolution of svg user units (CSS pixels)
+const pxPerMm = 96 / 25.4
+
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+// Image is a parsed svg document
+type Image struct {
+	root *node
+	ids  map[string]*node
+	// width and height in mm
+	width  float64
+	height float64
+	// viewBox as x, y, width, height
+	viewBox  [4]float64
+	preserve string
+}
+
+// Sniff reports if data looks like a svg document
+func Sniff(data []byte) bool {
+	if len(data) > 1024 {
+		data = data[:1024]
+	}
+	return bytes.Contains(data, []byte("<svg"))
+}
+
+// Parse parses a svg document
+func Parse(r io.Reader) (*Image, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	img := &Image{
+		ids: map[string]*node{},
+	}
+	var stack []*node
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "decode svg")
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{
+				name:  t.Name.Local,
+				attrs: map[string]string{},
+			}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if id, ok := n.attrs["id"]; ok {
+				img.ids[id] = n
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if img.root == nil {
+				img.root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if img.root == nil || img.root.name != "svg" {
+		return nil, errors.Errorf("no svg root element")
+	}
+	err := img.initViewport()
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// ParseBytes parses a svg document from data
+func ParseBytes(data []byte) (*Image, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+func (img *Image) initViewport() error {
+	img.preserve = img.root.attrs["preserveAspectRatio"]
+	hasViewBox := false
+	if vb, ok := img.root.attrs["viewBox"]; ok {
+		sc := &pathScanner{s: vb}
+		vs, err := sc.numbers(4)
+		if err != nil {
+			return errors.Wrapf(err, "parse viewBox %q", vb)
+		}
+		if vs[2] <= 0 || vs[3] <= 0 {
+			return errors.Errorf("invalid viewBox %q - width and height must be positive", vb)
+		}
+		copy(img.viewBox[:], vs)
+		hasViewBox = true
+	}
+	//default size of the css replaced element
+	width, height := 300.0, 150.0
+	if hasViewBox {
+		width, height = img.viewBox[2], img.viewBox[3]
+	}
+	w, wok := absLength(img.root.attrs["width"])
+	h, hok := absLength(img.root.attrs["height"])
+	switch {
+	case wok && hok:
+		width, height = w, h
+	case wok:
+		height = w * height / width
+		width = w
+	case hok:
+		width = h * width / height
+		height = h
+	}
+	if width <= 0 || height <= 0 {
+		return errors.Errorf("invalid size %gx%g - width and height must be positive", width, height)
+	}
+	if !hasViewBox {
+		img.viewBox = [4]float64{0, 0, width, height}
+	}
+	img.width = width / pxPerMm
+	img.height = height / pxPerMm
+	return nil
+}
+
+// Size returns the intrinsic size of the image in mm
+func (img *Image) Size() (width, height float64) {
+	return img.width, img.height
+}
+
+// absLength parses a length with an absolute unit and returns it in user units. Percentages and invalid values return false.
+func absLength(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	factor := 1.0
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"px", 1},
+		{"mm", pxPerMm},
+		{"cm", 10 * pxPerMm},
+		{"in", 96},
+		{"pt", 96.0 / 72.0},
+		{"pc", 16},
+		{"em", 16},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			factor = u.factor
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v * factor, true
+}
+
+// length parses a length, where percentages are relative to ref
+func length(s string, ref float64) float64 {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil {
+			return 0
+		}
+		return v / 100 * ref
+	}
+	v, _ := absLength(s)
+	return v
+}
+
+// viewportMatrix maps the viewBox into the box at x,y with width and height according to preserveAspectRatio
+func (img *Image) viewportMatrix(x, y, width, height float64) matrix {
+	vb := img.viewBox
+	sx, sy := width/vb[2], height/vb[3]
+	fields := strings.Fields(img.preserve)
+	align := "xMidYMid"
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if align == "none" {
+		return translate(x, y).mul(scale(sx, sy)).mul(translate(-vb[0], -vb[1]))
+	}
+	s := math.Min(sx, sy)
+	if len(fields) > 1 && fields[1] == "slice" {
+		s = math.Max(sx, sy)
+	}
+	offset := func(axis string, space float64) float64 {
+		switch {
+		case strings.Contains(align, axis+"Min"):
+			return 0
+		case strings.Contains(align, axis+"Max"):
+			return space
+		default:
+			return space / 2
+		}
+	}
+	dx := offset("x", width-vb[2]*s)
+	dy := offset("Y", height-vb[3]*s)
+	return translate(x+dx, y+dy).mul(scale(s, s)).mul(translate(-vb[0], -vb[1]))
+}
+
+// Draw draws the image into the box at x,y with width and height. Text is written with fnt, where the size and weight are taken from the image.
+func (img *Image) Draw(c Canvas, x, y, width, height float64, fnt style.Font) {
+	r := &renderer{
+		img:    img,
+		canvas: c,
+		font:   fnt,
+	}
+	st := state{
+		m:           img.viewportMatrix(x, y, width, height),
+		fill:        paint{},
+		stroke:      paint{none: true},
+		strokeWidth: 1,
+		fontSize:    16,
+	}
+	st = r.inherit(img.root, st)
+	for _, child := range img.root.children {
+		r.render(child, st, 0)
+	}
+}
@@ -0,0 +1,300 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+func pathString(p path) string {
+	var sb strings.Builder
+	for _, o := range p {
+		switch o.kind {
+		case opMove:
+			fmt.Fprintf(&sb, "M%g,%g ", o.pts[2].x, o.pts[2].y)
+		case opLine:
+			fmt.Fprintf(&sb, "L%g,%g ", o.pts[2].x, o.pts[2].y)
+		case opCubic:
+			fmt.Fprintf(&sb, "C%g,%g ", o.pts[2].x, o.pts[2].y)
+		case opClose:
+			sb.WriteString("Z ")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		out  string
+		fail bool
+	}{
+		{
+			name: "absolute",
+			in:   "M10 20 L30 40 H50 V60 Z",
+			out:  "M10,20 L30,40 L50,40 L50,60 Z",
+		},
+		{
+			name: "relative with implicit line-to",
+			in:   "m10,20 10,0 0,10 h-10z",
+			out:  "M10,20 L20,20 L20,30 L10,30 Z",
+		},
+		{
+			name: "compact numbers",
+			in:   "M1.5.5L-1-2",
+			out:  "M1.5,0.5 L-1,-2",
+		},
+		{
+			name: "exponent",
+			in:   "M1e1,2E-1",
+			out:  "M10,0.2",
+		},
+		{
+			name: "curves",
+			in:   "M0,0 C1,1 2,1 3,0 S5,-1 6,0 Q7,1 8,0 T10,0",
+			out:  "M0,0 C3,0 C6,0 C8,0 C10,0",
+		},
+		{
+			name: "arc with compact flags",
+			in:   "M0,0 a5,5 0 105,5",
+			out:  "M0,0 C5,5",
+		},
+		{
+			name: "missing command",
+			in:   "10,20",
+			fail: true,
+		},
+		{
+			name: "missing number",
+			in:   "M10",
+			fail: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parsePath(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			// arcs are split into several curves, so only compare the end points of the last one
+			have := pathString(p)
+			if strings.HasPrefix(test.name, "arc") {
+				have = "M0,0 " + pathString(p[len(p)-1:])
+			}
+			if have != test.out {
+				t.Fatalf("have %q, want %q", have, test.out)
+			}
+		})
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		in   string
+		pt   point
+		out  point
+		fail bool
+	}{
+		{in: "translate(10,20)", pt: point{1, 1}, out: point{11, 21}},
+		{in: "scale(2)", pt: point{1, 3}, out: point{2, 6}},
+		{in: "translate(10) scale(2,3)", pt: point{1, 1}, out: point{12, 3}},
+		{in: "rotate(90)", pt: point{1, 0}, out: point{0, 1}},
+		{in: "rotate(90, 1, 1)", pt: point{2, 1}, out: point{1, 2}},
+		{in: "matrix(1,0,0,1,5,6)", pt: point{0, 0}, out: point{5, 6}},
+		{in: "skewX(45)", pt: point{0, 1}, out: point{1, 1}},
+		{in: "shear(1)", fail: true},
+		{in: "matrix(1,2)", fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			m, err := parseTransform(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			have := m.apply(test.pt)
+			if math.Abs(have.x-test.out.x) > 1e-9 || math.Abs(have.y-test.out.y) > 1e-9 {
+				t.Fatalf("have %v, want %v", have, test.out)
+			}
+		})
+	}
+}
+
+func TestParsePaint(t *testing.T) {
+	tests := []struct {
+		in   string
+		out  paint
+		fail bool
+	}{
+		{in: "none", out: paint{none: true}},
+		{in: "red", out: paint{color: rgb{255, 0, 0}}},
+		{in: "#0f0", out: paint{color: rgb{0, 255, 0}}},
+		{in: "#123456", out: paint{color: rgb{0x12, 0x34, 0x56}}},
+		{in: "rgb(10, 20, 100%)", out: paint{color: rgb{10, 20, 255}}},
+		{in: "currentColor", out: paint{current: true}},
+		{in: "url(#grad)", out: paint{ref: "grad"}},
+		{in: "#12345", fail: true},
+		{in: "chartreuse-ish", fail: true},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			p, err := parsePaint(test.in)
+			if test.fail {
+				if err == nil {
+					t.Fatalf("parse %q should fail but did not", test.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q failed: %v", test.in, err)
+			}
+			if p != test.out {
+				t.Fatalf("have %+v, want %+v", p, test.out)
+			}
+		})
+	}
+}
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		width  float64
+		height float64
+	}{
+		{
+			name:   "mm",
+			in:     `<svg width="60mm" height="30mm" viewBox="0 0 200 100"/>`,
+			width:  60,
+			height: 30,
+		},
+		{
+			name:   "viewBox only",
+			in:     `<svg viewBox="0 0 96 48"/>`,
+			width:  25.4,
+			height: 12.7,
+		},
+		{
+			name:   "width only",
+			in:     `<svg width="1in" viewBox="0 0 200 100"/>`,
+			width:  25.4,
+			height: 12.7,
+		},
+		{
+			name:   "default",
+			in:     `<svg/>`,
+			width:  300 / pxPerMm,
+			height: 150 / pxPerMm,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img, err := ParseBytes([]byte(test.in))
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			w, h := img.Size()
+			if math.Abs(w-test.width) > 1e-9 || math.Abs(h-test.height) > 1e-9 {
+				t.Fatalf("have %gx%g, want %gx%g", w, h, test.width, test.height)
+			}
+		})
+	}
+}
+
+func TestInvalidSize(t *testing.T) {
+	tests := []string{
+		`<svg width="0" height="50"/>`,
+		`<svg width="100" height="-10"/>`,
+		`<svg width="0"/>`,
+		`<svg viewBox="0 0 0 100"/>`,
+		`<svg width="100" height="50" viewBox="0 0 100 -50"/>`,
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			if _, err := ParseBytes([]byte(test)); err == nil {
+				t.Fatalf("expected error for %s", test)
+			}
+		})
+	}
+}
+
+type recordingCanvas struct {
+	ops []string
+}
+
+func (c *recordingCanvas) record(format string, args ...any) {
+	c.ops = append(c.ops, fmt.Sprintf(format, args...))
+}
+
+func (c *recordingCanvas) SetLineWidth(w float64)   { c.record("w %g", w) }
+func (c *recordingCanvas) SetDrawColor(r, g, b int) { c.record("RG %d %d %d", r, g, b) }
+func (c *recordingCanvas) SetFillColor(r, g, b int) { c.record("rg %d %d %d", r, g, b) }
+func (c *recordingCanvas) SetTextColor(r, g, b int) { c.record("tc %d %d %d", r, g, b) }
+func (c *recordingCanvas) MoveTo(x, y float64)      { c.record("m %g %g", x, y) }
+func (c *recordingCanvas) LineTo(x, y float64)      { c.record("l %g %g", x, y) }
+func (c *recordingCanvas) ClosePath()               { c.record("h") }
+func (c *recordingCanvas) DrawPath()                { c.record("S") }
+func (c *recordingCanvas) ChangeFont(fnt style.Font) {
+	c.record("font %s %.2f", fnt.Weight, fnt.PointSize)
+}
+func (c *recordingCanvas) TextWidth(s string) float64 {
+	return float64(len(s))
+}
+func (c *recordingCanvas) TextAt(x, y float64, s string) { c.record("text %g %g %s", x, y, s) }
+func (c *recordingCanvas) CurveTo(cx0, cy0, cx1, cy1, x, y float64) {
+	c.record("c %g %g", x, y)
+}
+func (c *recordingCanvas) FillPath(evenOdd bool) {
+	if evenOdd {
+		c.record("f*")
+		return
+	}
+	c.record("f")
+}
+
+func TestDraw(t *testing.T) {
+	in := `<svg viewBox="0 0 100 50" width="100" height="50">
+	<g fill="#00f" transform="translate(10,10)">
+		<rect width="20" height="10" stroke="red" stroke-width="2"/>
+		<line x1="0" y1="0" x2="5" y2="0" style="stroke: black; display: none"/>
+	</g>
+	<text x="50" y="40" font-size="10" text-anchor="middle" font-weight="bold">Hi</text>
+</svg>`
+	img, err := ParseBytes([]byte(in))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	c := &recordingCanvas{}
+	// draw into a box twice as large, so that one user unit is 2 units of the canvas
+	img.Draw(c, 0, 0, 200, 100, style.Font{PointSize: 10})
+	want := []string{
+		"rg 0 0 255",
+		"m 20 20", "l 60 20", "l 60 40", "l 20 40", "h",
+		"f",
+		"RG 255 0 0",
+		"w 4",
+		"m 20 20", "l 60 20", "l 60 40", "l 20 40", "h",
+		"S",
+		"font bold 56.69",
+		"tc 0 0 0",
+		"text 99 80 Hi",
+	}
+	if strings.Join(c.ops, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("have:\n%s\nwant:\n%s", strings.Join(c.ops, "\n"), strings.Join(want, "\n"))
+	}
+}
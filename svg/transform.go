@@ -0,0 +1,110 @@
+package svg
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// matrix is an affine transformation [a c e; b d f; 0 0 1]
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = matrix{a: 1, d: 1}
+
+func translate(tx, ty float64) matrix {
+	return matrix{a: 1, d: 1, e: tx, f: ty}
+}
+
+func scale(sx, sy float64) matrix {
+	return matrix{a: sx, d: sy}
+}
+
+func rotate(deg float64) matrix {
+	r := deg * math.Pi / 180
+	sin, cos := math.Sin(r), math.Cos(r)
+	return matrix{a: cos, b: sin, c: -sin, d: cos}
+}
+
+// mul returns m*n, which applies n first
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+func (m matrix) apply(p point) point {
+	return point{
+		x: m.a*p.x + m.c*p.y + m.e,
+		y: m.b*p.x + m.d*p.y + m.f,
+	}
+}
+
+// scaleFactor returns the average scaling of m, which is used for line widths and font sizes
+func (m matrix) scaleFactor() float64 {
+	return math.Sqrt(math.Abs(m.a*m.d - m.b*m.c))
+}
+
+// parseTransform parses a svg transform list like "translate(10,20) rotate(45)"
+func parseTransform(s string) (matrix, error) {
+	m := identity
+	s = strings.TrimSpace(s)
+	for s != "" {
+		open := strings.IndexByte(s, '(')
+		close := strings.IndexByte(s, ')')
+		if open < 0 || close < open {
+			return identity, errors.Errorf("invalid transform %q", s)
+		}
+		name := strings.TrimSpace(s[:open])
+		sc := &pathScanner{s: s[open+1 : close]}
+		var args []float64
+		for sc.hasNumber() {
+			v, err := sc.number()
+			if err != nil {
+				return identity, errors.Wrapf(err, "transform %q", name)
+			}
+			args = append(args, v)
+		}
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+		if len(args) == 0 {
+			return identity, errors.Errorf("transform %q without arguments", name)
+		}
+
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				return identity, errors.Errorf("matrix needs 6 arguments, got %d", len(args))
+			}
+			t = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		case "translate":
+			t = translate(arg(0, 0), arg(1, 0))
+		case "scale":
+			t = scale(arg(0, 1), arg(1, arg(0, 1)))
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = translate(cx, cy).mul(rotate(arg(0, 0))).mul(translate(-cx, -cy))
+		case "skewX":
+			t = matrix{a: 1, c: math.Tan(arg(0, 0) * math.Pi / 180), d: 1}
+		case "skewY":
+			t = matrix{a: 1, b: math.Tan(arg(0, 0) * math.Pi / 180), d: 1}
+		default:
+			return identity, errors.Errorf("unknown transform %q", name)
+		}
+		m = m.mul(t)
+		s = strings.TrimLeft(s[close+1:], " \t\n\r,")
+	}
+	return m, nil
+}
@@ -0,0 +1,213 @@
+package xpdf
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+const (
+	// distance of the default tab stops, which apply beyond the styled ones
+	defaultTabInterval = 12.5
+	// space between leaders and the surrounding text
+	leaderPad = 1.0
+)
+
+// tabItem is the space of a text line up to a tab stop
+type tabItem struct {
+	width  float64
+	leader rune
+}
+
+// tabState tracks the text following a tab in a line, which is aligned at the tab stop
+type tabState struct {
+	item *textItem
+	stop style.TabStop
+	// x is the start of the tab and target the position of the stop, both relative to the line
+	x      float64
+	target float64
+	// seg is the width of the text following the tab and point the width up to its decimal separator, if already found
+	seg   float64
+	point float64
+}
+
+// lineWidth returns the width of the line, if the text following the tab has the width seg
+func (t *tabState) lineWidth(seg float64) float64 {
+	var adjust float64
+	switch t.stop.Align {
+	case style.TabAlignRight:
+		adjust = seg
+	case style.TabAlignCenter:
+		adjust = seg / 2
+	case style.TabAlignDecimal:
+		adjust = seg
+		if t.point >= 0 {
+			adjust = t.point
+		}
+	}
+	return t.x + math.Max(0, t.target-t.x-adjust) + seg
+}
+
+// align updates the width of the tab and the line
+func (t *tabState) align(l *textLine) {
+	l.width = t.lineWidth(t.seg)
+	t.item.tab.width = l.width - t.x - t.seg
+}
+
+// nextTabStop returns the tab stop following x. Beyond the styled stops, there are left aligned stops at regular intervals.
+func nextTabStop(stops style.TabStops, x float64) style.TabStop {
+	if stop, ok := stops.Next(x); ok {
+		return stop
+	}
+	return style.TabStop{
+		Position: (math.Floor(x/defaultTabInterval) + 1) * defaultTabInterval,
+		Align:    style.TabAlignLeft,
+	}
+}
+
+// addTab adds a tab to the line, which aligns the following text at the next tab stop
+func (p *Processor) addTab(l *textLine, sty style.Styles) {
+	stop := nextTabStop(sty.TabStops, l.indent+l.width)
+	item := &textItem{
+		sty:   sty,
+		glued: true,
+		tab:   &tabItem{leader: stop.Leader},
+	}
+	l.items = append(l.items, item)
+	l.tab = &tabState{
+		item:   item,
+		stop:   stop,
+		x:      l.width,
+		target: stop.Position - l.indent,
+		point:  -1,
+	}
+	l.tab.align(l)
+}
+
+// addItem adds item with the given width to the line. Text following a tab is aligned at its stop.
+func (p *Processor) addItem(l *textLine, item *textItem, width float64) {
+	l.items = append(l.items, item)
+	t := l.tab
+	if t == nil {
+		l.width += width
+		return
+	}
+	if t.stop.Align == style.TabAlignDecimal && t.point < 0 {
+		//the last separator of the first word containing one is the decimal separator, so that "1,234.50" and "1.234,50" both work
+		if idx := strings.LastIndexAny(item.text, ".,"); idx >= 0 {
+			t.point = t.seg + p.runWidth(item.text[:idx], item.sty)
+		}
+	}
+	t.seg += width
+	t.align(l)
+}
+
+// overflows reports if an item of the given width doesn't fit into the line
+func (l *textLine) overflows(width float64) bool {
+	if l.tab == nil {
+		return l.width+width >= l.avail
+	}
+	//text aligned at a tab stop may end exactly at the end of the line
+	return l.tab.lineWidth(l.tab.seg+width) > l.avail+1e-9
+}
+
+// hasTabs reports if the line contains tabs. Such lines are not justified.
+func (l *textLine) hasTabs() bool {
+	for _, item := range l.items {
+		if item.tab != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// putTab moves the current position over the tab of the item and draws its leader
+func (p *Processor) putTab(item *textItem) {
+	if item.tab == nil {
+		return
+	}
+	x, _ := p.engine.GetXY()
+	if item.tab.leader != 0 {
+		p.engine.ChangeFont(item.sty.Font)
+		leader := string(item.tab.leader)
+		lw := p.engine.TextWidth(leader)
+		//leaders are spaced by their own width and end at the stop
+		step := 2 * lw
+		n := int((item.tab.width - 2*leaderPad + lw) / step)
+		if n > 0 {
+			p.engine.SetX(x + item.tab.width - leaderPad - float64(n)*step + lw)
+			p.engine.SetCharSpacing(step - lw)
+			p.engine.WriteText(strings.Repeat(leader, n))
+			p.engine.SetCharSpacing(0)
+		}
+	}
+	p.engine.SetX(x + item.tab.width)
+}
+
+// expandTabs splits text runs at tab characters into runs and tabs. Tabs in whitespace containing a line break
+// are regarded as indentation of the document source.
+func expandTabs(iss []xdoc.Instruction) []xdoc.Instruction {
+	var expanded []xdoc.Instruction
+	for _, is := range iss {
+		switch is := is.(type) {
+		case *xdoc.TextBlock:
+			for i, s := range splitTabs(is.Text) {
+				if i > 0 {
+					expanded = append(expanded, &xdoc.Tab{})
+				}
+				expanded = append(expanded, &xdoc.TextBlock{Text: s})
+			}
+		case *xdoc.Paragraph:
+			for i, s := range splitTabs(is.Text) {
+				if i > 0 {
+					expanded = append(expanded, &xdoc.Tab{})
+				}
+				para := *is
+				para.Text = s
+				expanded = append(expanded, &para)
+			}
+		default:
+			expanded = append(expanded, is)
+		}
+	}
+	return expanded
+}
+
+// splitTabs splits s at tabs, which are not part of whitespace containing a line break
+func splitTabs(s string) []string {
+	if !strings.Contains(s, "\t") {
+		return []string{s}
+	}
+	var parts []string
+	var curr strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		if !unicode.IsSpace(rs[i]) {
+			curr.WriteRune(rs[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(rs) && unicode.IsSpace(rs[j]) {
+			j++
+		}
+		ws := string(rs[i:j])
+		if strings.ContainsAny(ws, "\r\n") {
+			curr.WriteString(ws)
+		} else {
+			for _, r := range ws {
+				if r == '\t' {
+					parts = append(parts, curr.String())
+					curr.Reset()
+					continue
+				}
+				curr.WriteRune(r)
+			}
+		}
+		i = j
+	}
+	return append(parts, curr.String())
+}
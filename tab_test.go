@@ -0,0 +1,110 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+func TestSplitTabs(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp []string
+	}{
+		{in: "no tabs", exp: []string{"no tabs"}},
+		{in: "Coffee\t2.50", exp: []string{"Coffee", "2.50"}},
+		{in: "a\t\tb\t", exp: []string{"a", "", "b", ""}},
+		{in: "a \t b", exp: []string{"a ", " b"}},
+		{in: "\n\t\tindented\n\t", exp: []string{"\n\t\tindented\n\t"}},
+		{in: "line\n\tnext\tcol", exp: []string{"line\n\tnext", "col"}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := splitTabs(test.in)
+			if fmt.Sprintf("%q", have) != fmt.Sprintf("%q", test.exp) {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	iss := expandTabs([]xdoc.Instruction{
+		&xdoc.TextBlock{Text: "a\tb"},
+		&xdoc.LineBreak{},
+		&xdoc.Paragraph{Text: "c\td"},
+	})
+	var sl []string
+	for _, is := range iss {
+		switch is := is.(type) {
+		case *xdoc.TextBlock:
+			sl = append(sl, is.Text)
+		case *xdoc.Paragraph:
+			sl = append(sl, "p:"+is.Text)
+		case *xdoc.Tab:
+			sl = append(sl, "tab")
+		case *xdoc.LineBreak:
+			sl = append(sl, "br")
+		}
+	}
+	if have, exp := strings.Join(sl, " "), "a tab b br p:c tab p:d"; have != exp {
+		t.Fatalf("have %q, want %q", have, exp)
+	}
+}
+
+func TestNextTabStop(t *testing.T) {
+	var stops style.TabStops
+	if err := stops.UnmarshalStyle("30 right"); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	tests := []struct {
+		x   float64
+		exp float64
+	}{
+		{x: 0, exp: 30},
+		{x: 30, exp: 37.5},
+		{x: 40, exp: 50},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := nextTabStop(stops, test.x)
+			if have.Position != test.exp {
+				t.Fatalf("have %f, want %f", have.Position, test.exp)
+			}
+		})
+	}
+}
+
+func TestTabLineWidth(t *testing.T) {
+	tests := []struct {
+		align style.TabAlign
+		point float64
+		seg   float64
+		exp   float64
+	}{
+		{align: style.TabAlignLeft, point: -1, seg: 10, exp: 60},
+		{align: style.TabAlignRight, point: -1, seg: 10, exp: 50},
+		{align: style.TabAlignCenter, point: -1, seg: 10, exp: 55},
+		{align: style.TabAlignDecimal, point: 4, seg: 10, exp: 56},
+		{align: style.TabAlignDecimal, point: -1, seg: 10, exp: 50},
+		{align: style.TabAlignRight, point: -1, seg: 50, exp: 70},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			ts := &tabState{
+				stop:   style.TabStop{Position: 50, Align: test.align},
+				x:      20,
+				target: 50,
+				point:  test.point,
+			}
+			have := ts.lineWidth(test.seg)
+			if math.Abs(have-test.exp) > 1e-9 {
+				t.Fatalf("have %f, want %f", have, test.exp)
+			}
+		})
+	}
+}
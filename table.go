@@ -81,6 +81,24 @@ func (row *tableRow) maxCellHeight() float64 {
 	return max
 }
 
+// height returns the height of all rows
+func (t *table) height() float64 {
+	var height float64
+	for _, row := range t.rows {
+		height += row.maxCellHeight()
+	}
+	return height
+}
+
+// headHeight returns the height of the header rows, which are repeated on each page, and the first row after them
+func (t *table) headHeight(repeatHeader int) float64 {
+	var height float64
+	for i := 0; i < repeatHeader+1 && i < len(t.rows); i++ {
+		height += t.rows[i].maxCellHeight()
+	}
+	return height
+}
+
 type tableCell struct {
 	style.Styles
 	iss []xdoc.Instruction
@@ -341,25 +359,34 @@ func (p *Processor) renderTable(xtab *xdoc.Table) {
 	if tab.columnCount == 0 {
 		return
 	}
+	p.keepTogether(tab.Styles, tab.height())
 
 	page := p.page()
 	x0, y := p.engine.GetXY()
 
 	renderRow := func(row *tableRow) {
+		var rowWidth float64
+		for _, cell := range row.cells {
+			rowWidth += cell.width
+		}
 		x := x0
 		for _, cell := range row.cells {
+			cx := x
+			x += cell.width
 			if cell.spannedBy != nil || cell.zero {
-				x += cell.width
 				continue
 			}
 			cw, ch := cell.dim()
+			if rtl(tab.Styles) {
+				//the columns of right-to-left tables are mirrored
+				cx = x0 + rowWidth - (cx - x0) - cw
+			}
 			p.renderCell(PrintableArea{
-				x0: x,
+				x0: cx,
 				y0: y,
-				x1: x + cw,
+				x1: cx + cw,
 				y1: y + ch,
 			}, cell)
-			x += cell.width
 		}
 		y += row.height
 		p.engine.SetX(x0)
@@ -367,22 +394,18 @@ func (p *Processor) renderTable(xtab *xdoc.Table) {
 
 	//check if we have to start a new page for the entire table
 	if !p.preventPageBreak {
-		var headHeight float64
-		for i := 0; i < xtab.RepeatHeader+1; i++ {
-			if i < len(tab.rows) {
-				headHeight += tab.rows[i].maxCellHeight()
-			}
-		}
-		if y+headHeight > page.printableArea.y1 {
-			p.engine.AddPage()
+		if y+tab.headHeight(xtab.RepeatHeader) > page.printableArea.y1 {
+			x0 = p.newPageAt(x0)
 			_, y = p.engine.GetXY()
+			page = p.page()
 		}
 	}
 
 	for i, row := range tab.rows {
 		if !p.preventPageBreak && y+row.maxCellHeight() > page.printableArea.y1 {
-			p.engine.AddPage()
+			x0 = p.newPageAt(x0)
 			_, y = p.engine.GetXY()
+			page = p.page()
 			if i > 0 && xtab.RepeatHeader > 0 {
 				for rhr := 0; rhr < xtab.RepeatHeader; rhr++ {
 					if rhr >= 0 && rhr < len(tab.rows) {
@@ -397,6 +420,8 @@ func (p *Processor) renderTable(xtab *xdoc.Table) {
 }
 
 func (p *Processor) renderCell(pa PrintableArea, cell *tableCell) {
+	//rows are paginated as a whole
+	defer p.keepPageBreakPrevention()()
 	p.drawBox(pa.x0, pa.y0, pa.x1, pa.y1, cell.Styles)
 
 	paddedPa := pa.WithPadding(cell.Padding)
@@ -413,7 +438,7 @@ func (p *Processor) renderCell(pa PrintableArea, cell *tableCell) {
 		}
 		p.engine.SetX(paddedPa.x0)
 
-		p.writeTextFnc(cell.Styles)(cell.iss, pa.Width()-cell.Padding.Left-cell.Padding.Right, cell.Styles)
+		p.writeTextFnc(cell.Styles)(cell.iss, fixedSpan(pa.Width()-cell.Padding.Left-cell.Padding.Right), cell.Styles)
 	}
 
 	p.engine.SetX(paddedPa.x0)
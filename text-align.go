@@ -0,0 +1,129 @@
+package xpdf
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+)
+
+const (
+	// font size of superscripts and subscripts relative to the surrounding text
+	scriptScale = 0.6
+	// baseline shift of superscripts and subscripts relative to the font height of the surrounding text
+	superRise = 0.3
+	subRise   = -0.15
+)
+
+// baselineShift returns how far the baseline of an inline element is raised in a text with the given font height
+func baselineShift(va style.VerticalAlign, fontHeight float64) float64 {
+	switch va.Mode {
+	case style.VerticalAlignSuper:
+		return superRise * fontHeight
+	case style.VerticalAlignSub:
+		return subRise * fontHeight
+	case style.VerticalAlignLength:
+		return va.Length
+	default:
+		return 0
+	}
+}
+
+// extent returns how far an inline element with the given offset from the line top and height exceeds a text line
+func extent(top, height, fontHeight float64) (above, below float64) {
+	if top < 0 {
+		above = -top
+	}
+	if bottom := top + height; bottom > fontHeight {
+		below = bottom - fontHeight
+	}
+	return
+}
+
+// shiftedRun returns the styles of an inline run, where super- and subscripts are scaled down,
+// and the offset of the run's text from the top of the line
+func (p *Processor) shiftedRun(sty style.Styles) (style.Styles, float64) {
+	switch sty.VerticalAlign.Mode {
+	case style.VerticalAlignSuper, style.VerticalAlignSub, style.VerticalAlignLength:
+	default:
+		return sty, 0
+	}
+	p.engine.ChangeFont(sty.Font)
+	height := p.engine.FontHeight()
+	shift := baselineShift(sty.VerticalAlign, height)
+	rsty := sty
+	if sty.VerticalAlign.Mode != style.VerticalAlignLength {
+		rsty.Font.PointSize *= scriptScale
+	}
+	p.engine.ChangeFont(rsty.Font)
+	return rsty, baselineRatio*(height-p.engine.FontHeight()) - shift
+}
+
+// runItem returns the text item of an inline run like a paragraph in a text with styles sty.
+// The vertical alignment of the text doesn't apply to the run.
+func (p *Processor) runItem(run xdoc.Instruction, text string, sty style.Styles) *textItem {
+	base := sty
+	base.VerticalAlign = style.VerticalAlign{Mode: style.VerticalAlignBaseline}
+	psty, dy := p.shiftedRun(run.MutatedStyles(p.doc.StyleClasses(), base))
+	return &textItem{
+		sty:  psty,
+		text: text,
+		dy:   dy,
+	}
+}
+
+// runExtent returns how far the text of a shifted item exceeds a line with the given font height
+func (p *Processor) runExtent(item *textItem, fontHeight float64) (above, below float64) {
+	if item.dy == 0 {
+		return 0, 0
+	}
+	p.engine.ChangeFont(item.sty.Font)
+	return extent(item.dy, p.engine.FontHeight(), fontHeight)
+}
+
+// writeItemText writes s of item into the line starting at lineTop
+func (p *Processor) writeItemText(item *textItem, s string, lineTop float64) {
+	if item.dy == 0 {
+		p.writeRun(s, item.sty)
+		return
+	}
+	p.engine.SetY(lineTop + item.dy)
+	p.writeRun(s, item.sty)
+	p.engine.SetY(lineTop)
+}
+
+// runJoint tracks, if a text run is glued to the preceding one. Runs are glued, if there is no breaking whitespace between them.
+// Text following a tab is always glued to it.
+type runJoint struct {
+	// open is true, if the preceding run doesn't end with collapsible whitespace
+	open bool
+	tab  bool
+}
+
+// glues reports if the run with the raw text s follows the preceding one without space. The whitespace of preserved runs
+// is part of their text.
+func (j runJoint) glues(s string, preserved bool) bool {
+	if j.tab {
+		return strings.TrimSpace(s) != ""
+	}
+	if s == "" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	if preserved {
+		return j.open || isBreakingSpace(r)
+	}
+	return j.open && !isBreakingSpace(r)
+}
+
+// follow records the run with the raw text s as the preceding one
+func (j *runJoint) follow(s string, preserved bool) {
+	if j.tab && strings.TrimSpace(s) == "" {
+		return
+	}
+	r, _ := utf8.DecodeLastRuneInString(s)
+	*j = runJoint{
+		open: s != "" && (preserved || !isBreakingSpace(r)),
+	}
+}
@@ -0,0 +1,83 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+func TestBaselineShift(t *testing.T) {
+	tests := []struct {
+		va  style.VerticalAlign
+		exp float64
+	}{
+		{va: style.VerticalAlign{Mode: style.VerticalAlignBaseline}, exp: 0},
+		{va: style.VerticalAlign{Mode: style.VerticalAlignMiddle}, exp: 0},
+		{va: style.VerticalAlign{Mode: style.VerticalAlignSuper}, exp: 3},
+		{va: style.VerticalAlign{Mode: style.VerticalAlignSub}, exp: -1.5},
+		{va: style.VerticalAlign{Mode: style.VerticalAlignLength, Length: -2}, exp: -2},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := baselineShift(test.va, 10)
+			if math.Abs(have-test.exp) > 1e-9 {
+				t.Fatalf("have %f, want %f", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestExtent(t *testing.T) {
+	tests := []struct {
+		top, height  float64
+		above, below float64
+	}{
+		{top: 0, height: 10},
+		{top: 2, height: 6},
+		{top: -2, height: 6, above: 2},
+		{top: 6, height: 6, below: 2},
+		{top: -1, height: 12, above: 1, below: 1},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			above, below := extent(test.top, test.height, 10)
+			if math.Abs(above-test.above) > 1e-9 || math.Abs(below-test.below) > 1e-9 {
+				t.Fatalf("have %f/%f, want %f/%f", above, below, test.above, test.below)
+			}
+		})
+	}
+}
+
+func TestRunJoint(t *testing.T) {
+	type run struct {
+		raw       string
+		preserved bool
+	}
+	tests := []struct {
+		runs []run
+		exp  []bool
+	}{
+		{runs: []run{{"m", false}, {"2", false}, {", and", false}}, exp: []bool{false, true, true}},
+		{runs: []run{{"m ", false}, {"2", false}, {" and", false}}, exp: []bool{false, false, false}},
+		{runs: []run{{"foo", false}, {"bar", false}}, exp: []bool{false, true}},
+		{runs: []run{{"H", false}, {"2", false}, {"", false}, {"O", false}}, exp: []bool{false, true, false, false}},
+		{runs: []run{{"10\u00a0", false}, {"km", false}}, exp: []bool{false, true}},
+		{runs: []run{{"foo\n", false}, {"\u202fbar", false}}, exp: []bool{false, false}},
+		{runs: []run{{"foo ", false}, {"  bar", true}, {"baz  ", true}, {"qux", false}}, exp: []bool{false, true, true, true}},
+		{runs: []run{{"foo ", false}, {"bar", true}}, exp: []bool{false, false}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			var j runJoint
+			for k, r := range test.runs {
+				have := j.glues(r.raw, r.preserved)
+				if have != test.exp[k] {
+					t.Fatalf("run %d: have %t, want %t", k, have, test.exp[k])
+				}
+				j.follow(r.raw, r.preserved)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package xpdf
+
+import (
+	"strings"
+
+	"github.com/mazzegi/xpdf/hyphenation"
+	"github.com/mazzegi/xpdf/style"
+)
+
+const (
+	softHyphen     = '\u00ad'
+	zeroWidthSpace = '\u200b'
+)
+
+// breakHints are invisible characters marking break opportunities in words
+var breakHints = strings.NewReplacer(string(softHyphen), "", string(zeroWidthSpace), "")
+
+// cleanWord returns word without break hints
+func cleanWord(word string) string {
+	return breakHints.Replace(word)
+}
+
+// wordPart is a part of a word between break opportunities. If the word is broken after a hyphenated part, a hyphen is added.
+type wordPart struct {
+	text   string
+	hyphen bool
+}
+
+// wordParts splits word at its break opportunities, which are zero width spaces and, unless hyphens is none, soft hyphens.
+// Parts without soft hyphens are hyphenated by the patterns of the text's language, if hyphens is auto and auto hyphenation applies.
+func (p *Processor) wordParts(word string, sty style.Styles, auto bool) []wordPart {
+	var hyp *hyphenation.Hyphenator
+	if sty.Hyphens == style.HyphensAuto && auto {
+		hyp = p.hyphenatorFor(sty.Lang)
+	}
+	var parts []wordPart
+	for _, seg := range strings.Split(word, string(zeroWidthSpace)) {
+		var sub []string
+		switch {
+		case sty.Hyphens == style.HyphensNone:
+			sub = []string{strings.ReplaceAll(seg, string(softHyphen), "")}
+		case strings.ContainsRune(seg, softHyphen):
+			sub = strings.Split(seg, string(softHyphen))
+		case hyp != nil:
+			sub = hyp.Hyphenate(seg)
+		default:
+			sub = []string{seg}
+		}
+		var texts []string
+		for _, s := range sub {
+			if s != "" {
+				texts = append(texts, s)
+			}
+		}
+		for i, s := range texts {
+			parts = append(parts, wordPart{text: s, hyphen: i < len(texts)-1})
+		}
+	}
+	return parts
+}
+
+// joinParts returns the text of the parts
+func joinParts(parts []wordPart) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		sb.WriteString(part.text)
+	}
+	return sb.String()
+}
+
+// hintedParts returns the text of the parts, where the break opportunities are marked by break hints
+func hintedParts(parts []wordPart) string {
+	var sb strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			if parts[i-1].hyphen {
+				sb.WriteRune(softHyphen)
+			} else {
+				sb.WriteRune(zeroWidthSpace)
+			}
+		}
+		sb.WriteString(part.text)
+	}
+	return sb.String()
+}
+
+// breakWord breaks word at the last break opportunity, where the head preceded by lead fits. The head ends with a hyphen,
+// if the word is hyphenated there. The tail keeps the break hints of the remaining opportunities.
+func (p *Processor) breakWord(word, lead string, sty style.Styles, fits func(width float64) bool, auto bool) (head, tail string, ok bool) {
+	parts := p.wordParts(word, sty, auto)
+	for i := len(parts) - 2; i >= 0; i-- {
+		head = lead + joinParts(parts[:i+1])
+		if parts[i].hyphen {
+			head += "-"
+		}
+		if fits(p.runWidth(head, sty)) {
+			return head, hintedParts(parts[i+1:]), true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,70 @@
+package xpdf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mazzegi/xpdf/hyphenation"
+	"github.com/mazzegi/xpdf/style"
+)
+
+func TestWordParts(t *testing.T) {
+	tests := []struct {
+		in      string
+		hyphens style.Hyphens
+		lang    string
+		exp     string
+	}{
+		{in: "word", hyphens: style.HyphensAuto, exp: "[{word false}]"},
+		{in: "hy\u00adphen\u00adation", hyphens: style.HyphensManual, exp: "[{hy true} {phen true} {ation false}]"},
+		{in: "hy\u00adphen\u00adation", hyphens: style.HyphensNone, exp: "[{hyphenation false}]"},
+		{in: "a/\u200bb/\u200bc", hyphens: style.HyphensNone, exp: "[{a/ false} {b/ false} {c false}]"},
+		{in: "ab\u00ad\u200bcd\u00ad", hyphens: style.HyphensAuto, exp: "[{ab false} {cd false}]"},
+		{in: "long\u00adword\u200bnext", hyphens: style.HyphensManual, exp: "[{long true} {word false} {next false}]"},
+		{in: "Fenster", hyphens: style.HyphensAuto, lang: "de", exp: "[{Fens true} {ter false}]"},
+		{in: "Fenster", hyphens: style.HyphensAuto, lang: "de-AT", exp: "[{Fens true} {ter false}]"},
+		{in: "Fenster", hyphens: style.HyphensManual, lang: "de", exp: "[{Fenster false}]"},
+		{in: "Fenster", hyphens: style.HyphensAuto, lang: "xx", exp: "[{Fenster false}]"},
+	}
+	p := &Processor{hyphenators: map[string]*hyphenation.Hyphenator{}}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := fmt.Sprintf("%v", p.wordParts(test.in, style.Styles{Typography: style.Typography{Hyphens: test.hyphens, Lang: test.lang}}, true))
+			if have != test.exp {
+				t.Fatalf("have %s, want %s", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestHintedParts(t *testing.T) {
+	parts := []wordPart{{text: "long", hyphen: true}, {text: "word"}, {text: "next"}}
+	have := hintedParts(parts)
+	if exp := "long\u00adword\u200bnext"; have != exp {
+		t.Fatalf("have %q, want %q", have, exp)
+	}
+	if clean := cleanWord(have); clean != "longwordnext" {
+		t.Fatalf("clean: have %q", clean)
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "two words", exp: "[{two false} {words false}]"},
+		{in: "a well-known path/to", exp: "[{a false} {well- false} {known true} {path/ false} {to true}]"},
+		{in: "日本語 ok", exp: "[{日 false} {本 true} {語 true} {ok false}]"},
+		{in: "a  b", exp: "[{a false} { false} {b false}]"},
+	}
+	p := &Processor{}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := fmt.Sprintf("%v", p.words(test.in))
+			if have != test.exp {
+				t.Fatalf("have %s, want %s", have, test.exp)
+			}
+		})
+	}
+}
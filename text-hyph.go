@@ -8,94 +8,141 @@ import (
 	"github.com/mazzegi/xpdf/xdoc"
 )
 
-func (p *Processor) textLinesHyphenated(iss []xdoc.Instruction, width float64, sty style.Styles) []textLine {
-	norm := func(s string) string {
-		return text.WhitespaceRectified(p.tr(s))
-	}
-	tryHyphenate := func(s string, currWidth float64) (s1 string, s2 string, success bool) {
-		success = false
-		availWidth := width - currWidth
-		parts := p.hyphenator.Hyphenate(s)
-		if len(parts) < 2 {
-			return
-		}
-		for i := len(parts) - 2; i >= 0; i-- {
-			trial := " " + strings.Join(parts[:i+1], "") + "-"
-			trialWidth := p.engine.TextWidth(trial)
-			if trialWidth <= availWidth {
-				s1 = trial
-				s2 = strings.Join(parts[i+1:], "")
-				success = true
-				return
-			}
-		}
-		return
-	}
-
+func (p *Processor) textLinesHyphenated(iss []xdoc.Instruction, span lineSpan, sty style.Styles) []textLine {
 	lines := []textLine{}
-	curr := textLine{}
-	for _, is := range iss {
+	curr := newTextLine(span, nil)
+	footnotes := p.footnoteNumber
+	p.engine.ChangeFont(sty.Font)
+	fontHeight := p.engine.FontHeight()
+	var joint runJoint
+	for _, is := range expandTabs(iss) {
 		var isitem *textItem
+		var raw string
 		switch is := is.(type) {
+		case *xdoc.Tab:
+			p.addTab(&curr, sty)
+			joint = runJoint{tab: true}
+			continue
 		case *xdoc.LineBreak:
 			if len(lines) > 0 {
 				lines[len(lines)-1].paragraph = true
 			}
 
 			lines = append(lines, curr)
-			curr = textLine{}
+			curr = newTextLine(span, lines)
 			continue
 		case *xdoc.TextBlock:
 			isitem = &textItem{
 				sty:  sty,
-				text: norm(is.Text),
+				text: is.Text,
 			}
+			raw = is.Text
 		case *xdoc.Paragraph:
-			isitem = &textItem{
-				sty:  is.MutatedStyles(p.doc.StyleClasses(), sty),
-				text: norm(is.Text),
+			isitem = p.runItem(is, is.Text, sty)
+			raw = is.Text
+		case *xdoc.Code:
+			isitem = p.codeItem(is, is.Text, sty)
+			raw = is.Text
+		case *xdoc.InlineImage:
+			item, itemWidth, ok := p.inlineImageItem(is, sty)
+			if !ok {
+				continue
+			}
+			if curr.overflows(itemWidth) && len(curr.items) > 0 {
+				lines = append(lines, curr)
+				curr = newTextLine(span, lines)
+			}
+			if len(curr.items) > 0 {
+				item.text = " "
 			}
+			p.addItem(&curr, item, itemWidth)
+			curr.pureTextWidth += item.image.width
+			curr.extend(item.image.extent(p.engine.FontHeight()))
+			joint = runJoint{}
+			continue
+		case *xdoc.Footnote:
+			footnotes++
+			item, itemWidth := p.footnoteItem(is, footnotes, sty)
+			joint = runJoint{open: true}
+			p.addItem(&curr, item, itemWidth)
+			curr.pureTextWidth += itemWidth
+			curr.extend(p.runExtent(item, fontHeight))
+			continue
+		case *footnoteMark:
+			item, itemWidth := p.footnoteMarkItem(is.number, sty)
+			joint = runJoint{}
+			p.addItem(&curr, item, itemWidth)
+			curr.pureTextWidth += itemWidth
+			curr.extend(p.runExtent(item, fontHeight))
+			continue
 		default:
 			continue
 		}
 
-		p.changeFont(isitem.sty.Font)
-		words := p.words(isitem.text)
-		for _, word := range words {
-			item := &textItem{
-				sty:  isitem.sty,
-				text: word,
+		ws := isitem.sty.WhiteSpace
+		isitem.text = text.ShapeArabic(transformed(normalizedRun(p.tr(isitem.text), ws), isitem.sty.TextTransform))
+		glue := joint.glues(raw, ws.Preserves()) && len(curr.items) > 0
+		joint.follow(raw, ws.Preserves())
+		if isitem.text == "" {
+			continue
+		}
+		above, below := p.runExtent(isitem, fontHeight)
+		p.engine.ChangeFont(isitem.sty.Font)
+		for k, segment := range strings.Split(isitem.text, "\n") {
+			if k > 0 {
+				//preserved line breaks end a paragraph
+				curr.paragraph = true
+				lines = append(lines, curr)
+				curr = newTextLine(span, lines)
+				glue = false
 			}
-			itemWidth := p.engine.TextWidth(" " + item.text)
-			pureItemWidth := p.engine.TextWidth(item.text)
-			if curr.width+itemWidth >= width {
-				//try hyphenation
-				s1, s2, ok := tryHyphenate(item.text, curr.width)
-				if !ok {
-					lines = append(lines, curr)
-					curr = textLine{}
-				} else {
-					curr.items = append(curr.items, &textItem{
-						text: s1,
-						sty:  item.sty,
-					})
-					curr.width += p.engine.TextWidth(s1)
-					curr.pureTextWidth += p.engine.TextWidth(strings.Trim(s1, " "))
+			for i, w := range p.words(segment) {
+				word := w.text
+				item := &textItem{
+					sty:  isitem.sty,
+					text: cleanWord(word),
+					dy:   isitem.dy,
+				}
+				itemWidth := p.runWidth(" "+item.text, item.sty)
+				if w.glued || (i == 0 && glue) {
+					item.glued = true
+					itemWidth = p.runWidth(item.text, item.sty)
+				}
+				pureItemWidth := p.runWidth(item.text, item.sty)
+				for ws.Wraps() && curr.overflows(itemWidth) {
+					//try hyphenation
+					s1, s2, ok := p.breakWord(word, curr.lead(item), item.sty, curr.fits, true)
+					if !ok && len(curr.items) == 0 {
+						break
+					}
+					if ok {
+						p.addItem(&curr, &textItem{
+							text:  s1,
+							sty:   item.sty,
+							glued: item.glued,
+							dy:    item.dy,
+						}, p.runWidth(s1, item.sty))
+						curr.pureTextWidth += p.runWidth(strings.Trim(s1, " "), item.sty)
+						curr.extend(above, below)
+						word = s2
+						item.text = cleanWord(s2)
+						pureItemWidth = p.runWidth(item.text, item.sty)
+					}
 					lines = append(lines, curr)
-
-					curr = textLine{}
-					item.text = s2
-					itemWidth = p.engine.TextWidth(s2)
-					pureItemWidth = p.engine.TextWidth(strings.Trim(s2, " "))
+					curr = newTextLine(span, lines)
+					itemWidth = p.runWidth(item.text, item.sty)
 				}
-			}
 
-			if len(curr.items) > 0 {
-				item.text = " " + item.text
+				if len(curr.items) > 0 && !item.glued {
+					item.text = " " + item.text
+				} else if !item.glued {
+					//the first word of a line has no leading space
+					itemWidth = p.runWidth(item.text, item.sty)
+				}
+				p.addItem(&curr, item, itemWidth)
+				curr.pureTextWidth += pureItemWidth
+				curr.extend(above, below)
 			}
-			curr.items = append(curr.items, item)
-			curr.width += itemWidth
-			curr.pureTextWidth += pureItemWidth
 		}
 	}
 	if len(curr.items) > 0 {
@@ -106,36 +153,70 @@ func (p *Processor) textLinesHyphenated(iss []xdoc.Instruction, width float64, s
 }
 
 func (p *Processor) textHeightHyphenated(iss []xdoc.Instruction, width float64, sty style.Styles) float64 {
-	p.engine.ChangeFont(sty.Font)
-	lines := p.textLinesHyphenated(iss, width, sty)
-	lineHeight := p.engine.FontHeight() * sty.Dimension.LineSpacing
-	//subtract line-spacing, to have no space below the last line
-	return float64(len(lines))*lineHeight - lineHeight + p.engine.FontHeight()
+	return p.linesHeight(p.textLinesHyphenated(iss, fixedSpan(width), sty), sty)
 }
 
-func (p *Processor) writeTextHyphenated(iss []xdoc.Instruction, width float64, sty style.Styles) {
+func (p *Processor) writeTextHyphenated(iss []xdoc.Instruction, span lineSpan, sty style.Styles) {
 	p.engine.ChangeFont(sty.Font)
 	p.engine.SetTextColor(sty.Text.Values())
-	lines := p.textLinesHyphenated(iss, width, sty)
+	footnotes := p.footnoteNumber
+	lines := p.textLinesHyphenated(iss, span, sty)
 	xLeft, _ := p.engine.GetXY()
-	for _, line := range lines {
-		p.engine.SetX(xLeft)
-		spaceCnt := len(line.items) - 1
-		if spaceCnt < 1 || line.paragraph {
+	fontHeight := p.engine.FontHeight()
+	lineBreak := p.lineBreaks(lines, fontHeight*sty.LineSpacing, sty)
+	for i := 0; i < len(lines); i++ {
+		for lineBreak(i) || !p.footnotesFit(lines[i], fontHeight) {
+			xLeft = p.newPageAt(xLeft)
+			//header and footer have reset the styles
+			p.engine.ChangeFont(sty.Font)
+			p.engine.SetTextColor(sty.Text.Values())
+			if _, y := p.engine.GetXY(); span.pageBreak(i, xLeft, y) {
+				lines = p.relayout(footnotes, func() []textLine { return p.textLinesHyphenated(iss, span, sty) })
+				p.engine.ChangeFont(sty.Font)
+				lineBreak = p.lineBreaks(lines, fontHeight*sty.LineSpacing, sty)
+			}
+		}
+		line := lines[i]
+		_, lineTop := p.engine.GetXY()
+		lineTop += line.spaceAbove()
+		p.engine.SetY(lineTop)
+		line = visualLine(line, rtl(sty))
+		spaceCnt := line.spaces()
+		justified := spaceCnt > 0 && !line.paragraph && !line.hasTabs() && !line.preserved()
+		if rtl(sty) && !justified {
+			//unjustified lines of right-to-left text start at the right
+			p.engine.SetX(xLeft + line.indent + line.avail - line.width)
+		} else {
+			p.engine.SetX(xLeft + line.indent)
+		}
+		bottom := lineTop + fontHeight + line.below
+		if !justified {
 			for _, item := range line.items {
-				p.changeFont(item.sty.Font)
-				p.engine.WriteText(item.text)
+				p.engine.ChangeFont(item.sty.Font)
+				p.writeItemText(item, item.text, lineTop)
+				p.putInlineImage(item, lineTop, fontHeight)
+				p.putTab(item)
+				p.putFootnote(item, bottom)
 			}
 		} else {
 			//subtract another 0.1 to avoid page breaks on equal widths
-			spaceWidth := (width - 0.1 - line.pureTextWidth) / float64(spaceCnt)
-			for _, item := range line.items {
-				p.changeFont(item.sty.Font)
-				p.engine.WriteText(strings.Trim(item.text, " "))
-				cx, _ := p.engine.GetXY()
-				p.engine.SetX(cx + spaceWidth)
+			spaceWidth := (line.avail - 0.1 - line.pureTextWidth) / float64(spaceCnt)
+			for i, item := range line.items {
+				if i > 0 && !item.glued {
+					cx, _ := p.engine.GetXY()
+					p.engine.SetX(cx + spaceWidth)
+				}
+				p.engine.ChangeFont(item.sty.Font)
+				p.writeItemText(item, strings.Trim(item.text, " "), lineTop)
+				p.putInlineImage(item, lineTop, fontHeight)
+				p.putTab(item)
+				p.putFootnote(item, bottom)
 			}
 		}
 		p.engine.LineFeed(sty.LineSpacing)
+		if line.below > 0 {
+			_, y := p.engine.GetXY()
+			p.engine.SetY(y + line.below)
+		}
 	}
 }
@@ -0,0 +1,145 @@
+package xpdf
+
+import (
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/xdoc"
+	"github.com/pkg/errors"
+)
+
+// the engine places the baseline of a text line at this ratio of the font height
+const baselineRatio = 0.8
+
+// inlineImage is an image, which is placed inside a text line
+type inlineImage struct {
+	name   string
+	data   []byte
+	desc   ImageDescriptor
+	sty    style.Styles
+	width  float64
+	height float64
+	align  style.VerticalAlign
+}
+
+// resolvedImage is the content of an image resource with its descriptor or the error resolving it
+type resolvedImage struct {
+	data []byte
+	desc ImageDescriptor
+	err  error
+}
+
+// resolveInlineImage returns the content and descriptor of the image of img. They are resolved once per instruction,
+// as text is laid out several times for measuring and writing.
+func (p *Processor) resolveInlineImage(img *xdoc.InlineImage) *resolvedImage {
+	if ri, ok := p.inlineImages[img]; ok {
+		return ri
+	}
+	ri := &resolvedImage{}
+	ri.data, ri.err = p.resource(img.Source)
+	if ri.err == nil {
+		ri.desc, ri.err = DescribeImageData(ri.data)
+		if ri.err != nil {
+			ri.err = errors.Wrapf(ri.err, "describe image %q", img.Source)
+		}
+	}
+	if p.inlineImages == nil {
+		p.inlineImages = map[*xdoc.InlineImage]*resolvedImage{}
+	}
+	p.inlineImages[img] = ri
+	return ri
+}
+
+// inlineImage returns the image of img sized by its styles. Without width and height, the image gets the height of the font.
+func (p *Processor) inlineImage(img *xdoc.InlineImage, sty style.Styles) (*inlineImage, error) {
+	ri := p.resolveInlineImage(img)
+	if ri.err != nil {
+		return nil, ri.err
+	}
+	data, iDesc := ri.data, ri.desc
+	//width and height of the surrounding text don't apply to the image
+	base := sty
+	base.Width, base.Height = 0, 0
+	isty := img.MutatedStyles(p.doc.StyleClasses(), base)
+
+	idWidth, idHeight := iDesc.SizeMm(isty.Image.Dpi)
+	width, height := isty.Width, isty.Height
+	switch {
+	case width > 0 && height > 0:
+	case width <= 0 && height > 0:
+		width = idWidth / idHeight * height
+	case width > 0 && height <= 0:
+		height = idHeight / idWidth * width
+	default:
+		p.engine.ChangeFont(isty.Font)
+		height = p.engine.FontHeight()
+		width = idWidth / idHeight * height
+	}
+	return &inlineImage{
+		name:   img.Source,
+		data:   data,
+		desc:   iDesc,
+		sty:    isty,
+		width:  width,
+		height: height,
+		align:  isty.VerticalAlign,
+	}, nil
+}
+
+// top returns the top of the image in a text line, which starts at y
+func (img *inlineImage) top(y, fontHeight float64) float64 {
+	switch img.align.Mode {
+	case style.VerticalAlignMiddle:
+		return y + (fontHeight-img.height)/2
+	default:
+		return y + baselineRatio*fontHeight - baselineShift(img.align, fontHeight) - img.height
+	}
+}
+
+// extent returns how far the image exceeds a text line above and below
+func (img *inlineImage) extent(fontHeight float64) (above, below float64) {
+	return extent(img.top(0, fontHeight), img.height, fontHeight)
+}
+
+// extend enlarges the space above and below the line
+func (l *textLine) extend(above, below float64) {
+	if above > l.above {
+		l.above = above
+	}
+	if below > l.below {
+		l.below = below
+	}
+}
+
+// extraHeight returns the space of the lines exceeding the regular line height
+func extraHeight(lines []textLine) float64 {
+	var h float64
+	for _, l := range lines {
+		h += l.spaceAbove() + l.below
+	}
+	return h
+}
+
+// inlineImageItem returns the text item of an inline image and its width including the leading space
+func (p *Processor) inlineImageItem(img *xdoc.InlineImage, sty style.Styles) (*textItem, float64, bool) {
+	inl, err := p.inlineImage(img, sty)
+	if err != nil {
+		Logf("ERROR: inline image: %v", err)
+		return nil, 0, false
+	}
+	p.engine.ChangeFont(sty.Font)
+	return &textItem{
+		sty:   sty,
+		image: inl,
+	}, p.runWidth(" ", sty) + inl.width, true
+}
+
+// putInlineImage puts the image of the item at the current x position of a line starting at lineTop
+func (p *Processor) putInlineImage(item *textItem, lineTop, fontHeight float64) {
+	if item.image == nil {
+		return
+	}
+	x, _ := p.engine.GetXY()
+	p.putImage(item.image.name, item.image.data, item.image.desc, x, item.image.top(lineTop, fontHeight), item.image.width, item.image.height, item.image.sty)
+	p.engine.ChangeFont(item.sty.Font)
+	p.engine.SetTextColor(item.sty.Text.Values())
+	p.engine.SetX(x + item.image.width)
+}
@@ -0,0 +1,279 @@
+package xpdf
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/text"
+)
+
+// font size of small capitals relative to the capitals of the font
+const smallCapsScale = 0.7
+
+// transformed returns s with the text transform tt applied
+func transformed(s string, tt style.TextTransform) string {
+	switch tt {
+	case style.TextTransformUppercase:
+		return strings.ToUpper(s)
+	case style.TextTransformLowercase:
+		return strings.ToLower(s)
+	case style.TextTransformCapitalize:
+		var sb strings.Builder
+		start := true
+		for _, r := range s {
+			if start {
+				r = unicode.ToTitle(r)
+			}
+			start = unicode.IsSpace(r)
+			sb.WriteRune(r)
+		}
+		return sb.String()
+	default:
+		return s
+	}
+}
+
+// runPart is a part of a text run, which is written in one font
+type runPart struct {
+	text  string
+	small bool
+}
+
+// smallCapsParts splits s into parts of lowercase letters, which are written as small capitals, and parts of other characters
+func smallCapsParts(s string) []runPart {
+	var parts []runPart
+	for _, r := range s {
+		small := unicode.IsLower(r)
+		if small {
+			r = unicode.ToUpper(r)
+		}
+		if n := len(parts); n > 0 && parts[n-1].small == small {
+			parts[n-1].text += string(r)
+			continue
+		}
+		parts = append(parts, runPart{text: string(r), small: small})
+	}
+	return parts
+}
+
+// plainRun reports if text with styles sty is written as is
+func plainRun(sty style.Styles) bool {
+	return sty.LetterSpacing == 0 && sty.WordSpacing == 0 && sty.Font.Variant != style.FontVariantSmallCaps
+}
+
+// runParts returns the parts of s written in one font
+func runParts(s string, sty style.Styles) []runPart {
+	if sty.Font.Variant == style.FontVariantSmallCaps {
+		return smallCapsParts(s)
+	}
+	return []runPart{{text: s}}
+}
+
+// partFont returns the font of a run part
+func partFont(part runPart, sty style.Styles) style.Font {
+	fnt := sty.Font
+	if part.small {
+		fnt.PointSize *= smallCapsScale
+	}
+	return fnt
+}
+
+// spacing returns the letter and word spacing, which is added to the width of s
+func spacing(s string, sty style.Styles) float64 {
+	return float64(utf8.RuneCountInString(s))*float64(sty.LetterSpacing) + float64(strings.Count(s, " "))*float64(sty.WordSpacing)
+}
+
+// kerned reports if the kerning pairs of the font are applied to text with styles sty
+func kerned(sty style.Styles) bool {
+	return sty.Font.Kerning != style.FontKerningNone
+}
+
+// ligated reports if the standard ligatures of the font are used for text with styles sty.
+// Like in browsers, letter spacing disables them.
+func ligated(sty style.Styles) bool {
+	return sty.Font.Ligatures != style.FontLigaturesNone && sty.LetterSpacing == 0
+}
+
+// kernedPart is a part of a text, which is followed by the kerning of its last character and the next one
+type kernedPart struct {
+	text string
+	kern float64
+}
+
+// shaped returns the parts of s in the current font split at kerned pairs, where ligatures are substituted
+// as enabled by sty. Right-to-left text is reversed for display, so it isn't shaped.
+func (p *Processor) shaped(s string, sty style.Styles) []kernedPart {
+	if text.HasRTL(s) {
+		return []kernedPart{{text: s}}
+	}
+	if ligated(sty) {
+		s = p.engine.Ligatures(s)
+	}
+	if !kerned(sty) {
+		return []kernedPart{{text: s}}
+	}
+	var parts []kernedPart
+	start := 0
+	var prev rune
+	for i, r := range s {
+		if i > 0 {
+			if kern := p.engine.Kerning(prev, r); kern != 0 {
+				parts = append(parts, kernedPart{text: s[start:i], kern: kern})
+				start = i
+			}
+		}
+		prev = r
+	}
+	return append(parts, kernedPart{text: s[start:]})
+}
+
+// shapedWidth returns the width of s in the current font including kerning and ligatures, but without spacing
+func (p *Processor) shapedWidth(s string, sty style.Styles) float64 {
+	var width float64
+	for _, part := range p.shaped(s, sty) {
+		width += p.engine.TextWidth(part.text) + part.kern
+	}
+	return width
+}
+
+// writeShaped writes s in the current font at the current position like shapedWidth measures it.
+// The position is moved behind s including the letter and word spacing of sty.
+func (p *Processor) writeShaped(s string, sty style.Styles) {
+	parts := p.shaped(s, sty)
+	if len(parts) == 1 {
+		p.engine.WriteText(parts[0].text)
+		return
+	}
+	x, _ := p.engine.GetXY()
+	for _, part := range parts {
+		p.engine.SetX(x)
+		p.engine.WriteText(part.text)
+		x += p.engine.TextWidth(part.text) + spacing(part.text, sty) + part.kern
+	}
+	p.engine.SetX(x)
+}
+
+// fallbackRun is a part of a text run, which is written in one family of the font list of the run
+type fallbackRun struct {
+	text string
+	sty  style.Styles
+}
+
+// fallbackRuns splits s into the parts written in one family of the font list of sty. Characters are written in the
+// first family having a glyph for them, whitespace and combining marks in the one of the preceding character.
+// Characters without a glyph in any family keep the list, so they are written in its first defined family.
+func (p *Processor) fallbackRuns(s string, sty style.Styles) []fallbackRun {
+	families := sty.Font.Families()
+	if len(families) < 2 {
+		return []fallbackRun{{text: s, sty: sty}}
+	}
+	var runs []fallbackRun
+	start := 0
+	for i, r := range s {
+		n := len(runs)
+		var family string
+		if n > 0 && (unicode.IsSpace(r) || unicode.In(r, unicode.Mn, unicode.Me) || r == '\u200d') {
+			family = runs[n-1].sty.Font.Family
+		} else {
+			family = p.fallbackFamily(r, sty.Font, families)
+		}
+		if n > 0 && runs[n-1].sty.Font.Family == family {
+			continue
+		}
+		if n > 0 {
+			runs[n-1].text = s[start:i]
+			start = i
+		}
+		rsty := sty
+		rsty.Font.Family = family
+		runs = append(runs, fallbackRun{sty: rsty})
+	}
+	if len(runs) == 0 {
+		return []fallbackRun{{text: s, sty: sty}}
+	}
+	runs[len(runs)-1].text = s[start:]
+	return runs
+}
+
+// fallbackFamily returns the first of the families of fnt having a glyph for r or the whole list, if there is none
+func (p *Processor) fallbackFamily(r rune, fnt style.Font, families []string) string {
+	list := fnt.Family
+	for _, family := range families {
+		fnt.Family = family
+		if p.engine.HasGlyph(fnt, r) {
+			return family
+		}
+	}
+	return list
+}
+
+// fallsBack reports if runs aren't written as a whole in the font of sty
+func fallsBack(runs []fallbackRun, sty style.Styles) bool {
+	return len(runs) > 1 || runs[0].sty.Font.Family != sty.Font.Family
+}
+
+// runWidth returns the width of s written with the styles sty including letter and word spacing.
+// The font of sty is the current font afterwards.
+func (p *Processor) runWidth(s string, sty style.Styles) float64 {
+	if runs := p.fallbackRuns(s, sty); fallsBack(runs, sty) {
+		var width float64
+		for _, run := range runs {
+			width += p.runWidth(run.text, run.sty)
+		}
+		p.engine.ChangeFont(sty.Font)
+		return width
+	}
+	p.engine.ChangeFont(sty.Font)
+	if plainRun(sty) {
+		return p.shapedWidth(s, sty)
+	}
+	var width float64
+	for _, part := range runParts(s, sty) {
+		p.engine.ChangeFont(partFont(part, sty))
+		width += p.shapedWidth(part.text, sty) + spacing(part.text, sty)
+	}
+	p.engine.ChangeFont(sty.Font)
+	return width
+}
+
+// writeRun writes s with the styles sty at the current position
+func (p *Processor) writeRun(s string, sty style.Styles) {
+	if runs := p.fallbackRuns(s, sty); fallsBack(runs, sty) {
+		for _, run := range runs {
+			p.writeRun(run.text, run.sty)
+		}
+		p.engine.ChangeFont(sty.Font)
+		return
+	}
+	p.engine.ChangeFont(sty.Font)
+	if plainRun(sty) {
+		p.writeShaped(s, sty)
+		return
+	}
+	x, y := p.engine.GetXY()
+	fontHeight := p.engine.FontHeight()
+	p.engine.SetCharSpacing(float64(sty.LetterSpacing))
+	for _, part := range runParts(s, sty) {
+		p.engine.ChangeFont(partFont(part, sty))
+		//small capitals share the baseline of the run
+		dy := baselineRatio * (fontHeight - p.engine.FontHeight())
+		for i, word := range strings.Split(part.text, " ") {
+			if i > 0 {
+				x += p.engine.TextWidth(" ") + spacing(" ", sty)
+			}
+			if word == "" {
+				continue
+			}
+			p.engine.SetY(y + dy)
+			p.engine.SetX(x)
+			p.writeShaped(word, sty)
+			x += p.shapedWidth(word, sty) + spacing(word, sty)
+		}
+	}
+	p.engine.SetCharSpacing(0)
+	p.engine.ChangeFont(sty.Font)
+	p.engine.SetY(y)
+	p.engine.SetX(x)
+}
@@ -0,0 +1,124 @@
+package xpdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/engine"
+	"github.com/mazzegi/xpdf/style"
+)
+
+func TestTransformed(t *testing.T) {
+	tests := []struct {
+		in  string
+		tt  style.TextTransform
+		exp string
+	}{
+		{in: "Straße und Weg", tt: style.TextTransformNone, exp: "Straße und Weg"},
+		{in: "Öl und Weg", tt: style.TextTransformUppercase, exp: "ÖL UND WEG"},
+		{in: "Straße und Weg", tt: style.TextTransformLowercase, exp: "straße und weg"},
+		{in: "the quick  brown-fox", tt: style.TextTransformCapitalize, exp: "The Quick  Brown-fox"},
+		{in: "élan vital", tt: style.TextTransformCapitalize, exp: "Élan Vital"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := transformed(test.in, test.tt)
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestSmallCapsParts(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "", exp: "[]"},
+		{in: "ABC", exp: "[{ABC false}]"},
+		{in: "Small Caps", exp: "[{S false} {MALL true} { C false} {APS true}]"},
+		{in: "ärger 2x", exp: "[{ÄRGER true} { 2 false} {X true}]"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := fmt.Sprint(smallCapsParts(test.in))
+			if have != test.exp {
+				t.Fatalf("have %s, want %s", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestSpacing(t *testing.T) {
+	sty := DefaultStyle()
+	sty.LetterSpacing = 0.5
+	sty.WordSpacing = 2
+	tests := []struct {
+		in  string
+		exp float64
+	}{
+		{in: "", exp: 0},
+		{in: "word", exp: 2},
+		{in: " wörd", exp: 4.5},
+		{in: "a b c", exp: 6.5},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := spacing(test.in, sty)
+			if math.Abs(have-test.exp) > 1e-9 {
+				t.Fatalf("have %f, want %f", have, test.exp)
+			}
+		})
+	}
+}
+
+// glyphEngine is an engine, whose fonts have glyphs for the characters listed by family
+type glyphEngine struct {
+	engine.Engine
+	glyphs map[string]string
+}
+
+func (e *glyphEngine) HasGlyph(fnt style.Font, r rune) bool {
+	return strings.ContainsRune(e.glyphs[fnt.Family], r)
+}
+
+func TestFallbackRuns(t *testing.T) {
+	p := &Processor{
+		engine: &glyphEngine{glyphs: map[string]string{
+			"latin": "abc ",
+			"greek": "αβγ ",
+			"cjk":   "中文 ",
+		}},
+	}
+	tests := []struct {
+		family string
+		in     string
+		exp    string
+	}{
+		{family: "latin", in: "abc αβ", exp: "[{abc αβ latin}]"},
+		{family: "latin, greek", in: "abc", exp: "[{abc latin}]"},
+		{family: "latin, greek", in: "abc αβ c", exp: "[{abc  latin} {αβ  greek} {c latin}]"},
+		{family: `latin, greek, "cjk"`, in: "a中文β", exp: "[{a latin} {中文 cjk} {β greek}]"},
+		//combining marks stay with their base
+		{family: "latin, greek", in: "a\u0301\u03b1\u0301", exp: "[{a\u0301 latin} {\u03b1\u0301 greek}]"},
+		{family: "latin, greek", in: "ax", exp: "[{a latin} {x latin, greek}]"},
+		{family: "latin, greek", in: "", exp: "[{ latin, greek}]"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			sty := DefaultStyle()
+			sty.Font.Family = test.family
+			var parts []string
+			for _, run := range p.fallbackRuns(test.in, sty) {
+				parts = append(parts, fmt.Sprintf("{%s %s}", run.text, run.sty.Font.Family))
+			}
+			have := "[" + strings.Join(parts, " ") + "]"
+			if have != test.exp {
+				t.Fatalf("have %s, want %s", have, test.exp)
+			}
+		})
+	}
+}
@@ -9,8 +9,15 @@ import (
 )
 
 type textItem struct {
-	sty  style.Styles
-	text string
+	sty   style.Styles
+	text  string
+	image *inlineImage
+	// glued items follow the preceding one without space
+	glued    bool
+	footnote *footnote
+	tab      *tabItem
+	// dy is the offset of the text from the top of the line for raised or lowered text
+	dy float64
 }
 
 type textLine struct {
@@ -18,60 +25,218 @@ type textLine struct {
 	width         float64
 	pureTextWidth float64
 	paragraph     bool
+	// indent and avail are the offset and the available width of the line
+	indent float64
+	avail  float64
+	// above and below are the extra space needed by inline elements exceeding the line
+	above float64
+	below float64
+	// clearance moves the line down below floats, which leave no space for it
+	clearance float64
+	// tab is the last tab of the line
+	tab *tabState
+}
+
+// lineSpan gives the indent and the available width of the lines of a text
+type lineSpan interface {
+	// line returns the indent and the available width of the line following the lines prev
+	// and the clearance, by which it is moved down to get any width
+	line(prev []textLine) (indent, width, clearance float64)
+	// pageBreak tells the span, that the line idx continues at (x,y) on a new page. It reports if the lines
+	// from idx on change and have to be broken anew.
+	pageBreak(idx int, x, y float64) bool
+}
+
+// fixedSpan is a lineSpan, where all lines have the same width
+type fixedSpan float64
+
+func (s fixedSpan) line([]textLine) (float64, float64, float64) {
+	return 0, float64(s), 0
 }
 
-func (p *Processor) words(s string) []string {
-	return strings.Split(s, " ")
+func (s fixedSpan) pageBreak(int, float64, float64) bool {
+	return false
 }
 
-func (p *Processor) textLines(iss []xdoc.Instruction, width float64, sty style.Styles) []textLine {
-	norm := func(s string) string {
-		return text.WhitespaceRectified(p.tr(s))
+func newTextLine(span lineSpan, prev []textLine) textLine {
+	indent, avail, clearance := span.line(prev)
+	return textLine{
+		indent:    indent,
+		avail:     avail,
+		clearance: clearance,
 	}
+}
+
+// spaceAbove returns the space between the preceding line and the top of the line
+func (l textLine) spaceAbove() float64 {
+	return l.clearance + l.above
+}
+
+// lead returns the space preceding item, if it is added to the line
+func (l *textLine) lead(item *textItem) string {
+	if item.glued || len(l.items) == 0 {
+		return ""
+	}
+	return " "
+}
+
+// fits reports if an item of the given width fits into the line
+func (l *textLine) fits(width float64) bool {
+	return !l.overflows(width)
+}
+
+// spaces returns the number of spaces between the items of the line
+func (l textLine) spaces() int {
+	n := 0
+	for i, item := range l.items {
+		if i > 0 && !item.glued {
+			n++
+		}
+	}
+	return n
+}
+
+// textWord is a part of a text between line break opportunities
+type textWord struct {
+	text string
+	// glued words follow the preceding one without space
+	glued bool
+}
+
+// words splits s at spaces and at the line break opportunities within the space separated words
+func (p *Processor) words(s string) []textWord {
+	var words []textWord
+	for _, w := range strings.Split(s, " ") {
+		for i, seg := range text.Segments(w) {
+			words = append(words, textWord{text: seg, glued: i > 0})
+		}
+	}
+	return words
+}
+
+func (p *Processor) textLines(iss []xdoc.Instruction, span lineSpan, sty style.Styles) []textLine {
 	lines := []textLine{}
-	curr := textLine{}
-	for _, is := range iss {
+	curr := newTextLine(span, nil)
+	footnotes := p.footnoteNumber
+	p.engine.ChangeFont(sty.Font)
+	fontHeight := p.engine.FontHeight()
+	var joint runJoint
+	for _, is := range expandTabs(iss) {
 		var isitem *textItem
+		var raw string
 		switch is := is.(type) {
+		case *xdoc.Tab:
+			p.addTab(&curr, sty)
+			joint = runJoint{tab: true}
+			continue
 		case *xdoc.LineBreak:
 			lines = append(lines, curr)
-			curr = textLine{}
+			curr = newTextLine(span, lines)
 			continue
 		case *xdoc.TextBlock:
 			isitem = &textItem{
 				sty:  sty,
-				text: norm(is.Text),
+				text: is.Text,
 			}
+			raw = is.Text
 		case *xdoc.Paragraph:
-			isitem = &textItem{
-				sty:  is.MutatedStyles(p.doc.StyleClasses(), sty),
-				text: norm(is.Text),
+			isitem = p.runItem(is, is.Text, sty)
+			raw = is.Text
+		case *xdoc.Code:
+			isitem = p.codeItem(is, is.Text, sty)
+			raw = is.Text
+		case *xdoc.InlineImage:
+			item, itemWidth, ok := p.inlineImageItem(is, sty)
+			if !ok {
+				continue
 			}
+			if curr.overflows(itemWidth) && len(curr.items) > 0 {
+				lines = append(lines, curr)
+				curr = newTextLine(span, lines)
+			}
+			if len(curr.items) > 0 {
+				item.text = " "
+			}
+			p.addItem(&curr, item, itemWidth)
+			curr.extend(item.image.extent(p.engine.FontHeight()))
+			joint = runJoint{}
+			continue
+		case *xdoc.Footnote:
+			footnotes++
+			item, itemWidth := p.footnoteItem(is, footnotes, sty)
+			joint = runJoint{open: true}
+			p.addItem(&curr, item, itemWidth)
+			curr.extend(p.runExtent(item, fontHeight))
+			continue
+		case *footnoteMark:
+			item, itemWidth := p.footnoteMarkItem(is.number, sty)
+			joint = runJoint{}
+			p.addItem(&curr, item, itemWidth)
+			curr.extend(p.runExtent(item, fontHeight))
+			continue
 		default:
 			continue
 		}
+		ws := isitem.sty.WhiteSpace
+		isitem.text = text.ShapeArabic(transformed(normalizedRun(p.tr(isitem.text), ws), isitem.sty.TextTransform))
+		glue := joint.glues(raw, ws.Preserves()) && len(curr.items) > 0
+		joint.follow(raw, ws.Preserves())
 		if isitem.text == "" {
 			continue
 		}
 
-		p.changeFont(isitem.sty.Font)
-		words := p.words(isitem.text)
-		for _, word := range words {
-			item := &textItem{
-				sty:  isitem.sty,
-				text: word,
-			}
-			itemWidth := p.engine.TextWidth(" " + item.text)
-			if curr.width+itemWidth >= width {
+		above, below := p.runExtent(isitem, fontHeight)
+		p.engine.ChangeFont(isitem.sty.Font)
+		for k, segment := range strings.Split(isitem.text, "\n") {
+			if k > 0 {
+				//preserved line breaks
 				lines = append(lines, curr)
-				curr = textLine{}
+				curr = newTextLine(span, lines)
+				glue = false
 			}
+			for i, w := range p.words(segment) {
+				word := w.text
+				item := &textItem{
+					sty:  isitem.sty,
+					text: cleanWord(word),
+					dy:   isitem.dy,
+				}
+				itemWidth := p.runWidth(" "+item.text, item.sty)
+				if w.glued || (i == 0 && glue) {
+					item.glued = true
+					itemWidth = p.runWidth(item.text, item.sty)
+				}
+				for ws.Wraps() && curr.overflows(itemWidth) {
+					//break at soft hyphens and zero width spaces
+					s1, s2, ok := p.breakWord(word, curr.lead(item), item.sty, curr.fits, false)
+					if !ok && len(curr.items) == 0 {
+						break
+					}
+					if ok {
+						p.addItem(&curr, &textItem{
+							text:  s1,
+							sty:   item.sty,
+							glued: item.glued,
+							dy:    item.dy,
+						}, p.runWidth(s1, item.sty))
+						curr.extend(above, below)
+						word = s2
+						item.text = cleanWord(s2)
+					}
+					lines = append(lines, curr)
+					curr = newTextLine(span, lines)
+					itemWidth = p.runWidth(item.text, item.sty)
+				}
 
-			if len(curr.items) > 0 {
-				item.text = " " + item.text
+				if len(curr.items) > 0 && !item.glued {
+					item.text = " " + item.text
+				} else if !item.glued {
+					//the first word of a line has no leading space
+					itemWidth = p.runWidth(item.text, item.sty)
+				}
+				p.addItem(&curr, item, itemWidth)
+				curr.extend(above, below)
 			}
-			curr.items = append(curr.items, item)
-			curr.width += itemWidth
 		}
 	}
 	if len(curr.items) > 0 {
@@ -81,31 +246,72 @@ func (p *Processor) textLines(iss []xdoc.Instruction, width float64, sty style.S
 }
 
 func (p *Processor) textHeight(iss []xdoc.Instruction, width float64, sty style.Styles) float64 {
+	return p.linesHeight(p.textLines(iss, fixedSpan(width), sty), sty)
+}
+
+// linesHeight returns the height of the text lines with styles sty
+func (p *Processor) linesHeight(lines []textLine, sty style.Styles) float64 {
 	p.engine.ChangeFont(sty.Font)
-	lines := p.textLines(iss, width, sty)
 	lineHeight := p.engine.FontHeight() * sty.Dimension.LineSpacing
 	//subtract line-spacing, to have no space below the last line
-	return float64(len(lines))*lineHeight - lineHeight + p.engine.FontHeight()
+	return float64(len(lines))*lineHeight - lineHeight + p.engine.FontHeight() + extraHeight(lines)
 }
 
-func (p *Processor) writeText(iss []xdoc.Instruction, width float64, sty style.Styles) {
+// relayout breaks a text anew after a page break with the footnote numbers of its first layout, which started after
+// footnote number. The lines before the page break are the same as before.
+func (p *Processor) relayout(number int, layout func() []textLine) []textLine {
+	curr := p.footnoteNumber
+	p.footnoteNumber = number
+	defer func() {
+		p.footnoteNumber = curr
+	}()
+	return layout()
+}
+
+func (p *Processor) writeText(iss []xdoc.Instruction, span lineSpan, sty style.Styles) {
 	p.engine.ChangeFont(sty.Font)
 	p.engine.SetTextColor(sty.Text.Values())
-	lines := p.textLines(iss, width, sty)
+	footnotes := p.footnoteNumber
+	lines := p.textLines(iss, span, sty)
 	xLeft, _ := p.engine.GetXY()
-	for _, line := range lines {
-		switch sty.HAlign {
+	fontHeight := p.engine.FontHeight()
+	lineBreak := p.lineBreaks(lines, fontHeight*sty.LineSpacing, sty)
+	for i := 0; i < len(lines); i++ {
+		for lineBreak(i) || !p.footnotesFit(lines[i], fontHeight) {
+			xLeft = p.newPageAt(xLeft)
+			//header and footer have reset the styles
+			p.engine.ChangeFont(sty.Font)
+			p.engine.SetTextColor(sty.Text.Values())
+			if _, y := p.engine.GetXY(); span.pageBreak(i, xLeft, y) {
+				lines = p.relayout(footnotes, func() []textLine { return p.textLines(iss, span, sty) })
+				p.engine.ChangeFont(sty.Font)
+				lineBreak = p.lineBreaks(lines, fontHeight*sty.LineSpacing, sty)
+			}
+		}
+		line := lines[i]
+		_, lineTop := p.engine.GetXY()
+		lineTop += line.spaceAbove()
+		p.engine.SetY(lineTop)
+		line = visualLine(line, rtl(sty))
+		switch hAlign(sty) {
 		case style.HAlignLeft:
-			p.engine.SetX(xLeft)
+			p.engine.SetX(xLeft + line.indent)
 		case style.HAlignCenter:
-			p.engine.SetX(xLeft + (width-line.width)/2.0)
+			p.engine.SetX(xLeft + line.indent + (line.avail-line.width)/2.0)
 		case style.HAlignRight:
-			p.engine.SetX(xLeft + width - line.width)
+			p.engine.SetX(xLeft + line.indent + line.avail - line.width)
 		}
 		for _, item := range line.items {
-			p.changeFont(item.sty.Font)
-			p.engine.WriteText(item.text)
+			p.engine.ChangeFont(item.sty.Font)
+			p.writeItemText(item, item.text, lineTop)
+			p.putInlineImage(item, lineTop, fontHeight)
+			p.putTab(item)
+			p.putFootnote(item, lineTop+fontHeight+line.below)
 		}
 		p.engine.LineFeed(sty.LineSpacing)
+		if line.below > 0 {
+			_, y := p.engine.GetXY()
+			p.engine.SetY(y + line.below)
+		}
 	}
 }
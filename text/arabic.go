@@ -0,0 +1,144 @@
+package text
+
+// arabicForms holds the first presentation form of an Arabic letter, which is followed by its final and,
+// for dual-joining letters, its initial and medial forms
+type arabicForms struct {
+	isolated rune
+	dual     bool
+}
+
+var arabicLetters = map[rune]arabicForms{
+	0x0621: {isolated: 0xfe80},
+	0x0622: {isolated: 0xfe81},
+	0x0623: {isolated: 0xfe83},
+	0x0624: {isolated: 0xfe85},
+	0x0625: {isolated: 0xfe87},
+	0x0626: {isolated: 0xfe89, dual: true},
+	0x0627: {isolated: 0xfe8d},
+	0x0628: {isolated: 0xfe8f, dual: true},
+	0x0629: {isolated: 0xfe93},
+	0x062a: {isolated: 0xfe95, dual: true},
+	0x062b: {isolated: 0xfe99, dual: true},
+	0x062c: {isolated: 0xfe9d, dual: true},
+	0x062d: {isolated: 0xfea1, dual: true},
+	0x062e: {isolated: 0xfea5, dual: true},
+	0x062f: {isolated: 0xfea9},
+	0x0630: {isolated: 0xfeab},
+	0x0631: {isolated: 0xfead},
+	0x0632: {isolated: 0xfeaf},
+	0x0633: {isolated: 0xfeb1, dual: true},
+	0x0634: {isolated: 0xfeb5, dual: true},
+	0x0635: {isolated: 0xfeb9, dual: true},
+	0x0636: {isolated: 0xfebd, dual: true},
+	0x0637: {isolated: 0xfec1, dual: true},
+	0x0638: {isolated: 0xfec5, dual: true},
+	0x0639: {isolated: 0xfec9, dual: true},
+	0x063a: {isolated: 0xfecd, dual: true},
+	0x0641: {isolated: 0xfed1, dual: true},
+	0x0642: {isolated: 0xfed5, dual: true},
+	0x0643: {isolated: 0xfed9, dual: true},
+	0x0644: {isolated: 0xfedd, dual: true},
+	0x0645: {isolated: 0xfee1, dual: true},
+	0x0646: {isolated: 0xfee5, dual: true},
+	0x0647: {isolated: 0xfee9, dual: true},
+	0x0648: {isolated: 0xfeed},
+	0x0649: {isolated: 0xfeef},
+	0x064a: {isolated: 0xfef1, dual: true},
+	//persian and urdu letters
+	0x067e: {isolated: 0xfb56, dual: true},
+	0x0686: {isolated: 0xfb7a, dual: true},
+	0x0698: {isolated: 0xfb8a},
+	0x06a9: {isolated: 0xfb8e, dual: true},
+	0x06af: {isolated: 0xfb92, dual: true},
+	0x06cc: {isolated: 0xfbfc, dual: true},
+}
+
+const (
+	arabicLam     = 0x0644
+	arabicTatweel = 0x0640
+)
+
+// lamAlef maps the alefs to the isolated form of their ligature with a preceding lam, which is followed by the final one
+var lamAlef = map[rune]rune{
+	0x0622: 0xfef5,
+	0x0623: 0xfef7,
+	0x0625: 0xfef9,
+	0x0627: 0xfefb,
+}
+
+// joinsNext reports if r connects to the following letter
+func joinsNext(r rune) bool {
+	return r == arabicTatweel || arabicLetters[r].dual
+}
+
+// joinsPrev reports if r connects to the preceding letter
+func joinsPrev(r rune) bool {
+	if r == arabicTatweel {
+		return true
+	}
+	_, ok := arabicLetters[r]
+	return ok && r != 0x0621
+}
+
+// hasArabic reports if s contains Arabic letters
+func hasArabic(s string) bool {
+	for _, r := range s {
+		if r >= 0x0600 && r <= 0x06ff {
+			return true
+		}
+	}
+	return false
+}
+
+// ShapeArabic replaces the Arabic letters of s by their contextual presentation forms, which depend on whether
+// they are connected to the preceding and following letters. Lam followed by alef is replaced by a ligature.
+// Combining marks like vowel signs are transparent.
+func ShapeArabic(s string) string {
+	if !hasArabic(s) {
+		return s
+	}
+	rs := []rune(s)
+	//neighbour returns the next non-transparent rune from i in direction d
+	neighbour := func(i, d int) rune {
+		for i += d; i >= 0 && i < len(rs); i += d {
+			if bidiClassOf(rs[i]) != bidiNSM {
+				return rs[i]
+			}
+		}
+		return 0
+	}
+	out := make([]rune, 0, len(rs))
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		f, ok := arabicLetters[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+		prev := joinsNext(neighbour(i, -1))
+		if r == arabicLam && i+1 < len(rs) {
+			if lig, ok := lamAlef[rs[i+1]]; ok {
+				if prev {
+					lig++
+				}
+				out = append(out, lig)
+				i++
+				continue
+			}
+		}
+		next := f.dual && joinsPrev(neighbour(i, 1))
+		switch {
+		case r == 0x0621:
+			out = append(out, f.isolated)
+		case prev && next:
+			out = append(out, f.isolated+3)
+		case next:
+			out = append(out, f.isolated+2)
+		case prev:
+			out = append(out, f.isolated+1)
+		default:
+			out = append(out, f.isolated)
+		}
+	}
+	return string(out)
+}
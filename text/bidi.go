@@ -0,0 +1,270 @@
+package text
+
+import "unicode"
+
+// bidiClass is a bidirectional character type of the Unicode Bidirectional Algorithm (UAX #9).
+// Explicit embeddings, overrides and isolates aren't supported.
+type bidiClass int
+
+const (
+	bidiL bidiClass = iota
+	bidiR
+	bidiAL
+	bidiEN
+	bidiES
+	bidiET
+	bidiAN
+	bidiCS
+	bidiNSM
+	bidiWS
+	bidiON
+)
+
+// ObjectReplacement stands for inline objects like images, which are neutral
+const ObjectReplacement = '\ufffc'
+
+func bidiClassOf(r rune) bidiClass {
+	switch {
+	case r >= '0' && r <= '9', r >= 0x06f0 && r <= 0x06f9:
+		return bidiEN
+	case r >= 0x0660 && r <= 0x0669, r == 0x066b || r == 0x066c:
+		return bidiAN
+	case r == '+' || r == '-':
+		return bidiES
+	case r == '#' || r == '%' || r == 0x00b0 || r == 0x066a || unicode.Is(unicode.Sc, r):
+		return bidiET
+	case r == ',' || r == '.' || r == ':' || r == '/' || r == 0x00a0 || r == 0x060c:
+		return bidiCS
+	case r == ' ' || r == '\t' || unicode.Is(unicode.Zs, r):
+		return bidiWS
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+		return bidiNSM
+	case r >= 0x0590 && r <= 0x05ff, r >= 0x07c0 && r <= 0x085f, r >= 0xfb1d && r <= 0xfb4f:
+		return bidiR
+	case r >= 0x0600 && r <= 0x07bf, r >= 0x0860 && r <= 0x08ff, r >= 0xfb50 && r <= 0xfdff, r >= 0xfe70 && r <= 0xfefe:
+		return bidiAL
+	case unicode.IsLetter(r) || unicode.Is(unicode.Mc, r):
+		return bidiL
+	}
+	return bidiON
+}
+
+// IsRTL reports if r is a strong right-to-left character like Hebrew or Arabic letters
+func IsRTL(r rune) bool {
+	c := bidiClassOf(r)
+	return c == bidiR || c == bidiAL
+}
+
+// HasRTL reports if s contains right-to-left characters
+func HasRTL(s string) bool {
+	for _, r := range s {
+		if IsRTL(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// BidiLevels returns the embedding levels of the runes of a line, which are resolved by the rules W1-W7, N1-N2, I1-I2
+// and L1 of the Unicode Bidirectional Algorithm. The paragraph level is 1 for right-to-left text, otherwise 0.
+func BidiLevels(rs []rune, rtl bool) []int {
+	base, sos := 0, bidiL
+	if rtl {
+		base, sos = 1, bidiR
+	}
+	n := len(rs)
+	cls := make([]bidiClass, n)
+	for i, r := range rs {
+		cls[i] = bidiClassOf(r)
+	}
+	//W1: non-spacing marks take the type of the preceding character
+	for i := range cls {
+		if cls[i] == bidiNSM {
+			if i == 0 {
+				cls[i] = sos
+			} else {
+				cls[i] = cls[i-1]
+			}
+		}
+	}
+	//W2, W3: european numbers following arabic letters are arabic numbers, arabic letters are right-to-left
+	strong := sos
+	for i, c := range cls {
+		switch c {
+		case bidiL, bidiR, bidiAL:
+			strong = c
+		case bidiEN:
+			if strong == bidiAL {
+				cls[i] = bidiAN
+			}
+		}
+	}
+	for i, c := range cls {
+		if c == bidiAL {
+			cls[i] = bidiR
+		}
+	}
+	//W4: single separators between numbers of the same type
+	for i := 1; i < n-1; i++ {
+		prev, next := cls[i-1], cls[i+1]
+		switch {
+		case cls[i] == bidiES && prev == bidiEN && next == bidiEN:
+			cls[i] = bidiEN
+		case cls[i] == bidiCS && prev == next && (prev == bidiEN || prev == bidiAN):
+			cls[i] = prev
+		}
+	}
+	//W5: terminators adjacent to european numbers
+	for i := 0; i < n; i++ {
+		if cls[i] != bidiET {
+			continue
+		}
+		j := i
+		for j < n && cls[j] == bidiET {
+			j++
+		}
+		if (i > 0 && cls[i-1] == bidiEN) || (j < n && cls[j] == bidiEN) {
+			for k := i; k < j; k++ {
+				cls[k] = bidiEN
+			}
+		}
+		i = j - 1
+	}
+	//W6: remaining separators and terminators are neutral
+	for i, c := range cls {
+		if c == bidiES || c == bidiET || c == bidiCS {
+			cls[i] = bidiON
+		}
+	}
+	//W7: european numbers following left-to-right text are left-to-right
+	strong = sos
+	for i, c := range cls {
+		switch c {
+		case bidiL, bidiR:
+			strong = c
+		case bidiEN:
+			if strong == bidiL {
+				cls[i] = bidiL
+			}
+		}
+	}
+	//N1, N2: neutrals between characters of the same direction take it, others the paragraph direction
+	direction := func(c bidiClass) bidiClass {
+		if c == bidiEN || c == bidiAN {
+			return bidiR
+		}
+		return c
+	}
+	for i := 0; i < n; i++ {
+		if cls[i] != bidiWS && cls[i] != bidiON {
+			continue
+		}
+		j := i
+		for j < n && (cls[j] == bidiWS || cls[j] == bidiON) {
+			j++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = direction(cls[i-1])
+		}
+		if j < n {
+			after = direction(cls[j])
+		}
+		resolved := sos
+		if before == after {
+			resolved = before
+		}
+		for k := i; k < j; k++ {
+			if cls[k] == bidiWS && j == n {
+				//L1: trailing whitespace keeps the paragraph level
+				continue
+			}
+			cls[k] = resolved
+		}
+		i = j - 1
+	}
+	//I1, I2: implicit levels
+	levels := make([]int, n)
+	for i, c := range cls {
+		level := base
+		switch {
+		case base%2 == 0 && c == bidiR:
+			level++
+		case base%2 == 0 && (c == bidiEN || c == bidiAN):
+			level += 2
+		case base%2 == 1 && (c == bidiL || c == bidiEN || c == bidiAN):
+			level++
+		}
+		if c == bidiWS {
+			level = base
+		}
+		levels[i] = level
+	}
+	return levels
+}
+
+// VisualOrder returns the logical indices of elements with the given levels in visual order by the rule L2:
+// From the highest level to the lowest odd one, sequences of at least that level are reversed.
+func VisualOrder(levels []int) []int {
+	order := make([]int, len(levels))
+	max, minOdd := 0, -1
+	for i, l := range levels {
+		order[i] = i
+		if l > max {
+			max = l
+		}
+		if l%2 == 1 && (minOdd < 0 || l < minOdd) {
+			minOdd = l
+		}
+	}
+	if minOdd < 0 {
+		return order
+	}
+	for level := max; level >= minOdd; level-- {
+		for i := 0; i < len(order); i++ {
+			if levels[order[i]] < level {
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+var mirrored = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'\u00ab': '\u00bb', '\u00bb': '\u00ab',
+	'\u2039': '\u203a', '\u203a': '\u2039',
+}
+
+// Reversed returns s written from right to left, where brackets are mirrored (L4) and combining marks
+// stay behind their base characters
+func Reversed(s string) string {
+	rs := []rune(s)
+	out := make([]rune, 0, len(rs))
+	for end := len(rs); end > 0; {
+		start := end - 1
+		for start > 0 && bidiClassOf(rs[start]) == bidiNSM {
+			start--
+		}
+		for i := start; i < end; i++ {
+			r := rs[i]
+			if m, ok := mirrored[r]; ok {
+				r = m
+			}
+			out = append(out, r)
+		}
+		end = start
+	}
+	return string(out)
+}
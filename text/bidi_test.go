@@ -0,0 +1,78 @@
+package text
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBidiLevels(t *testing.T) {
+	tests := []struct {
+		in  string
+		rtl bool
+		exp []int
+	}{
+		{in: "abc", exp: []int{0, 0, 0}},
+		{in: "ab אב", exp: []int{0, 0, 0, 1, 1}},
+		{in: "א ב c", exp: []int{1, 1, 1, 0, 0}},
+		{in: "א 12", exp: []int{1, 1, 2, 2}},
+		{in: "א 12", rtl: true, exp: []int{1, 1, 2, 2}},
+		{in: "ab א", rtl: true, exp: []int{2, 2, 1, 1}},
+		{in: "ا 12", exp: []int{1, 1, 2, 2}},
+		{in: "a (א) ", rtl: true, exp: []int{2, 1, 1, 1, 1, 1}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := BidiLevels([]rune(test.in), test.rtl)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %v, want %v", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	tests := []struct {
+		levels []int
+		exp    []int
+	}{
+		{levels: []int{0, 0, 0}, exp: []int{0, 1, 2}},
+		{levels: []int{0, 1, 1, 0}, exp: []int{0, 2, 1, 3}},
+		{levels: []int{1, 1, 2, 2, 1}, exp: []int{4, 2, 3, 1, 0}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := VisualOrder(test.levels)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %v, want %v", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestReversed(t *testing.T) {
+	if have, exp := Reversed("(אָב)"), "(באָ)"; have != exp {
+		t.Fatalf("have %q, want %q", have, exp)
+	}
+}
+
+func TestShapeArabic(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{in: "abc", exp: "abc"},
+		{in: "سلام", exp: "ﺳﻼﻡ"},
+		{in: "مرحبا", exp: "ﻣﺮﺣﺒﺎ"},
+		{in: "بَب", exp: "ﺑَﺐ"},
+		{in: "لا ب", exp: "ﻻ ﺏ"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := ShapeArabic(test.in)
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// breakClass is a simplified line breaking class of UAX #14
+type breakClass int
+
+const (
+	// alphabetic, numeric and other characters without break opportunities of their own
+	classAL breakClass = iota
+	// numbers
+	classNU
+	// hyphens, which allow a break after them except before numbers
+	classHY
+	// break after like en dashes and vertical lines
+	classBA
+	// break before and after like em dashes
+	classB2
+	// slashes, which are tailored to allow a break after them as in URLs and paths
+	classSY
+	// ideographs and kana, which allow a break before and after them
+	classID
+	// opening punctuation, after which isn't broken
+	classOP
+	// closing punctuation, before which isn't broken
+	classCL
+	// exclamation and question marks, before which isn't broken
+	classEX
+	// infix separators like commas and periods, before which isn't broken
+	classIS
+	// nonstarters like small kana and iteration marks, before which isn't broken
+	classNS
+	// quotation marks, around which isn't broken
+	classQU
+	// glue like no-break spaces and combining marks, around which isn't broken
+	classGL
+	classCM
+)
+
+var breakClasses = map[rune]breakClass{
+	'-':      classHY,
+	'\u2010': classBA,
+	'\u2012': classBA,
+	'\u2013': classBA,
+	'|':      classBA,
+	'\u2014': classB2,
+	'/':      classSY,
+	'(':      classOP,
+	'[':      classOP,
+	'{':      classOP,
+	'\u00a1': classOP,
+	'\u00bf': classOP,
+	')':      classCL,
+	']':      classCL,
+	'}':      classCL,
+	'!':      classEX,
+	'?':      classEX,
+	'\uff01': classEX,
+	'\uff1f': classEX,
+	',':      classIS,
+	'.':      classIS,
+	':':      classIS,
+	';':      classIS,
+	'"':      classQU,
+	'\'':     classQU,
+	'\u00ab': classQU,
+	'\u00bb': classQU,
+	'\u2018': classQU,
+	'\u2019': classQU,
+	'\u201c': classQU,
+	'\u201d': classQU,
+	'\u201e': classQU,
+	'\u00a0': classGL,
+	'\u2007': classGL,
+	'\u202f': classGL,
+	'\u2060': classGL,
+	// soft hyphens and zero width spaces are break hints, which are handled with hyphenation
+	'\u00ad': classGL,
+	'\u200b': classGL,
+	'\u200d': classCM,
+}
+
+// cjkOpen, cjkClose and cjkNonStarters are the CJK punctuation and kana of the classes OP, CL and NS
+const (
+	cjkOpen        = "〈《「『【〔〖〘〚（［｛｢"
+	cjkClose       = "、。〉》」』】〕〗〙〛），．］｝｣"
+	cjkNonStarters = "々〻ゝゞ・ーヽヾ" +
+		"ぁぃぅぇぉっゃゅょゎゕゖ" +
+		"ァィゥェォッャュョヮヵヶ"
+)
+
+// classOf returns the line breaking class of r
+func classOf(r rune) breakClass {
+	if c, ok := breakClasses[r]; ok {
+		return c
+	}
+	switch {
+	case strings.ContainsRune(cjkOpen, r):
+		return classOP
+	case strings.ContainsRune(cjkClose, r):
+		return classCL
+	case strings.ContainsRune(cjkNonStarters, r):
+		return classNS
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+		return classCM
+	case unicode.IsDigit(r):
+		return classNU
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		return classID
+	case r >= 0xff01 && r <= 0xff60:
+		//fullwidth forms
+		return classID
+	}
+	return classAL
+}
+
+// breakBetween reports if a line may be broken between runes of the classes before and after
+func breakBetween(before, after breakClass, atStart bool) bool {
+	switch before {
+	case classOP, classQU, classGL:
+		return false
+	}
+	switch after {
+	case classCL, classEX, classIS, classNS, classQU, classGL, classCM, classHY, classBA, classSY:
+		return false
+	}
+	switch before {
+	case classHY:
+		//no break in negative numbers and after leading hyphens
+		return after != classNU && !atStart
+	case classBA:
+		return !atStart
+	case classB2:
+		return after != classB2
+	case classSY:
+		//no break in numbers like 1/2
+		return after == classAL || after == classID
+	case classID:
+		return after == classID || after == classOP || after == classAL || after == classNU || after == classB2
+	case classCL, classEX:
+		return after == classID || after == classOP
+	}
+	return after == classB2 || after == classID
+}
+
+// Breaks returns the byte offsets in s, where a line may be broken according to a subset of UAX #14.
+// Breaks are allowed after hyphens, dashes and slashes and around ideographs, but not before closing
+// punctuation or after opening one. Spaces aren't considered, as text is split at them beforehand.
+func Breaks(s string) []int {
+	var breaks []int
+	var prev breakClass
+	// start is true as long as only punctuation has been read
+	start := true
+	idx := 0
+	for _, r := range s {
+		cls := classOf(r)
+		if idx > 0 {
+			if cls == classCM {
+				//combining marks take the class of their base
+				idx += len(string(r))
+				continue
+			}
+			if breakBetween(prev, cls, start) {
+				breaks = append(breaks, idx)
+			}
+		}
+		if cls == classAL || cls == classNU || cls == classID {
+			start = false
+		}
+		prev = cls
+		idx += len(string(r))
+	}
+	return breaks
+}
+
+// Segments splits s at its line break opportunities. The result has at least one element.
+func Segments(s string) []string {
+	breaks := Breaks(s)
+	if len(breaks) == 0 {
+		return []string{s}
+	}
+	segs := make([]string, 0, len(breaks)+1)
+	last := 0
+	for _, b := range breaks {
+		segs = append(segs, s[last:b])
+		last = b
+	}
+	return append(segs, s[last:])
+}
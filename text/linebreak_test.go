@@ -0,0 +1,40 @@
+package text
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp []string
+	}{
+		{in: "", exp: []string{""}},
+		{in: "word", exp: []string{"word"}},
+		{in: "well-known", exp: []string{"well-", "known"}},
+		{in: "-5", exp: []string{"-5"}},
+		{in: "-foo", exp: []string{"-foo"}},
+		{in: "pages 10-12", exp: []string{"pages 10-12"}},
+		{in: "and/or", exp: []string{"and/", "or"}},
+		{in: "1/2", exp: []string{"1/2"}},
+		{in: "https://example.com/path/to/file.txt", exp: []string{"https://", "example.com/", "path/", "to/", "file.txt"}},
+		{in: "word—word", exp: []string{"word", "—", "word"}},
+		{in: "a b", exp: []string{"a b"}},
+		{in: "(a)b", exp: []string{"(a)b"}},
+		{in: "日本語", exp: []string{"日", "本", "語"}},
+		{in: "これは「本」です。次", exp: []string{"こ", "れ", "は", "「本」", "で", "す。", "次"}},
+		{in: "ちょっと", exp: []string{"ちょっ", "と"}},
+		{in: "Go言語", exp: []string{"Go", "言", "語"}},
+		{in: "年2024", exp: []string{"年", "2024"}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := Segments(test.in)
+			if !reflect.DeepEqual(have, test.exp) {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
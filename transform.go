@@ -0,0 +1,55 @@
+package xpdf
+
+import (
+	"math"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+// matrix is an affine transformation, which maps (x,y) to (a*x + c*y + e, b*x + d*y + f) in page coordinates
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+func identity() matrix {
+	return matrix{a: 1, d: 1}
+}
+
+// rotation returns the clockwise rotation by angle around (cx,cy)
+func rotation(angle style.Angle, cx, cy float64) matrix {
+	sin, cos := math.Sincos(angle.Radians())
+	return matrix{
+		a: cos,
+		b: sin,
+		c: -sin,
+		d: cos,
+		e: cx - cx*cos + cy*sin,
+		f: cy - cx*sin - cy*cos,
+	}
+}
+
+// translated returns m followed by a translation by (dx,dy)
+func (m matrix) translated(dx, dy float64) matrix {
+	m.e += dx
+	m.f += dy
+	return m
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m.a*x + m.c*y + m.e, m.b*x + m.d*y + m.f
+}
+
+// rotatedSize returns the size of the bounding box of a rectangle rotated by angle
+func rotatedSize(angle style.Angle, width, height float64) (float64, float64) {
+	sin, cos := math.Sincos(angle.Radians())
+	sin, cos = math.Abs(sin), math.Abs(cos)
+	return width*cos + height*sin, width*sin + height*cos
+}
+
+// transformed runs draw inside a transformation context with m
+func (p *Processor) transformed(m matrix, draw func()) {
+	p.engine.TransformBegin()
+	p.engine.Transform(m.a, m.b, m.c, m.d, m.e, m.f)
+	draw()
+	p.engine.TransformEnd()
+}
@@ -0,0 +1,41 @@
+package xpdf
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/mazzegi/xpdf/text"
+)
+
+// isNonBreakingSpace reports if r is a space, where lines must not be broken: no-break, figure and narrow no-break space
+func isNonBreakingSpace(r rune) bool {
+	return r == '\u00a0' || r == '\u2007' || r == '\u202f'
+}
+
+// isBreakingSpace reports if r is whitespace, which separates words
+func isBreakingSpace(r rune) bool {
+	return unicode.IsSpace(r) && !isNonBreakingSpace(r)
+}
+
+// normalizedRun returns the text of a run with the white-space mode ws. Collapsed whitespace is reduced to single spaces
+// between the words. Preserved whitespace is kept, where line breaks are normalized to \n and tabs, which are not split
+// into tab stops, are expanded to spaces.
+func normalizedRun(s string, ws style.WhiteSpace) string {
+	if !ws.Preserves() {
+		return text.WhitespaceRectified(s)
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", preTabSize))
+}
+
+// preserved reports if the line contains text with preserved whitespace. Such lines are not justified.
+func (l *textLine) preserved() bool {
+	for _, item := range l.items {
+		if item.sty.WhiteSpace.Preserves() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package xpdf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+func TestNormalizedRun(t *testing.T) {
+	tests := []struct {
+		in  string
+		ws  style.WhiteSpace
+		exp string
+	}{
+		{in: "  foo \n\t bar  ", ws: style.WhiteSpaceNormal, exp: "foo bar"},
+		{in: "  foo \n bar  ", ws: style.WhiteSpaceNowrap, exp: "foo bar"},
+		{in: "10\u00a0km  and", ws: style.WhiteSpaceNormal, exp: "10\u00a0km and"},
+		{in: "  foo \r\n\tbar  ", ws: style.WhiteSpacePre, exp: "  foo \n    bar  "},
+		{in: "a\rb", ws: style.WhiteSpacePreWrap, exp: "a\nb"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d", i+1), func(t *testing.T) {
+			have := normalizedRun(test.in, test.ws)
+			if have != test.exp {
+				t.Fatalf("have %q, want %q", have, test.exp)
+			}
+		})
+	}
+}
+
+func TestBreakingSpace(t *testing.T) {
+	for _, r := range []rune{' ', '\t', '\n', '\u2003'} {
+		if !isBreakingSpace(r) {
+			t.Fatalf("%U should be breaking", r)
+		}
+	}
+	for _, r := range []rune{'\u00a0', '\u202f', '\u2007', 'x'} {
+		if isBreakingSpace(r) {
+			t.Fatalf("%U should not be breaking", r)
+		}
+	}
+}
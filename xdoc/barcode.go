@@ -0,0 +1,73 @@
+package xdoc
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type BarcodeType string
+
+const (
+	BarcodeQR         BarcodeType = "qr"
+	BarcodeCode128    BarcodeType = "code128"
+	BarcodeEAN13      BarcodeType = "ean13"
+	BarcodeDataMatrix BarcodeType = "datamatrix"
+)
+
+// Barcode is a one- or two-dimensional symbol of its data. The size is given by the width and height styles.
+type Barcode struct {
+	Styled
+	XMLName xml.Name    `xml:"barcode"`
+	Type    BarcodeType `xml:"-"`
+	// Level is the error correction level (L, M, Q, H) of qr codes
+	Level string `xml:"-"`
+	// QuietZone is the light margin around the symbol in modules. If negative, the minimum of the type is used.
+	QuietZone int `xml:"-"`
+	// ShowText prints the data human-readable below one-dimensional symbols
+	ShowText bool   `xml:"-"`
+	Data     string `xml:",chardata"`
+}
+
+func (b *Barcode) DecodeAttrs(attrs []xml.Attr) error {
+	b.Level = "M"
+	b.QuietZone = -1
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "type":
+			switch t := BarcodeType(a.Value); t {
+			case BarcodeQR, BarcodeCode128, BarcodeEAN13, BarcodeDataMatrix:
+				b.Type = t
+			default:
+				return errors.Errorf("invalid barcode type %q - must be one of qr, code128, ean13, datamatrix", a.Value)
+			}
+		case "level":
+			switch l := strings.ToUpper(a.Value); l {
+			case "L", "M", "Q", "H":
+				b.Level = l
+			default:
+				return errors.Errorf("invalid value %q for level - must be one of L, M, Q, H", a.Value)
+			}
+		case "quiet-zone":
+			n, err := strconv.ParseInt(a.Value, 10, 64)
+			if err != nil {
+				return err
+			} else if n < 0 || n > 50 {
+				return errors.Errorf("invalid value %d for quiet-zone - must be in [0,50]", n)
+			}
+			b.QuietZone = int(n)
+		case "text":
+			show, err := strconv.ParseBool(a.Value)
+			if err != nil {
+				return errors.Wrapf(err, "invalid value %q for text", a.Value)
+			}
+			b.ShowText = show
+		}
+	}
+	if b.Type == "" {
+		return errors.Errorf("barcode without type")
+	}
+	return b.Styled.DecodeAttrs(attrs)
+}
@@ -0,0 +1,125 @@
+package xdoc
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+type ChartType string
+
+const (
+	ChartBar  ChartType = "bar"
+	ChartLine ChartType = "line"
+	ChartPie  ChartType = "pie"
+)
+
+// Chart draws its series as bar, line or pie chart. Pie charts show the first series only.
+type Chart struct {
+	Styled
+	XMLName xml.Name  `xml:"chart"`
+	Type    ChartType `xml:"-"`
+	// Labels are the category labels of the values
+	Labels []string  `xml:"-"`
+	Series []*Series `xml:"series"`
+}
+
+// Series is a named list of values separated by commas or whitespace
+type Series struct {
+	Styled
+	XMLName xml.Name  `xml:"series"`
+	Name    string    `xml:"name,attr"`
+	Values  []float64 `xml:"-"`
+}
+
+func (c *Chart) DecodeAttrs(attrs []xml.Attr) error {
+	c.Type = ChartBar
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "type":
+			switch t := ChartType(a.Value); t {
+			case ChartBar, ChartLine, ChartPie:
+				c.Type = t
+			default:
+				return errors.Errorf("invalid chart type %q - must be one of bar, line, pie", a.Value)
+			}
+		case "labels":
+			for _, l := range strings.Split(a.Value, ",") {
+				c.Labels = append(c.Labels, strings.TrimSpace(l))
+			}
+		}
+	}
+	return c.Styled.DecodeAttrs(attrs)
+}
+
+func (c *Chart) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			if t == start.End() {
+				if len(c.Series) == 0 {
+					return errors.Errorf("chart has no series")
+				}
+				return nil
+			}
+		case xml.StartElement:
+			i, err := registry.DecodeInstruction(d, t)
+			if err != nil {
+				return err
+			}
+			switch i := i.(type) {
+			case *Series:
+				c.Series = append(c.Series, i)
+			}
+		}
+	}
+}
+
+func (s *Series) DecodeAttrs(attrs []xml.Attr) error {
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "name":
+			s.Name = a.Value
+		}
+	}
+	return s.Styled.DecodeAttrs(attrs)
+}
+
+func (s *Series) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var data strings.Builder
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			if t == start.End() {
+				return s.parseValues(data.String())
+			}
+		case xml.CharData:
+			data.Write(t)
+		}
+	}
+}
+
+func (s *Series) parseValues(data string) error {
+	fields := strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value %q in series %q", f, s.Name)
+		}
+		s.Values = append(s.Values, v)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package xdoc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeChart(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		typ    ChartType
+		labels []string
+		series string
+		fail   bool
+	}{
+		{
+			name:   "bar",
+			in:     `<chart labels="Jan, Feb"><series name="a">1, 2.5</series><series>-3 4</series></chart>`,
+			typ:    ChartBar,
+			labels: []string{"Jan", "Feb"},
+			series: "a:[1 2.5] :[-3 4]",
+		},
+		{
+			name:   "pie",
+			in:     `<chart type="pie"><series>1;2;3</series></chart>`,
+			typ:    ChartPie,
+			series: ":[1 2 3]",
+		},
+		{
+			name: "invalid type",
+			in:   `<chart type="radar"><series>1</series></chart>`,
+			fail: true,
+		},
+		{
+			name: "invalid value",
+			in:   `<chart><series>1, x</series></chart>`,
+			fail: true,
+		},
+		{
+			name: "without series",
+			in:   `<chart type="line"></chart>`,
+			fail: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Load(strings.NewReader(`<document><body>` + test.in + `</body></document>`))
+			if test.fail {
+				if err == nil {
+					t.Fatalf("load should fail but did not")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			ch, ok := doc.Body.ISS[0].(*Chart)
+			if !ok {
+				t.Fatalf("have %T, want chart", doc.Body.ISS[0])
+			}
+			var series []string
+			for _, s := range ch.Series {
+				series = append(series, fmt.Sprintf("%s:%v", s.Name, s.Values))
+			}
+			if ch.Type != test.typ || fmt.Sprint(ch.Labels) != fmt.Sprint(test.labels) || strings.Join(series, " ") != test.series {
+				t.Fatalf("have %s %v %s", ch.Type, ch.Labels, strings.Join(series, " "))
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package xdoc
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/mazzegi/xpdf/style"
+	"github.com/pkg/errors"
+)
+
+// Columns lets its instructions flow through count columns separated by gap. Of its styles only offset-y and the
+// pagination rules apply, they aren't inherited by the instructions.
+type Columns struct {
+	Styled
+	XMLName xml.Name `xml:"columns"`
+	Count   int      `xml:"count,attr"`
+	Gap     float64  `xml:"-"`
+	Instructions
+}
+
+func (c *Columns) DecodeAttrs(attrs []xml.Attr) error {
+	c.Count = 2
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "count":
+			n, err := strconv.ParseInt(a.Value, 10, 64)
+			if err != nil {
+				return err
+			} else if n < 1 || n > 10 {
+				return errors.Errorf("invalid value %d for count - must be in [1,10]", n)
+			}
+			c.Count = int(n)
+		case "gap":
+			gap, err := style.ParseLength(a.Value)
+			if err != nil {
+				return err
+			} else if gap < 0 {
+				return errors.Errorf("invalid value %q for gap - must not be negative", a.Value)
+			}
+			c.Gap = gap
+		}
+	}
+	return c.Styled.DecodeAttrs(attrs)
+}
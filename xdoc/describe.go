@@ -251,6 +251,18 @@ func (desc *Description) describeInstructions(iss Instructions) []DescribeItem {
 				Value:      clearStr(is.Text),
 				StyleDiffs: desc.describeMutator(is),
 			})
+		case *Pre:
+			dis = append(dis, DescribeItem{
+				Name:       fmt.Sprintf("pre lang=%s", is.Lang),
+				Value:      is.Text,
+				StyleDiffs: desc.describeMutator(is),
+			})
+		case *Code:
+			dis = append(dis, DescribeItem{
+				Name:       "code",
+				Value:      clearStr(is.Text),
+				StyleDiffs: desc.describeMutator(is),
+			})
 		case *LineBreak:
 			dis = append(dis, DescribeItem{
 				Name: "line-break",
@@ -259,6 +271,10 @@ func (desc *Description) describeInstructions(iss Instructions) []DescribeItem {
 			dis = append(dis, DescribeItem{
 				Name: "page-break",
 			})
+		case *Tab:
+			dis = append(dis, DescribeItem{
+				Name: "tab",
+			})
 		case *TextBlock:
 			dis = append(dis, DescribeItem{
 				Name:  "text-block",
@@ -271,10 +287,50 @@ func (desc *Description) describeInstructions(iss Instructions) []DescribeItem {
 				Value:      is.Source,
 				StyleDiffs: desc.describeMutator(is),
 			})
+		case *InlineImage:
+			dis = append(dis, DescribeItem{
+				Name:       "inline-image",
+				Value:      is.Source,
+				StyleDiffs: desc.describeMutator(is),
+			})
+		case *Footnote:
+			fi := DescribeItem{
+				Name:       "footnote",
+				StyleDiffs: desc.describeMutator(is),
+			}
+			fi.Items = append(fi.Items, desc.describeInstructions(is.Instructions)...)
+			dis = append(dis, fi)
+		case *Barcode:
+			dis = append(dis, DescribeItem{
+				Name:       fmt.Sprintf("barcode type=%s", is.Type),
+				Value:      is.Data,
+				StyleDiffs: desc.describeMutator(is),
+			})
+		case *Chart:
+			ci := DescribeItem{
+				Name:       fmt.Sprintf("chart type=%s", is.Type),
+				Value:      strings.Join(is.Labels, ","),
+				StyleDiffs: desc.describeMutator(is),
+			}
+			for _, s := range is.Series {
+				ci.Items = append(ci.Items, DescribeItem{
+					Name:       "series " + s.Name,
+					Value:      fmt.Sprint(s.Values),
+					StyleDiffs: desc.describeMutator(s),
+				})
+			}
+			dis = append(dis, ci)
 		case *Table:
 			dis = append(dis, desc.describeTable(is)...)
 		case *Grid:
 			dis = append(dis, desc.describeGrid(is)...)
+		case *Columns:
+			ci := DescribeItem{
+				Name:       fmt.Sprintf("columns count=%d gap=%.1f", is.Count, is.Gap),
+				StyleDiffs: desc.describeMutator(is),
+			}
+			ci.Items = append(ci.Items, desc.describeInstructions(is.Instructions)...)
+			dis = append(dis, ci)
 		}
 	}
 	return dis
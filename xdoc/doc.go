@@ -51,9 +51,11 @@ const (
 
 type Document struct {
 	XMLName      xml.Name     `xml:"document"`
+	Lang         string       `xml:"lang,attr"`
 	Meta         Meta         `xml:"meta"`
 	Page         Page         `xml:"page"`
 	Style        string       `xml:"style"`
+	Hyphenation  string       `xml:"hyphenation"` // exceptions like "ta-ble", optionally in TeX's \hyphenation{...}
 	Header       Instructions `xml:"header"`
 	Footer       Instructions `xml:"footer"`
 	Body         Instructions `xml:"body"`
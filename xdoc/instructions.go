@@ -92,6 +92,32 @@ type Image struct {
 	Source  string   `xml:",chardata"`
 }
 
+type InlineImage struct {
+	Styled
+	XMLName xml.Name `xml:"img"`
+	Source  string   `xml:",chardata"`
+}
+
+// Footnote is an inline element, which puts an auto-numbered marker into the text and its content at the bottom of the page
+type Footnote struct {
+	Styled
+	XMLName xml.Name `xml:"footnote"`
+	Instructions
+}
+
+func (f *Footnote) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	err := f.Instructions.UnmarshalXML(d, start)
+	if err != nil {
+		return err
+	}
+	for _, i := range f.ISS {
+		if _, ok := i.(*Footnote); ok {
+			return errors.Errorf("footnotes must not be nested")
+		}
+	}
+	return nil
+}
+
 type TextBlock struct {
 	NoStyles
 	Text string
@@ -103,11 +129,44 @@ type Paragraph struct {
 	Text    string   `xml:",chardata"`
 }
 
+// Pre is a block of preformatted text, whose whitespace and line breaks are preserved. It is written in the mono font
+// and highlighted according to its language, if any.
+type Pre struct {
+	Styled
+	XMLName xml.Name `xml:"pre"`
+	Lang    string   `xml:"lang,attr"`
+	Text    string   `xml:",chardata"`
+}
+
+// DecodeAttrs decodes the styles of pre. Its lang attribute is the language of the code, not of the text.
+func (p *Pre) DecodeAttrs(attrs []xml.Attr) error {
+	var styleAttrs []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Local != "lang" {
+			styleAttrs = append(styleAttrs, a)
+		}
+	}
+	return p.Styled.DecodeAttrs(styleAttrs)
+}
+
+// Code is an inline text run written in the mono font
+type Code struct {
+	Styled
+	XMLName xml.Name `xml:"code"`
+	Text    string   `xml:",chardata"`
+}
+
 type LineBreak struct {
 	NoStyles
 	XMLName xml.Name `xml:"br"`
 }
 
+// Tab moves the following text to the next tab stop
+type Tab struct {
+	NoStyles
+	XMLName xml.Name `xml:"tab"`
+}
+
 type PageBreak struct {
 	NoStyles
 	XMLName xml.Name `xml:"newpage"`
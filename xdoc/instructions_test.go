@@ -0,0 +1,122 @@
+package xdoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mazzegi/xpdf/style"
+)
+
+func TestDecodeFootnote(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		exp  int
+		fail bool
+	}{
+		{
+			name: "plain",
+			in:   `<text>Clause<footnote class="note">See section 2.</footnote> applies.</text>`,
+			exp:  1,
+		},
+		{
+			name: "paragraph",
+			in:   `<text>Clause<footnote>See <p style="font-style: italic">section 2</p>.</footnote></text>`,
+			exp:  3,
+		},
+		{
+			name: "nested",
+			in:   `<text>Clause<footnote>See<footnote>nested</footnote></footnote></text>`,
+			fail: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Load(strings.NewReader(`<document><body>` + test.in + `</body></document>`))
+			if test.fail {
+				if err == nil {
+					t.Fatalf("load should fail but did not")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			txt := doc.Body.ISS[0].(*Text)
+			fn, ok := txt.ISS[1].(*Footnote)
+			if !ok {
+				t.Fatalf("have %T, want footnote", txt.ISS[1])
+			}
+			if len(fn.ISS) != test.exp {
+				t.Fatalf("have %d instructions, want %d", len(fn.ISS), test.exp)
+			}
+		})
+	}
+}
+
+func TestDecodePre(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		lang string
+		exp  string
+	}{
+		{
+			name: "plain",
+			in:   "<pre>a  b\n\tc</pre>",
+			exp:  "a  b\n\tc",
+		},
+		{
+			name: "cdata",
+			in:   `<pre lang="go"><![CDATA[if a < b {}]]></pre>`,
+			lang: "go",
+			exp:  "if a < b {}",
+		},
+		{
+			name: "escaped",
+			in:   `<pre lang="xml" class="listing">&lt;a href="x"/&gt;</pre>`,
+			lang: "xml",
+			exp:  `<a href="x"/>`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Load(strings.NewReader(`<document><body>` + test.in + `</body></document>`))
+			if err != nil {
+				t.Fatalf("load failed: %v", err)
+			}
+			pre, ok := doc.Body.ISS[0].(*Pre)
+			if !ok {
+				t.Fatalf("have %T, want pre", doc.Body.ISS[0])
+			}
+			if pre.Lang != test.lang {
+				t.Fatalf("lang: have %q, want %q", pre.Lang, test.lang)
+			}
+			if pre.Text != test.exp {
+				t.Fatalf("text: have %q, want %q", pre.Text, test.exp)
+			}
+		})
+	}
+}
+
+func TestDecodeLang(t *testing.T) {
+	doc, err := Load(strings.NewReader(`<document lang="de"><body><p lang="fr-CH" style="hyphens: manual">texte</p><pre lang="go">x</pre></body></document>`))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if doc.Lang != "de" {
+		t.Fatalf("doc lang: have %q, want de", doc.Lang)
+	}
+	base := style.Styles{Typography: style.Typography{Lang: "de"}}
+	para, ok := doc.Body.ISS[0].(*Paragraph)
+	if !ok {
+		t.Fatalf("have %T, want paragraph", doc.Body.ISS[0])
+	}
+	if sty := para.MutatedStyles(doc.StyleClasses(), base); sty.Lang != "fr-CH" || sty.Hyphens != style.HyphensManual {
+		t.Fatalf("paragraph: have lang %q, hyphens %q", sty.Lang, sty.Hyphens)
+	}
+	pre := doc.Body.ISS[1].(*Pre)
+	if sty := pre.MutatedStyles(doc.StyleClasses(), base); sty.Lang != "de" || pre.Lang != "go" {
+		t.Fatalf("pre: have lang %q, code lang %q", sty.Lang, pre.Lang)
+	}
+}
@@ -19,6 +19,11 @@ func init() {
 	registry.RegisterInstruction(&SetX{})
 	registry.RegisterInstruction(&SetY{})
 	registry.RegisterInstruction(&Image{})
+	registry.RegisterInstruction(&InlineImage{})
+	registry.RegisterInstruction(&Footnote{})
+	registry.RegisterInstruction(&Barcode{})
+	registry.RegisterInstruction(&Chart{})
+	registry.RegisterInstruction(&Series{})
 	registry.RegisterInstruction(&Table{})
 	registry.RegisterInstruction(&TableRow{})
 	registry.RegisterInstruction(&TableCell{})
@@ -27,8 +32,13 @@ func init() {
 	registry.RegisterInstruction(&GridRow{})
 	registry.RegisterInstruction(&GridPart{})
 
+	registry.RegisterInstruction(&Columns{})
+
 	registry.RegisterInstruction(&Paragraph{})
+	registry.RegisterInstruction(&Pre{})
+	registry.RegisterInstruction(&Code{})
 	registry.RegisterInstruction(&LineBreak{})
+	registry.RegisterInstruction(&Tab{})
 	registry.RegisterInstruction(&PageBreak{})
 }
 
@@ -22,6 +22,12 @@ func (i *Styled) DecodeAttrs(attrs []xml.Attr) error {
 				return errors.Wrapf(err, "decode style applier (%s)", a.Value)
 			}
 			i.Mutators = append(i.Mutators, mut)
+		} else if a.Name.Local == "lang" {
+			mut, err := style.DecodeMutator(bytes.NewBufferString("lang: " + a.Value))
+			if err != nil {
+				return errors.Wrapf(err, "decode lang (%s)", a.Value)
+			}
+			i.Mutators = append(i.Mutators, mut)
 		} else if a.Name.Local == "class" {
 			i.Classes = append(i.Classes, strings.Fields(a.Value)...)
 		}